@@ -0,0 +1,193 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"grocery-store-api/models"
+	"grocery-store-api/services"
+)
+
+// CatalogHandler обслуживает каталоги и прайс-листы поставщиков.
+type CatalogHandler struct {
+	Service services.CatalogService
+}
+
+// GetProductSuppliers возвращает поставщиков товара с ценами на дату
+// (параметр date, по умолчанию сегодня).
+func (h *CatalogHandler) GetProductSuppliers(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID)
+		return
+	}
+
+	day, err := queryDate(c, "date")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	offers, err := h.Service.GetProductSuppliers(uint(id), day)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newSupplierOfferResponses(offers))
+}
+
+func (h *CatalogHandler) SaveProductSupplier(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID)
+		return
+	}
+	supplierID, err := strconv.ParseUint(c.Param("supplier_id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID)
+		return
+	}
+
+	var req ProductSupplierRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindError(err))
+		return
+	}
+
+	link := models.ProductSupplier{
+		ProductID:   uint(productID),
+		SupplierID:  uint(supplierID),
+		SupplierSKU: req.SupplierSKU,
+		Preferred:   req.Preferred,
+	}
+	if err := h.Service.SaveProductSupplier(currentActor(c), &link); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newProductSupplierResponse(&link))
+}
+
+func (h *CatalogHandler) DeleteProductSupplier(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID)
+		return
+	}
+	supplierID, err := strconv.ParseUint(c.Param("supplier_id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID)
+		return
+	}
+
+	if err := h.Service.DeleteProductSupplier(currentActor(c), uint(productID), uint(supplierID)); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "поставщик убран из каталога товара"})
+}
+
+// GetPriceList возвращает прайс-лист поставщика, действующий на дату
+// (параметр date, по умолчанию сегодня).
+func (h *CatalogHandler) GetPriceList(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID)
+		return
+	}
+
+	day, err := queryDate(c, "date")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	items, err := h.Service.GetPriceList(uint(id), day)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newPriceListItemResponses(items))
+}
+
+func (h *CatalogHandler) UploadPriceList(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID)
+		return
+	}
+
+	var req PriceListRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindError(err))
+		return
+	}
+
+	prices, err := h.Service.UploadPriceList(currentActor(c), uint(id), req.ValidFrom, req.toEntries())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, newSupplierPriceResponses(prices))
+}
+
+func (h *CatalogHandler) GetPriceHistory(c *gin.Context) {
+	supplierID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID)
+		return
+	}
+	productID, err := strconv.ParseUint(c.Param("product_id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID)
+		return
+	}
+
+	prices, err := h.Service.GetPriceHistory(uint(supplierID), uint(productID))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newSupplierPriceResponses(prices))
+}
+
+// ComparePrices сравнивает цены поставщиков по товарам будущей поставки
+// (параметры product_id, можно несколько) на дату date.
+func (h *CatalogHandler) ComparePrices(c *gin.Context) {
+	values := c.QueryArray("product_id")
+	if len(values) == 0 {
+		c.Error(invalidQuery("укажите хотя бы один параметр product_id"))
+		return
+	}
+
+	ids := make([]uint, 0, len(values))
+	for _, value := range values {
+		id, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			c.Error(errInvalidID)
+			return
+		}
+		ids = append(ids, uint(id))
+	}
+
+	day, err := queryDate(c, "date")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	comparisons, err := h.Service.ComparePrices(ids, day)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newPriceComparisonResponses(comparisons))
+}
@@ -0,0 +1,192 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"grocery-store-api/models"
+	"grocery-store-api/services"
+)
+
+type CustomerHandler struct {
+	Service services.CustomerService
+}
+
+func (h *CustomerHandler) Create(c *gin.Context) {
+	var req CustomerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindError(err))
+		return
+	}
+
+	customer := req.toModel()
+	if err := h.Service.CreateCustomer(currentActor(c), &customer); err != nil {
+		c.Error(err)
+		return
+	}
+
+	setETag(c, customer.Version)
+	c.JSON(http.StatusCreated, newCustomerResponse(&customer))
+}
+
+func (h *CustomerHandler) GetByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID)
+		return
+	}
+
+	customer, err := h.Service.GetCustomerByID(uint(id))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	setETag(c, customer.Version)
+	c.JSON(http.StatusOK, newCustomerResponse(customer))
+}
+
+// GetAll ищет покупателей по имени, телефону или номеру карты (параметр q).
+func (h *CustomerHandler) GetAll(c *gin.Context) {
+	var filter models.CustomerFilter
+	filter.Query = c.Query("q")
+	filter.Limit, _ = strconv.Atoi(c.Query("limit"))
+	filter.Offset, _ = strconv.Atoi(c.Query("offset"))
+
+	customers, err := h.Service.GetCustomers(filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newCustomerResponses(customers))
+}
+
+func (h *CustomerHandler) Update(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID)
+		return
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req CustomerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindError(err))
+		return
+	}
+
+	h.save(c, uint(id), req, version)
+}
+
+func (h *CustomerHandler) Patch(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID)
+		return
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	current, err := h.Service.GetCustomerByID(uint(id))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if version == 0 {
+		version = current.Version
+	}
+
+	req := newCustomerRequest(current)
+	if err := bindMergePatch(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	h.save(c, uint(id), req, version)
+}
+
+func (h *CustomerHandler) save(c *gin.Context, id uint, req CustomerRequest, version uint) {
+	customer := req.toModel()
+	customer.ID = id
+	if err := h.Service.UpdateCustomer(currentActor(c), &customer, version); err != nil {
+		c.Error(err)
+		return
+	}
+
+	setETag(c, customer.Version)
+	c.JSON(http.StatusOK, newCustomerResponse(&customer))
+}
+
+func (h *CustomerHandler) GetPoints(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID)
+		return
+	}
+
+	balance, err := h.Service.GetBalance(uint(id))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, LoyaltyBalanceResponse{
+		CustomerID: balance.Customer.ID,
+		Balance:    balance.Customer.PointsBalance,
+		Earned:     balance.Earned,
+		Redeemed:   balance.Redeemed,
+	})
+}
+
+func (h *CustomerHandler) GetPointsHistory(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID)
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	offset, _ := strconv.Atoi(c.Query("offset"))
+
+	entries, err := h.Service.GetHistory(uint(id), limit, offset)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newLoyaltyTransactionResponses(entries))
+}
+
+func (h *CustomerHandler) AdjustPoints(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID)
+		return
+	}
+
+	var req PointsAdjustmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindError(err))
+		return
+	}
+
+	entry, err := h.Service.AdjustPoints(currentActor(c), uint(id), req.Points, req.Comment)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, newLoyaltyTransactionResponse(entry))
+}
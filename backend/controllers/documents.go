@@ -0,0 +1,105 @@
+package controllers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"grocery-store-api/documents"
+	"grocery-store-api/models"
+	"grocery-store-api/services"
+)
+
+// DocumentHandler отдает печатные документы в PDF.
+type DocumentHandler struct {
+	SaleService    services.SaleService
+	SupplyService  services.SupplyService
+	ProductService services.ProductService
+	StoreName      string
+}
+
+func (h *DocumentHandler) SaleReceipt(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID)
+		return
+	}
+
+	sale, err := h.SaleService.GetSaleByID(uint(id))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := documents.Receipt(&buf, h.StoreName, sale); err != nil {
+		c.Error(err)
+		return
+	}
+	sendPDF(c, fmt.Sprintf("receipt-%d.pdf", sale.ID), &buf)
+}
+
+func (h *DocumentHandler) SupplyWaybill(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID)
+		return
+	}
+
+	supply, err := h.SupplyService.GetSupplyByID(uint(id))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := documents.Waybill(&buf, h.StoreName, supply); err != nil {
+		c.Error(err)
+		return
+	}
+	sendPDF(c, fmt.Sprintf("waybill-%d.pdf", supply.ID), &buf)
+}
+
+// ProductLabels печатает ценники выбранных товаров в нужном числе копий.
+func (h *DocumentHandler) ProductLabels(c *gin.Context) {
+	var req LabelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindError(err))
+		return
+	}
+
+	ids := make([]uint, 0, len(req.Items))
+	for _, item := range req.Items {
+		ids = append(ids, item.ProductID)
+	}
+	products, err := h.ProductService.GetProductsByIDs(ids)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var labels []models.Product
+	for i, item := range req.Items {
+		for copies := max(item.Copies, 1); copies > 0; copies-- {
+			labels = append(labels, products[i])
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := documents.Labels(&buf, labels, time.Now()); err != nil {
+		c.Error(err)
+		return
+	}
+	sendPDF(c, "labels.pdf", &buf)
+}
+
+// sendPDF отдает документ для просмотра в браузере. Документ формируется
+// в памяти целиком, чтобы при ошибке можно было вернуть обычный ответ с ней.
+func sendPDF(c *gin.Context, filename string, buf *bytes.Buffer) {
+	c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="%s"`, filename))
+	c.Data(http.StatusOK, documents.ContentType, buf.Bytes())
+}
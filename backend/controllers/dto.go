@@ -0,0 +1,2137 @@
+package controllers
+
+import (
+	"encoding/json"
+	"grocery-store-api/models"
+	"grocery-store-api/services"
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Запросы и ответы API. Модели GORM наружу не отдаются, чтобы внутренние
+// поля (например, хэш пароля) не попадали к клиентам, а форма API могла
+// меняться независимо от схемы базы данных.
+
+type LoginRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+type RegisterRequest struct {
+	Username string `json:"username" binding:"required,min=3,max=50"`
+	Password string `json:"password" binding:"required,max=72"`
+	Role     string `json:"role" binding:"required,role" enums:"admin,manager,cashier"`
+}
+
+type UpdateRoleRequest struct {
+	Role string `json:"role" binding:"required,role" enums:"admin,manager,cashier"`
+}
+
+// UserDepartmentRequest — отдел сотрудника; null открепляет от отдела.
+type UserDepartmentRequest struct {
+	DepartmentID *uint `json:"department_id"`
+}
+
+type ResetPasswordRequest struct {
+	Password string `json:"password" binding:"required,max=72"`
+}
+
+type RolePermissionsRequest struct {
+	Permissions []string `json:"permissions" binding:"required,dive,permission"`
+}
+
+type UserResponse struct {
+	ID                  uint       `json:"id"`
+	Username            string     `json:"username"`
+	Role                string     `json:"role"`
+	Disabled            bool       `json:"disabled"`
+	CreatedAt           time.Time  `json:"created_at"`
+	LastLoginAt         *time.Time `json:"last_login_at"`
+	FailedLoginAttempts int        `json:"failed_login_attempts"`
+	LockedUntil         *time.Time `json:"locked_until"`
+	DepartmentID        *uint      `json:"department_id"`
+}
+
+func newUserResponse(user *models.User) UserResponse {
+	return UserResponse{
+		ID:                  user.ID,
+		Username:            user.Username,
+		Role:                user.Role,
+		Disabled:            user.Disabled,
+		CreatedAt:           user.CreatedAt,
+		LastLoginAt:         user.LastLoginAt,
+		FailedLoginAttempts: user.FailedLoginAttempts,
+		LockedUntil:         user.LockedUntil,
+		DepartmentID:        user.DepartmentID,
+	}
+}
+
+func newUserResponses(users []models.User) []UserResponse {
+	result := make([]UserResponse, 0, len(users))
+	for i := range users {
+		result = append(result, newUserResponse(&users[i]))
+	}
+	return result
+}
+
+// UserSummary — краткие данные пользователя во вложенных объектах
+// (кассир продажи, утвердивший поставку).
+type UserSummary struct {
+	ID       uint   `json:"id"`
+	Username string `json:"username"`
+}
+
+func newUserSummary(user *models.User) *UserSummary {
+	if user.ID == 0 {
+		return nil
+	}
+	return &UserSummary{ID: user.ID, Username: user.Username}
+}
+
+// archivedAt возвращает время архивации или nil для активной записи.
+func archivedAt(deletedAt gorm.DeletedAt) *time.Time {
+	if !deletedAt.Valid {
+		return nil
+	}
+	return &deletedAt.Time
+}
+
+// DepartmentRequest — данные отдела. Проценты программы лояльности равны
+// нулю, если не переданы, то есть баллы в отделе не начисляются и не
+// списываются.
+type DepartmentRequest struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	Description string `json:"description" binding:"max=1000"`
+	ManagerID   uint   `json:"manager_id"`
+
+	LoyaltyAccrualPercent float64 `json:"loyalty_accrual_percent" binding:"gte=0,lte=100"`
+	LoyaltyRedeemPercent  float64 `json:"loyalty_redeem_percent" binding:"gte=0,lte=100"`
+}
+
+// newDepartmentRequest возвращает текущее состояние отдела в форме запроса,
+// к которому применяется PATCH.
+func newDepartmentRequest(department *models.Department) DepartmentRequest {
+	return DepartmentRequest{
+		Name:        department.Name,
+		Description: department.Description,
+		ManagerID:   department.ManagerID,
+
+		LoyaltyAccrualPercent: department.LoyaltyAccrualPercent,
+		LoyaltyRedeemPercent:  department.LoyaltyRedeemPercent,
+	}
+}
+
+func (r DepartmentRequest) toModel() models.Department {
+	return models.Department{
+		Name:        r.Name,
+		Description: r.Description,
+		ManagerID:   r.ManagerID,
+
+		LoyaltyAccrualPercent: r.LoyaltyAccrualPercent,
+		LoyaltyRedeemPercent:  r.LoyaltyRedeemPercent,
+	}
+}
+
+type DepartmentResponse struct {
+	ID          uint   `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	ManagerID   uint   `json:"manager_id"`
+
+	LoyaltyAccrualPercent float64 `json:"loyalty_accrual_percent"`
+	LoyaltyRedeemPercent  float64 `json:"loyalty_redeem_percent"`
+
+	Version    uint       `json:"version"`
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
+}
+
+func newDepartmentResponse(department *models.Department) DepartmentResponse {
+	return DepartmentResponse{
+		ID:          department.ID,
+		Name:        department.Name,
+		Description: department.Description,
+		ManagerID:   department.ManagerID,
+
+		LoyaltyAccrualPercent: department.LoyaltyAccrualPercent,
+		LoyaltyRedeemPercent:  department.LoyaltyRedeemPercent,
+
+		Version:    department.Version,
+		ArchivedAt: archivedAt(department.DeletedAt),
+	}
+}
+
+func newDepartmentResponses(departments []models.Department) []DepartmentResponse {
+	result := make([]DepartmentResponse, 0, len(departments))
+	for i := range departments {
+		result = append(result, newDepartmentResponse(&departments[i]))
+	}
+	return result
+}
+
+// SupplierRequest — данные поставщика. payment_term_days — отсрочка
+// оплаты счетов в днях.
+type SupplierRequest struct {
+	Name            string `json:"name" binding:"required,max=100"`
+	Phone           string `json:"phone" binding:"max=30"`
+	ContactPerson   string `json:"contact_person" binding:"max=100"`
+	PaymentTermDays int    `json:"payment_term_days" binding:"gte=0,lte=365"`
+}
+
+func newSupplierRequest(supplier *models.Supplier) SupplierRequest {
+	return SupplierRequest{
+		Name:            supplier.Name,
+		Phone:           supplier.Phone,
+		ContactPerson:   supplier.ContactPerson,
+		PaymentTermDays: supplier.PaymentTermDays,
+	}
+}
+
+func (r SupplierRequest) toModel() models.Supplier {
+	return models.Supplier{
+		Name:            r.Name,
+		Phone:           r.Phone,
+		ContactPerson:   r.ContactPerson,
+		PaymentTermDays: r.PaymentTermDays,
+	}
+}
+
+type SupplierResponse struct {
+	ID              uint       `json:"id"`
+	Name            string     `json:"name"`
+	Phone           string     `json:"phone"`
+	ContactPerson   string     `json:"contact_person"`
+	PaymentTermDays int        `json:"payment_term_days"`
+	Version         uint       `json:"version"`
+	ArchivedAt      *time.Time `json:"archived_at,omitempty"`
+}
+
+func newSupplierResponse(supplier *models.Supplier) SupplierResponse {
+	return SupplierResponse{
+		ID:              supplier.ID,
+		Name:            supplier.Name,
+		Phone:           supplier.Phone,
+		ContactPerson:   supplier.ContactPerson,
+		PaymentTermDays: supplier.PaymentTermDays,
+		Version:         supplier.Version,
+		ArchivedAt:      archivedAt(supplier.DeletedAt),
+	}
+}
+
+func newSupplierResponses(suppliers []models.Supplier) []SupplierResponse {
+	result := make([]SupplierResponse, 0, len(suppliers))
+	for i := range suppliers {
+		result = append(result, newSupplierResponse(&suppliers[i]))
+	}
+	return result
+}
+
+type ProductRequest struct {
+	Name         string    `json:"name" binding:"required,max=100"`
+	DepartmentID uint      `json:"department_id" binding:"required"`
+	SupplierID   uint      `json:"supplier_id" binding:"required"`
+	Grade        string    `json:"grade" binding:"required,grade" enums:"A,B,C"`
+	Price        float64   `json:"price" binding:"gt=0"`
+	CurrentQty   int       `json:"current_quantity" binding:"gte=0"`
+	MinThreshold int       `json:"min_threshold" binding:"gte=0"`
+	ExpiryDate   time.Time `json:"expiry_date" binding:"required"`
+	StorageCond  string    `json:"storage_cond" binding:"required,storage_cond" enums:"ambient,chilled,frozen,dry"`
+	Unit         string    `json:"unit" binding:"omitempty,unit" enums:"pcs,kg,l" default:"pcs"`
+	NetQuantity  float64   `json:"net_quantity" binding:"gte=0" default:"1"`
+}
+
+func newProductRequest(product *models.Product) ProductRequest {
+	return ProductRequest{
+		Name:         product.Name,
+		DepartmentID: product.DepartmentID,
+		SupplierID:   product.SupplierID,
+		Grade:        product.Grade,
+		Price:        product.Price,
+		CurrentQty:   product.CurrentQty,
+		MinThreshold: product.MinThreshold,
+		ExpiryDate:   product.ExpiryDate,
+		StorageCond:  product.StorageCond,
+		Unit:         product.Unit,
+		NetQuantity:  product.NetQuantity,
+	}
+}
+
+func (r ProductRequest) toModel() models.Product {
+	unit, netQuantity := productPackaging(r.Unit, r.NetQuantity)
+	return models.Product{
+		Name:         r.Name,
+		DepartmentID: r.DepartmentID,
+		SupplierID:   r.SupplierID,
+		Grade:        r.Grade,
+		Price:        r.Price,
+		CurrentQty:   r.CurrentQty,
+		MinThreshold: r.MinThreshold,
+		ExpiryDate:   r.ExpiryDate,
+		StorageCond:  r.StorageCond,
+		Unit:         unit,
+		NetQuantity:  netQuantity,
+	}
+}
+
+// productPackaging подставляет значения по умолчанию для необязательных
+// полей фасовки: товар продается поштучно, по одной единице.
+func productPackaging(unit string, netQuantity float64) (string, float64) {
+	if unit == "" {
+		unit = models.UnitPiece
+	}
+	if netQuantity == 0 {
+		netQuantity = 1
+	}
+	return unit, netQuantity
+}
+
+type ProductResponse struct {
+	ID           uint      `json:"id"`
+	Name         string    `json:"name"`
+	DepartmentID uint      `json:"department_id"`
+	SupplierID   uint      `json:"supplier_id"`
+	Grade        string    `json:"grade"`
+	Price        float64   `json:"price"`
+	CurrentQty   int       `json:"current_quantity"`
+	MinThreshold int       `json:"min_threshold"`
+	ExpiryDate   time.Time `json:"expiry_date"`
+	StorageCond  string    `json:"storage_cond"`
+	Unit         string    `json:"unit"`
+	NetQuantity  float64   `json:"net_quantity"`
+	Version      uint      `json:"version"`
+
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
+
+	Department *DepartmentResponse `json:"department,omitempty"`
+	Supplier   *SupplierResponse   `json:"supplier,omitempty"`
+}
+
+func newProductResponse(product *models.Product) ProductResponse {
+	response := ProductResponse{
+		ID:           product.ID,
+		Name:         product.Name,
+		DepartmentID: product.DepartmentID,
+		SupplierID:   product.SupplierID,
+		Grade:        product.Grade,
+		Price:        product.Price,
+		CurrentQty:   product.CurrentQty,
+		MinThreshold: product.MinThreshold,
+		ExpiryDate:   product.ExpiryDate,
+		StorageCond:  product.StorageCond,
+		Unit:         product.Unit,
+		NetQuantity:  product.NetQuantity,
+		Version:      product.Version,
+		ArchivedAt:   archivedAt(product.DeletedAt),
+	}
+
+	// Связанные сущности отдаются, только если они были загружены
+	if product.Department.ID != 0 {
+		department := newDepartmentResponse(&product.Department)
+		response.Department = &department
+	}
+	if product.Supplier.ID != 0 {
+		supplier := newSupplierResponse(&product.Supplier)
+		response.Supplier = &supplier
+	}
+
+	return response
+}
+
+func newProductResponses(products []models.Product) []ProductResponse {
+	result := make([]ProductResponse, 0, len(products))
+	for i := range products {
+		result = append(result, newProductResponse(&products[i]))
+	}
+	return result
+}
+
+// ProductSummary — краткие данные товара во вложенных объектах продаж и поставок.
+type ProductSummary struct {
+	ID       uint    `json:"id"`
+	Name     string  `json:"name"`
+	Price    float64 `json:"price"`
+	Archived bool    `json:"archived,omitempty"`
+}
+
+func newProductSummary(product *models.Product) *ProductSummary {
+	if product.ID == 0 {
+		return nil
+	}
+	return &ProductSummary{
+		ID:       product.ID,
+		Name:     product.Name,
+		Price:    product.Price,
+		Archived: product.DeletedAt.Valid,
+	}
+}
+
+// PaymentRequest — часть оплаты продажи. Для наличных tendered — сколько
+// получено от покупателя (по умолчанию ровно amount), сдача считается
+// сервером.
+type PaymentRequest struct {
+	Method   string  `json:"method" binding:"required,payment_method" enums:"cash,card"`
+	Amount   float64 `json:"amount" binding:"gt=0"`
+	Tendered float64 `json:"tendered" binding:"gte=0"`
+}
+
+// SaleRequest — продажа. redeem_points баллов покупателя customer_id идут
+// в оплату (1 балл = 1 рубль). Без payments остаток оплачивается
+// наличными без сдачи; иначе сумма платежей должна совпадать с
+// total_price за вычетом баллов.
+type SaleRequest struct {
+	ProductID    uint             `json:"product_id" binding:"required"`
+	Quantity     int              `json:"quantity" binding:"gt=0"`
+	CustomerID   *uint            `json:"customer_id"`
+	RedeemPoints int              `json:"redeem_points" binding:"gte=0"`
+	Payments     []PaymentRequest `json:"payments" binding:"omitempty,max=10,dive"`
+}
+
+func (r SaleRequest) toModel() models.Sale {
+	sale := models.Sale{
+		ProductID:      r.ProductID,
+		Quantity:       r.Quantity,
+		CustomerID:     r.CustomerID,
+		PointsRedeemed: r.RedeemPoints,
+	}
+	for _, payment := range r.Payments {
+		sale.Payments = append(sale.Payments, models.Payment{
+			Method:   payment.Method,
+			Amount:   payment.Amount,
+			Tendered: payment.Tendered,
+		})
+	}
+	return sale
+}
+
+type PaymentResponse struct {
+	ID            uint    `json:"id"`
+	Method        string  `json:"method" enums:"cash,card,points"`
+	Amount        float64 `json:"amount"`
+	Tendered      float64 `json:"tendered"`
+	ChangeDue     float64 `json:"change_due"`
+	TransactionID string  `json:"transaction_id,omitempty"`
+	AuthCode      string  `json:"auth_code,omitempty"`
+	CardMask      string  `json:"card_mask,omitempty"`
+}
+
+func newPaymentResponses(payments []models.Payment) []PaymentResponse {
+	result := make([]PaymentResponse, 0, len(payments))
+	for _, payment := range payments {
+		result = append(result, PaymentResponse{
+			ID:            payment.ID,
+			Method:        payment.Method,
+			Amount:        payment.Amount,
+			Tendered:      payment.Tendered,
+			ChangeDue:     payment.ChangeDue,
+			TransactionID: payment.TransactionID,
+			AuthCode:      payment.AuthCode,
+			CardMask:      payment.CardMask,
+		})
+	}
+	return result
+}
+
+type SaleResponse struct {
+	ID         uint      `json:"id"`
+	ProductID  uint      `json:"product_id"`
+	Quantity   int       `json:"quantity"`
+	TotalPrice float64   `json:"total_price"`
+	SaleDate   time.Time `json:"sale_date"`
+	CashierID  uint      `json:"cashier_id"`
+	ShiftID    *uint     `json:"shift_id"`
+	CustomerID *uint     `json:"customer_id"`
+
+	PointsRedeemed int `json:"points_redeemed"`
+	PointsEarned   int `json:"points_earned"`
+
+	ReturnedQuantity int `json:"returned_quantity"`
+
+	FiscalStatus         string     `json:"fiscal_status" enums:"none,pending,registered,failed"`
+	FiscalDocumentNumber string     `json:"fiscal_document_number,omitempty"`
+	FiscalSign           string     `json:"fiscal_sign,omitempty"`
+	FiscalizedAt         *time.Time `json:"fiscalized_at,omitempty"`
+
+	Payments  []PaymentResponse `json:"payments"`
+	ChangeDue float64           `json:"change_due"`
+
+	Product *ProductSummary `json:"product,omitempty"`
+	Cashier *UserSummary    `json:"cashier,omitempty"`
+}
+
+func newSaleResponse(sale *models.Sale) SaleResponse {
+	response := SaleResponse{
+		ID:         sale.ID,
+		ProductID:  sale.ProductID,
+		Quantity:   sale.Quantity,
+		TotalPrice: sale.TotalPrice,
+		SaleDate:   sale.SaleDate,
+		CashierID:  sale.CashierID,
+		ShiftID:    sale.ShiftID,
+		CustomerID: sale.CustomerID,
+
+		PointsRedeemed: sale.PointsRedeemed,
+		PointsEarned:   sale.PointsEarned,
+
+		ReturnedQuantity: sale.ReturnedQuantity,
+
+		FiscalStatus:         sale.FiscalStatus,
+		FiscalDocumentNumber: sale.FiscalDocumentNumber,
+		FiscalSign:           sale.FiscalSign,
+		FiscalizedAt:         sale.FiscalizedAt,
+
+		Payments: newPaymentResponses(sale.Payments),
+
+		Product: newProductSummary(&sale.Product),
+		Cashier: newUserSummary(&sale.Cashier),
+	}
+	for _, payment := range sale.Payments {
+		response.ChangeDue += payment.ChangeDue
+	}
+	return response
+}
+
+func newSaleResponses(sales []models.Sale) []SaleResponse {
+	result := make([]SaleResponse, 0, len(sales))
+	for i := range sales {
+		result = append(result, newSaleResponse(&sales[i]))
+	}
+	return result
+}
+
+// PaymentMethodSummary — сколько платежей и на какую сумму принято одним
+// способом оплаты.
+type PaymentMethodSummary struct {
+	Count  int     `json:"count"`
+	Amount float64 `json:"amount"`
+}
+
+// SupplyItemRequest — позиция поставки. expiry_date — срок годности
+// партии; по нему оценивается, не привозит ли поставщик товар на исходе
+// срока.
+type SupplyItemRequest struct {
+	ProductID  uint       `json:"product_id" binding:"required"`
+	Quantity   int        `json:"quantity" binding:"gt=0"`
+	UnitPrice  float64    `json:"unit_price" binding:"gte=0"`
+	ExpiryDate *time.Time `json:"expiry_date"`
+}
+
+type SupplyRequest struct {
+	Supply struct {
+		SupplierID uint    `json:"supplier_id" binding:"required"`
+		TotalCost  float64 `json:"total_cost" binding:"gte=0"`
+	} `json:"supply"`
+	Items []SupplyItemRequest `json:"items" binding:"required,min=1,dive"`
+}
+
+func (r SupplyRequest) toModel() (models.Supply, []models.SupplyItem) {
+	supply := models.Supply{
+		SupplierID: r.Supply.SupplierID,
+		TotalCost:  r.Supply.TotalCost,
+	}
+
+	items := make([]models.SupplyItem, 0, len(r.Items))
+	for _, item := range r.Items {
+		items = append(items, models.SupplyItem{
+			ProductID:  item.ProductID,
+			Quantity:   item.Quantity,
+			UnitPrice:  item.UnitPrice,
+			ExpiryDate: item.ExpiryDate,
+		})
+	}
+
+	return supply, items
+}
+
+type LabelItemRequest struct {
+	ProductID uint `json:"product_id" binding:"required"`
+	Copies    int  `json:"copies" binding:"omitempty,min=1,max=50" default:"1"`
+}
+
+type LabelRequest struct {
+	Items []LabelItemRequest `json:"items" binding:"required,min=1,max=100,dive"`
+}
+
+type SupplyItemResponse struct {
+	ID         uint       `json:"id"`
+	SupplyID   uint       `json:"supply_id"`
+	ProductID  uint       `json:"product_id"`
+	Quantity   int        `json:"quantity"`
+	UnitPrice  float64    `json:"unit_price"`
+	ExpiryDate *time.Time `json:"expiry_date,omitempty"`
+
+	Product *ProductSummary `json:"product,omitempty"`
+}
+
+type SupplyResponse struct {
+	ID         uint      `json:"id"`
+	SupplierID uint      `json:"supplier_id"`
+	SupplyDate time.Time `json:"supply_date"`
+	TotalCost  float64   `json:"total_cost"`
+	ApprovedBy uint      `json:"approved_by"`
+
+	Supplier *SupplierResponse    `json:"supplier,omitempty"`
+	Approver *UserSummary         `json:"approver,omitempty"`
+	Items    []SupplyItemResponse `json:"items,omitempty"`
+}
+
+func newSupplyResponse(supply *models.Supply) SupplyResponse {
+	response := SupplyResponse{
+		ID:         supply.ID,
+		SupplierID: supply.SupplierID,
+		SupplyDate: supply.SupplyDate,
+		TotalCost:  supply.TotalCost,
+		ApprovedBy: supply.ApprovedBy,
+		Approver:   newUserSummary(&supply.Approver),
+	}
+
+	if supply.Supplier.ID != 0 {
+		supplier := newSupplierResponse(&supply.Supplier)
+		response.Supplier = &supplier
+	}
+
+	for i := range supply.Items {
+		item := &supply.Items[i]
+		response.Items = append(response.Items, SupplyItemResponse{
+			ID:         item.ID,
+			SupplyID:   item.SupplyID,
+			ProductID:  item.ProductID,
+			Quantity:   item.Quantity,
+			UnitPrice:  item.UnitPrice,
+			ExpiryDate: item.ExpiryDate,
+			Product:    newProductSummary(&item.Product),
+		})
+	}
+
+	return response
+}
+
+func newSupplyResponses(supplies []models.Supply) []SupplyResponse {
+	result := make([]SupplyResponse, 0, len(supplies))
+	for i := range supplies {
+		result = append(result, newSupplyResponse(&supplies[i]))
+	}
+	return result
+}
+
+type ProductSupplierRequest struct {
+	SupplierSKU string `json:"supplier_sku" binding:"max=50"`
+	Preferred   bool   `json:"preferred"`
+}
+
+type ProductSupplierResponse struct {
+	ProductID   uint   `json:"product_id"`
+	SupplierID  uint   `json:"supplier_id"`
+	SupplierSKU string `json:"supplier_sku"`
+	Preferred   bool   `json:"preferred"`
+}
+
+func newProductSupplierResponse(link *models.ProductSupplier) ProductSupplierResponse {
+	return ProductSupplierResponse{
+		ProductID:   link.ProductID,
+		SupplierID:  link.SupplierID,
+		SupplierSKU: link.SupplierSKU,
+		Preferred:   link.Preferred,
+	}
+}
+
+type PriceListItemRequest struct {
+	ProductID   uint    `json:"product_id" binding:"required"`
+	SupplierSKU string  `json:"supplier_sku" binding:"max=50"`
+	PackSize    int     `json:"pack_size" binding:"gte=0"`
+	Cost        float64 `json:"cost" binding:"gt=0"`
+}
+
+// PriceListRequest — прайс-лист поставщика. Без valid_from цены действуют
+// с сегодняшнего дня; pack_size по умолчанию 1.
+type PriceListRequest struct {
+	ValidFrom time.Time              `json:"valid_from"`
+	Items     []PriceListItemRequest `json:"items" binding:"required,min=1,dive"`
+}
+
+func (r PriceListRequest) toEntries() []services.PriceListEntry {
+	entries := make([]services.PriceListEntry, 0, len(r.Items))
+	for _, item := range r.Items {
+		entries = append(entries, services.PriceListEntry{
+			ProductID:   item.ProductID,
+			SupplierSKU: item.SupplierSKU,
+			PackSize:    item.PackSize,
+			Cost:        item.Cost,
+		})
+	}
+	return entries
+}
+
+// SupplierPriceResponse — цена поставщика. cost — цена упаковки,
+// unit_cost — цена единицы товара.
+type SupplierPriceResponse struct {
+	ID         uint      `json:"id"`
+	SupplierID uint      `json:"supplier_id"`
+	ProductID  uint      `json:"product_id"`
+	ValidFrom  time.Time `json:"valid_from"`
+	PackSize   int       `json:"pack_size"`
+	Cost       float64   `json:"cost"`
+	UnitCost   float64   `json:"unit_cost"`
+	CreatedBy  uint      `json:"created_by"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func newSupplierPriceResponse(price *models.SupplierPrice) *SupplierPriceResponse {
+	if price == nil {
+		return nil
+	}
+	return &SupplierPriceResponse{
+		ID:         price.ID,
+		SupplierID: price.SupplierID,
+		ProductID:  price.ProductID,
+		ValidFrom:  price.ValidFrom,
+		PackSize:   price.PackSize,
+		Cost:       price.Cost,
+		UnitCost:   roundMoney(price.UnitCost()),
+		CreatedBy:  price.CreatedBy,
+		CreatedAt:  price.CreatedAt,
+	}
+}
+
+func newSupplierPriceResponses(prices []models.SupplierPrice) []SupplierPriceResponse {
+	result := make([]SupplierPriceResponse, 0, len(prices))
+	for i := range prices {
+		result = append(result, *newSupplierPriceResponse(&prices[i]))
+	}
+	return result
+}
+
+type PriceListItemResponse struct {
+	ProductID   uint                   `json:"product_id"`
+	ProductName string                 `json:"product_name"`
+	SupplierSKU string                 `json:"supplier_sku"`
+	Preferred   bool                   `json:"preferred"`
+	Price       *SupplierPriceResponse `json:"price"`
+}
+
+func newPriceListItemResponses(items []services.PriceListItem) []PriceListItemResponse {
+	result := make([]PriceListItemResponse, 0, len(items))
+	for i := range items {
+		item := &items[i]
+		result = append(result, PriceListItemResponse{
+			ProductID:   item.Product.ID,
+			ProductName: item.Product.Name,
+			SupplierSKU: item.SupplierSKU,
+			Preferred:   item.Preferred,
+			Price:       newSupplierPriceResponse(item.Price),
+		})
+	}
+	return result
+}
+
+// SupplierOfferResponse — предложение поставщика по товару; price равен
+// null, если цена на дату не задана.
+type SupplierOfferResponse struct {
+	SupplierID   uint                   `json:"supplier_id"`
+	SupplierName string                 `json:"supplier_name"`
+	SupplierSKU  string                 `json:"supplier_sku"`
+	Preferred    bool                   `json:"preferred"`
+	Price        *SupplierPriceResponse `json:"price"`
+}
+
+func newSupplierOfferResponses(offers []services.SupplierOffer) []SupplierOfferResponse {
+	result := make([]SupplierOfferResponse, 0, len(offers))
+	for i := range offers {
+		offer := &offers[i]
+		result = append(result, SupplierOfferResponse{
+			SupplierID:   offer.Supplier.ID,
+			SupplierName: offer.Supplier.Name,
+			SupplierSKU:  offer.SupplierSKU,
+			Preferred:    offer.Preferred,
+			Price:        newSupplierPriceResponse(offer.Price),
+		})
+	}
+	return result
+}
+
+// PriceComparisonResponse — сравнение цен поставщиков по товару.
+// savings_per_unit — на сколько дешевле единица товара у лучшего
+// поставщика, чем у основного (0, если основной и есть лучший или у
+// основного нет цены).
+type PriceComparisonResponse struct {
+	Product             ProductSummary          `json:"product"`
+	PreferredSupplierID *uint                   `json:"preferred_supplier_id"`
+	BestSupplierID      *uint                   `json:"best_supplier_id"`
+	SavingsPerUnit      float64                 `json:"savings_per_unit"`
+	Offers              []SupplierOfferResponse `json:"offers"`
+}
+
+func newPriceComparisonResponses(comparisons []services.PriceComparison) []PriceComparisonResponse {
+	result := make([]PriceComparisonResponse, 0, len(comparisons))
+	for i := range comparisons {
+		comparison := &comparisons[i]
+		response := PriceComparisonResponse{
+			Product: *newProductSummary(&comparison.Product),
+			Offers:  newSupplierOfferResponses(comparison.Offers),
+		}
+		if comparison.Preferred != nil {
+			response.PreferredSupplierID = &comparison.Preferred.Supplier.ID
+		}
+		if comparison.Best != nil {
+			response.BestSupplierID = &comparison.Best.Supplier.ID
+			if comparison.Preferred != nil && comparison.Preferred.Price != nil {
+				response.SavingsPerUnit = roundMoney(comparison.Preferred.Price.UnitCost() - comparison.Best.Price.UnitCost())
+			}
+		}
+		result = append(result, response)
+	}
+	return result
+}
+
+// InvoiceRequest — счет поставщика за поставку. Пустые поля заполняются
+// по поставке: сумма — стоимостью поставки, дата — сегодняшним днем, срок
+// оплаты — по отсрочке поставщика.
+type InvoiceRequest struct {
+	Number      string    `json:"number" binding:"required,max=50"`
+	InvoiceDate time.Time `json:"invoice_date"`
+	DueDate     time.Time `json:"due_date"`
+	Amount      float64   `json:"amount" binding:"gte=0"`
+}
+
+type InvoicePaymentRequest struct {
+	Amount    float64   `json:"amount" binding:"gt=0"`
+	PaidAt    time.Time `json:"paid_at"`
+	Method    string    `json:"method" binding:"omitempty,invoice_payment_method" enums:"bank_transfer,cash"`
+	Reference string    `json:"reference" binding:"max=100"`
+}
+
+type InvoicePaymentResponse struct {
+	ID        uint      `json:"id"`
+	Amount    float64   `json:"amount"`
+	PaidAt    time.Time `json:"paid_at"`
+	Method    string    `json:"method" enums:"bank_transfer,cash"`
+	Reference string    `json:"reference,omitempty"`
+	CreatedBy uint      `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// InvoiceResponse — счет поставщика. days_overdue — на сколько дней
+// просрочена оплата (0, если счет оплачен или срок не наступил).
+type InvoiceResponse struct {
+	ID                    uint                     `json:"id"`
+	SupplierID            uint                     `json:"supplier_id"`
+	SupplierName          string                   `json:"supplier_name"`
+	SupplyID              uint                     `json:"supply_id"`
+	Number                string                   `json:"number"`
+	InvoiceDate           time.Time                `json:"invoice_date"`
+	DueDate               time.Time                `json:"due_date"`
+	Amount                float64                  `json:"amount"`
+	PaidAmount            float64                  `json:"paid_amount"`
+	Outstanding           float64                  `json:"outstanding"`
+	Status                string                   `json:"status" enums:"open,partial,paid"`
+	DaysOverdue           int                      `json:"days_overdue"`
+	OverdueNotifiedAt     *time.Time               `json:"overdue_notified_at,omitempty"`
+	OverdueAcknowledgedAt *time.Time               `json:"overdue_acknowledged_at,omitempty"`
+	OverdueAcknowledgedBy *uint                    `json:"overdue_acknowledged_by,omitempty"`
+	CreatedBy             uint                     `json:"created_by"`
+	CreatedAt             time.Time                `json:"created_at"`
+	Payments              []InvoicePaymentResponse `json:"payments,omitempty"`
+}
+
+func newInvoiceResponse(invoice *models.SupplierInvoice, today time.Time) InvoiceResponse {
+	response := InvoiceResponse{
+		ID:                    invoice.ID,
+		SupplierID:            invoice.SupplierID,
+		SupplierName:          invoice.Supplier.Name,
+		SupplyID:              invoice.SupplyID,
+		Number:                invoice.Number,
+		InvoiceDate:           invoice.InvoiceDate,
+		DueDate:               invoice.DueDate,
+		Amount:                invoice.Amount,
+		PaidAmount:            invoice.PaidAmount,
+		Outstanding:           roundMoney(invoice.Outstanding()),
+		Status:                invoice.Status,
+		OverdueNotifiedAt:     invoice.OverdueNotifiedAt,
+		OverdueAcknowledgedAt: invoice.OverdueAcknowledgedAt,
+		OverdueAcknowledgedBy: invoice.OverdueAcknowledgedBy,
+		CreatedBy:             invoice.CreatedBy,
+		CreatedAt:             invoice.CreatedAt,
+	}
+	if days := services.DaysOverdue(invoice.DueDate, today); invoice.Status != models.InvoicePaid && days > 0 {
+		response.DaysOverdue = days
+	}
+	for _, payment := range invoice.Payments {
+		response.Payments = append(response.Payments, InvoicePaymentResponse{
+			ID:        payment.ID,
+			Amount:    payment.Amount,
+			PaidAt:    payment.PaidAt,
+			Method:    payment.Method,
+			Reference: payment.Reference,
+			CreatedBy: payment.CreatedBy,
+			CreatedAt: payment.CreatedAt,
+		})
+	}
+	return response
+}
+
+func newInvoiceResponses(invoices []models.SupplierInvoice, today time.Time) []InvoiceResponse {
+	result := make([]InvoiceResponse, 0, len(invoices))
+	for i := range invoices {
+		result = append(result, newInvoiceResponse(&invoices[i], today))
+	}
+	return result
+}
+
+// WriteOffRequest — акт списания товаров отдела. photo_url — ссылка на
+// фото испорченного товара; для причины other обязателен комментарий.
+type WriteOffRequest struct {
+	DepartmentID uint                  `json:"department_id" binding:"required"`
+	Reason       string                `json:"reason" binding:"required,write_off_reason" enums:"breakage,spoilage,theft,sample,other"`
+	Note         string                `json:"note" binding:"max=1000"`
+	PhotoURL     string                `json:"photo_url" binding:"omitempty,url,max=500"`
+	Items        []WriteOffItemRequest `json:"items" binding:"required,min=1,dive"`
+}
+
+type WriteOffItemRequest struct {
+	ProductID uint `json:"product_id" binding:"required"`
+	Quantity  int  `json:"quantity" binding:"gt=0"`
+}
+
+func (r WriteOffRequest) toModel() models.WriteOff {
+	writeOff := models.WriteOff{
+		DepartmentID: r.DepartmentID,
+		Reason:       r.Reason,
+		Note:         r.Note,
+		PhotoURL:     r.PhotoURL,
+		Items:        make([]models.WriteOffItem, 0, len(r.Items)),
+	}
+	for _, item := range r.Items {
+		writeOff.Items = append(writeOff.Items, models.WriteOffItem{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+		})
+	}
+	return writeOff
+}
+
+type WriteOffRejectRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+// WriteOffItemResponse — строка акта списания. unit_cost и cost
+// заполняются при утверждении акта.
+type WriteOffItemResponse struct {
+	ID          uint    `json:"id"`
+	ProductID   uint    `json:"product_id"`
+	ProductName string  `json:"product_name"`
+	Quantity    int     `json:"quantity"`
+	UnitCost    float64 `json:"unit_cost"`
+	Cost        float64 `json:"cost"`
+}
+
+// WriteOffResponse — акт списания. reviewed_by и reviewed_at — кто и
+// когда утвердил или отклонил акт.
+type WriteOffResponse struct {
+	ID             uint                   `json:"id"`
+	DepartmentID   uint                   `json:"department_id"`
+	DepartmentName string                 `json:"department_name"`
+	Reason         string                 `json:"reason" enums:"breakage,spoilage,theft,sample,other"`
+	Note           string                 `json:"note,omitempty"`
+	PhotoURL       string                 `json:"photo_url,omitempty"`
+	Status         string                 `json:"status" enums:"pending,approved,rejected"`
+	Quantity       int                    `json:"quantity"`
+	TotalCost      float64                `json:"total_cost"`
+	CreatedBy      uint                   `json:"created_by"`
+	CreatedAt      time.Time              `json:"created_at"`
+	ReviewedBy     *uint                  `json:"reviewed_by,omitempty"`
+	ReviewedAt     *time.Time             `json:"reviewed_at,omitempty"`
+	RejectReason   string                 `json:"reject_reason,omitempty"`
+	Items          []WriteOffItemResponse `json:"items"`
+}
+
+func newWriteOffResponse(writeOff *models.WriteOff) WriteOffResponse {
+	response := WriteOffResponse{
+		ID:             writeOff.ID,
+		DepartmentID:   writeOff.DepartmentID,
+		DepartmentName: writeOff.Department.Name,
+		Reason:         writeOff.Reason,
+		Note:           writeOff.Note,
+		PhotoURL:       writeOff.PhotoURL,
+		Status:         writeOff.Status,
+		TotalCost:      writeOff.TotalCost,
+		CreatedBy:      writeOff.CreatedBy,
+		CreatedAt:      writeOff.CreatedAt,
+		ReviewedBy:     writeOff.ReviewedBy,
+		ReviewedAt:     writeOff.ReviewedAt,
+		RejectReason:   writeOff.RejectReason,
+		Items:          make([]WriteOffItemResponse, 0, len(writeOff.Items)),
+	}
+	for _, item := range writeOff.Items {
+		response.Quantity += item.Quantity
+		response.Items = append(response.Items, WriteOffItemResponse{
+			ID:          item.ID,
+			ProductID:   item.ProductID,
+			ProductName: item.Product.Name,
+			Quantity:    item.Quantity,
+			UnitCost:    item.UnitCost,
+			Cost:        item.Cost,
+		})
+	}
+	return response
+}
+
+func newWriteOffResponses(writeOffs []models.WriteOff) []WriteOffResponse {
+	result := make([]WriteOffResponse, 0, len(writeOffs))
+	for i := range writeOffs {
+		result = append(result, newWriteOffResponse(&writeOffs[i]))
+	}
+	return result
+}
+
+// AgingTotalsResponse — неоплаченные остатки по срокам просрочки: current
+// — срок оплаты еще не наступил, остальные — просрочка в днях.
+type AgingTotalsResponse struct {
+	Invoices   int     `json:"invoices"`
+	Current    float64 `json:"current"`
+	Days1To30  float64 `json:"days_1_30"`
+	Days31To60 float64 `json:"days_31_60"`
+	Days61To90 float64 `json:"days_61_90"`
+	Over90     float64 `json:"over_90"`
+	Total      float64 `json:"total"`
+}
+
+func newAgingTotalsResponse(totals services.AgingTotals) AgingTotalsResponse {
+	return AgingTotalsResponse{
+		Invoices:   totals.Invoices,
+		Current:    totals.Current,
+		Days1To30:  totals.Days1To30,
+		Days31To60: totals.Days31To60,
+		Days61To90: totals.Days61To90,
+		Over90:     totals.Over90,
+		Total:      totals.Total,
+	}
+}
+
+type SupplierAgingResponse struct {
+	SupplierID    uint      `json:"supplier_id"`
+	SupplierName  string    `json:"supplier_name"`
+	OldestDueDate time.Time `json:"oldest_due_date"`
+	AgingTotalsResponse
+}
+
+type PayablesAgingResponse struct {
+	Date      string                  `json:"date" example:"2026-10-19"`
+	Suppliers []SupplierAgingResponse `json:"suppliers"`
+	Total     AgingTotalsResponse     `json:"total"`
+}
+
+func newPayablesAgingResponse(aging *services.PayablesAging) PayablesAgingResponse {
+	response := PayablesAgingResponse{
+		Date:      aging.Date.Format(exportDateLayout),
+		Suppliers: make([]SupplierAgingResponse, 0, len(aging.Suppliers)),
+		Total:     newAgingTotalsResponse(aging.Total),
+	}
+	for i := range aging.Suppliers {
+		row := &aging.Suppliers[i]
+		response.Suppliers = append(response.Suppliers, SupplierAgingResponse{
+			SupplierID:          row.Supplier.ID,
+			SupplierName:        row.Supplier.Name,
+			OldestDueDate:       row.OldestDueDate,
+			AgingTotalsResponse: newAgingTotalsResponse(row.AgingTotals),
+		})
+	}
+	return response
+}
+
+type OpenShiftRequest struct {
+	Register     string  `json:"register" binding:"required,max=20"`
+	OpeningFloat float64 `json:"opening_float" binding:"gte=0"`
+}
+
+type CashMovementRequest struct {
+	Type   string  `json:"type" binding:"required,cash_movement" enums:"in,out"`
+	Amount float64 `json:"amount" binding:"gt=0"`
+	Reason string  `json:"reason" binding:"max=255"`
+}
+
+type CloseShiftRequest struct {
+	CountedCash *float64 `json:"counted_cash" binding:"required,gte=0"`
+}
+
+type ShiftResponse struct {
+	ID           uint       `json:"id"`
+	CashierID    uint       `json:"cashier_id"`
+	Register     string     `json:"register"`
+	OpeningFloat float64    `json:"opening_float"`
+	OpenedAt     time.Time  `json:"opened_at"`
+	ClosedAt     *time.Time `json:"closed_at"`
+	ExpectedCash *float64   `json:"expected_cash,omitempty"`
+	CountedCash  *float64   `json:"counted_cash,omitempty"`
+
+	Cashier *UserSummary `json:"cashier,omitempty"`
+}
+
+func newShiftResponse(shift *models.Shift) ShiftResponse {
+	return ShiftResponse{
+		ID:           shift.ID,
+		CashierID:    shift.CashierID,
+		Register:     shift.Register,
+		OpeningFloat: shift.OpeningFloat,
+		OpenedAt:     shift.OpenedAt,
+		ClosedAt:     shift.ClosedAt,
+		ExpectedCash: shift.ExpectedCash,
+		CountedCash:  shift.CountedCash,
+		Cashier:      newUserSummary(&shift.Cashier),
+	}
+}
+
+func newShiftResponses(shifts []models.Shift) []ShiftResponse {
+	result := make([]ShiftResponse, 0, len(shifts))
+	for i := range shifts {
+		result = append(result, newShiftResponse(&shifts[i]))
+	}
+	return result
+}
+
+type CashMovementResponse struct {
+	ID        uint      `json:"id"`
+	ShiftID   uint      `json:"shift_id"`
+	Type      string    `json:"type" enums:"in,out"`
+	Amount    float64   `json:"amount"`
+	Reason    string    `json:"reason"`
+	CreatedBy uint      `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newCashMovementResponse(movement *models.CashMovement) CashMovementResponse {
+	return CashMovementResponse{
+		ID:        movement.ID,
+		ShiftID:   movement.ShiftID,
+		Type:      movement.Type,
+		Amount:    movement.Amount,
+		Reason:    movement.Reason,
+		CreatedBy: movement.CreatedBy,
+		CreatedAt: movement.CreatedAt,
+	}
+}
+
+// ShiftReportResponse — X-отчет открытой смены (final=false) или Z-отчет
+// закрытой (final=true). Refunds — выплаты по возвратам смены по способам
+// оплаты, возвраты наличными уменьшают expected_cash. Discrepancy =
+// counted_cash − expected_cash: отрицательное значение означает недостачу,
+// положительное — излишек.
+type ShiftReportResponse struct {
+	Shift        ShiftResponse          `json:"shift"`
+	Final        bool                   `json:"final"`
+	SalesCount   int64                  `json:"sales_count"`
+	SalesTotal   float64                `json:"sales_total"`
+	Payments     map[string]float64     `json:"payments"`
+	ReturnsCount int64                  `json:"returns_count"`
+	ReturnsTotal float64                `json:"returns_total"`
+	Refunds      map[string]float64     `json:"refunds"`
+	OpeningFloat float64                `json:"opening_float"`
+	CashIn       float64                `json:"cash_in"`
+	CashOut      float64                `json:"cash_out"`
+	ExpectedCash float64                `json:"expected_cash"`
+	CountedCash  *float64               `json:"counted_cash"`
+	Discrepancy  *float64               `json:"discrepancy"`
+	Movements    []CashMovementResponse `json:"movements"`
+}
+
+func newShiftReportResponse(report *services.ShiftReport) ShiftReportResponse {
+	response := ShiftReportResponse{
+		Shift:        newShiftResponse(&report.Shift),
+		Final:        report.Final,
+		SalesCount:   report.SalesCount,
+		SalesTotal:   report.SalesTotal,
+		Payments:     report.Payments,
+		ReturnsCount: report.ReturnsCount,
+		ReturnsTotal: report.ReturnsTotal,
+		Refunds:      report.Refunds,
+		OpeningFloat: report.Shift.OpeningFloat,
+		CashIn:       report.CashIn,
+		CashOut:      report.CashOut,
+		ExpectedCash: report.ExpectedCash,
+		CountedCash:  report.CountedCash,
+		Discrepancy:  report.Discrepancy,
+		Movements:    make([]CashMovementResponse, 0, len(report.Movements)),
+	}
+	for i := range report.Movements {
+		response.Movements = append(response.Movements, newCashMovementResponse(&report.Movements[i]))
+	}
+	return response
+}
+
+type SaleReturnRequest struct {
+	Quantity int    `json:"quantity" binding:"gt=0"`
+	Reason   string `json:"reason" binding:"required,max=500"`
+}
+
+type RefundResponse struct {
+	ID                    uint    `json:"id"`
+	Method                string  `json:"method" enums:"cash,card,points"`
+	Amount                float64 `json:"amount"`
+	OriginalTransactionID string  `json:"original_transaction_id,omitempty"`
+	TransactionID         string  `json:"transaction_id,omitempty"`
+}
+
+// SaleReturnResponse — возврат по продаже. amount — сумма всех выплат,
+// включая баллы; points_reversed — списанные баллы, начисленные за
+// возвращенный товар.
+type SaleReturnResponse struct {
+	ID             uint             `json:"id"`
+	SaleID         uint             `json:"sale_id"`
+	ShiftID        uint             `json:"shift_id"`
+	Quantity       int              `json:"quantity"`
+	Amount         float64          `json:"amount"`
+	PointsReversed int              `json:"points_reversed"`
+	Reason         string           `json:"reason"`
+	CreatedBy      uint             `json:"created_by"`
+	CreatedAt      time.Time        `json:"created_at"`
+	Refunds        []RefundResponse `json:"refunds"`
+
+	FiscalStatus         string     `json:"fiscal_status" enums:"none,pending,registered,failed"`
+	FiscalDocumentNumber string     `json:"fiscal_document_number,omitempty"`
+	FiscalSign           string     `json:"fiscal_sign,omitempty"`
+	FiscalizedAt         *time.Time `json:"fiscalized_at,omitempty"`
+}
+
+func newSaleReturnResponse(ret *models.SaleReturn) SaleReturnResponse {
+	response := SaleReturnResponse{
+		ID:             ret.ID,
+		SaleID:         ret.SaleID,
+		ShiftID:        ret.ShiftID,
+		Quantity:       ret.Quantity,
+		Amount:         ret.Amount,
+		PointsReversed: ret.PointsReversed,
+		Reason:         ret.Reason,
+		CreatedBy:      ret.CreatedBy,
+		CreatedAt:      ret.CreatedAt,
+		Refunds:        make([]RefundResponse, 0, len(ret.Refunds)),
+
+		FiscalStatus:         ret.FiscalStatus,
+		FiscalDocumentNumber: ret.FiscalDocumentNumber,
+		FiscalSign:           ret.FiscalSign,
+		FiscalizedAt:         ret.FiscalizedAt,
+	}
+	for _, refund := range ret.Refunds {
+		response.Refunds = append(response.Refunds, RefundResponse{
+			ID:                    refund.ID,
+			Method:                refund.Method,
+			Amount:                refund.Amount,
+			OriginalTransactionID: refund.OriginalTransactionID,
+			TransactionID:         refund.TransactionID,
+		})
+	}
+	return response
+}
+
+func newSaleReturnResponses(returns []models.SaleReturn) []SaleReturnResponse {
+	result := make([]SaleReturnResponse, 0, len(returns))
+	for i := range returns {
+		result = append(result, newSaleReturnResponse(&returns[i]))
+	}
+	return result
+}
+
+type CustomerRequest struct {
+	Name       string `json:"name" binding:"max=100"`
+	Phone      string `json:"phone" binding:"required,max=30"`
+	CardNumber string `json:"card_number" binding:"omitempty,numeric,min=6,max=20"`
+}
+
+func newCustomerRequest(customer *models.Customer) CustomerRequest {
+	return CustomerRequest{
+		Name:       customer.Name,
+		Phone:      customer.Phone,
+		CardNumber: customer.CardNumber,
+	}
+}
+
+func (r CustomerRequest) toModel() models.Customer {
+	return models.Customer{
+		Name:       r.Name,
+		Phone:      r.Phone,
+		CardNumber: r.CardNumber,
+	}
+}
+
+type CustomerResponse struct {
+	ID            uint      `json:"id"`
+	Name          string    `json:"name"`
+	Phone         string    `json:"phone"`
+	CardNumber    string    `json:"card_number"`
+	PointsBalance int       `json:"points_balance"`
+	CreatedAt     time.Time `json:"created_at"`
+	Version       uint      `json:"version"`
+}
+
+func newCustomerResponse(customer *models.Customer) CustomerResponse {
+	return CustomerResponse{
+		ID:            customer.ID,
+		Name:          customer.Name,
+		Phone:         customer.Phone,
+		CardNumber:    customer.CardNumber,
+		PointsBalance: customer.PointsBalance,
+		CreatedAt:     customer.CreatedAt,
+		Version:       customer.Version,
+	}
+}
+
+func newCustomerResponses(customers []models.Customer) []CustomerResponse {
+	result := make([]CustomerResponse, 0, len(customers))
+	for i := range customers {
+		result = append(result, newCustomerResponse(&customers[i]))
+	}
+	return result
+}
+
+type LoyaltyBalanceResponse struct {
+	CustomerID uint  `json:"customer_id"`
+	Balance    int   `json:"balance"`
+	Earned     int64 `json:"earned"`
+	Redeemed   int64 `json:"redeemed"`
+}
+
+// PointsAdjustmentRequest — ручная корректировка баллов: положительное
+// значение начисляет, отрицательное списывает.
+type PointsAdjustmentRequest struct {
+	Points  int    `json:"points" binding:"required"`
+	Comment string `json:"comment" binding:"required,max=255"`
+}
+
+type LoyaltyTransactionResponse struct {
+	ID        uint      `json:"id"`
+	SaleID    *uint     `json:"sale_id"`
+	Type      string    `json:"type" enums:"accrual,redemption,adjustment,return"`
+	Points    int       `json:"points"`
+	Balance   int       `json:"balance"`
+	Comment   string    `json:"comment,omitempty"`
+	CreatedBy uint      `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newLoyaltyTransactionResponse(entry *models.LoyaltyTransaction) LoyaltyTransactionResponse {
+	return LoyaltyTransactionResponse{
+		ID:        entry.ID,
+		SaleID:    entry.SaleID,
+		Type:      entry.Type,
+		Points:    entry.Points,
+		Balance:   entry.Balance,
+		Comment:   entry.Comment,
+		CreatedBy: entry.CreatedBy,
+		CreatedAt: entry.CreatedAt,
+	}
+}
+
+func newLoyaltyTransactionResponses(entries []models.LoyaltyTransaction) []LoyaltyTransactionResponse {
+	result := make([]LoyaltyTransactionResponse, 0, len(entries))
+	for i := range entries {
+		result = append(result, newLoyaltyTransactionResponse(&entries[i]))
+	}
+	return result
+}
+
+type CustomerRevenueResponse struct {
+	CustomerID uint    `json:"customer_id"`
+	Name       string  `json:"name"`
+	Phone      string  `json:"phone"`
+	SalesCount int64   `json:"sales_count"`
+	Revenue    float64 `json:"revenue"`
+}
+
+// CustomerAnalyticsResponse — выручка за период в разрезе покупателей.
+// Повторная продажа — продажа покупателю, у которого уже была более ранняя
+// покупка, в том числе до начала периода. Доли считаются от общей выручки.
+type CustomerAnalyticsResponse struct {
+	SalesCount        int64                     `json:"sales_count"`
+	TotalRevenue      float64                   `json:"total_revenue"`
+	IdentifiedSales   int64                     `json:"identified_sales"`
+	IdentifiedRevenue float64                   `json:"identified_revenue"`
+	IdentifiedShare   float64                   `json:"identified_share"`
+	Customers         int64                     `json:"customers"`
+	RepeatCustomers   int64                     `json:"repeat_customers"`
+	RepeatSales       int64                     `json:"repeat_sales"`
+	RepeatRevenue     float64                   `json:"repeat_revenue"`
+	RepeatShare       float64                   `json:"repeat_share"`
+	PointsEarned      int64                     `json:"points_earned"`
+	PointsRedeemed    int64                     `json:"points_redeemed"`
+	TopCustomers      []CustomerRevenueResponse `json:"top_customers"`
+}
+
+func newCustomerAnalyticsResponse(analytics *services.CustomerAnalytics) CustomerAnalyticsResponse {
+	stats := analytics.Stats
+	response := CustomerAnalyticsResponse{
+		SalesCount:        stats.SalesCount,
+		TotalRevenue:      roundMoney(stats.TotalRevenue),
+		IdentifiedSales:   stats.IdentifiedSales,
+		IdentifiedRevenue: roundMoney(stats.IdentifiedRevenue),
+		Customers:         stats.Customers,
+		RepeatCustomers:   stats.RepeatCustomers,
+		RepeatSales:       stats.RepeatSales,
+		RepeatRevenue:     roundMoney(stats.RepeatRevenue),
+		PointsEarned:      stats.PointsEarned,
+		PointsRedeemed:    stats.PointsRedeemed,
+		TopCustomers:      make([]CustomerRevenueResponse, 0, len(analytics.Top)),
+	}
+	if stats.TotalRevenue > 0 {
+		response.IdentifiedShare = math.Round(stats.IdentifiedRevenue/stats.TotalRevenue*1000) / 1000
+		response.RepeatShare = math.Round(stats.RepeatRevenue/stats.TotalRevenue*1000) / 1000
+	}
+	for _, row := range analytics.Top {
+		response.TopCustomers = append(response.TopCustomers, CustomerRevenueResponse{
+			CustomerID: row.CustomerID,
+			Name:       row.Name,
+			Phone:      row.Phone,
+			SalesCount: row.SalesCount,
+			Revenue:    roundMoney(row.Revenue),
+		})
+	}
+	return response
+}
+
+// SalesSummaryResponse — итоги продаж за период. Продажа — один чек:
+// avg_basket — средняя сумма чека, avg_items — среднее количество товара в
+// чеке.
+type SalesSummaryResponse struct {
+	StartDate     string  `json:"start_date" example:"2026-10-01"`
+	EndDate       string  `json:"end_date" example:"2026-10-31"`
+	TotalSales    int64   `json:"total_sales"`
+	TotalQuantity int64   `json:"total_quantity"`
+	TotalRevenue  float64 `json:"total_revenue"`
+	AvgBasket     float64 `json:"avg_basket"`
+	AvgItems      float64 `json:"avg_items"`
+}
+
+// newSalesSummaryResponse возвращает итоги запрошенного периода, а при
+// previous — предыдущего.
+func newSalesSummaryResponse(analytics *services.SalesAnalytics, previous bool) SalesSummaryResponse {
+	summary, from, to := analytics.Summary, analytics.From, analytics.To
+	if previous {
+		summary, from, to = analytics.Previous, analytics.PreviousFrom, analytics.From
+	}
+
+	response := SalesSummaryResponse{
+		StartDate:     from.Format(exportDateLayout),
+		EndDate:       to.AddDate(0, 0, -1).Format(exportDateLayout),
+		TotalSales:    summary.SalesCount,
+		TotalQuantity: summary.Quantity,
+		TotalRevenue:  roundMoney(summary.Revenue),
+	}
+	if summary.SalesCount > 0 {
+		response.AvgBasket = roundMoney(summary.Revenue / float64(summary.SalesCount))
+		response.AvgItems = roundMoney(float64(summary.Quantity) / float64(summary.SalesCount))
+	}
+	return response
+}
+
+// SalesChangeResponse — изменение показателей к предыдущему периоду в
+// процентах; null, если в предыдущем периоде продаж не было.
+type SalesChangeResponse struct {
+	Sales     *float64 `json:"sales_pct"`
+	Revenue   *float64 `json:"revenue_pct"`
+	AvgBasket *float64 `json:"avg_basket_pct"`
+}
+
+// percentChange возвращает изменение current относительно previous в
+// процентах с одним знаком после запятой.
+func percentChange(current, previous float64) *float64 {
+	if previous == 0 {
+		return nil
+	}
+	change := math.Round((current-previous)/previous*1000) / 10
+	return &change
+}
+
+// SalesGroupResponse — продажи в одном разрезе: key — день или начало
+// недели (YYYY-MM-DD), месяц (YYYY-MM), час (HH:00) или ID отдела, кассира
+// либо товара, name — его название.
+type SalesGroupResponse struct {
+	Key          string  `json:"key" example:"2026-10-19"`
+	Name         string  `json:"name,omitempty"`
+	Sales        int64   `json:"sales"`
+	Quantity     int64   `json:"quantity"`
+	Revenue      float64 `json:"revenue"`
+	AvgBasket    float64 `json:"avg_basket"`
+	RevenueShare float64 `json:"revenue_share"`
+}
+
+type ProductSalesResponse struct {
+	ProductID      uint    `json:"product_id"`
+	Name           string  `json:"name"`
+	DepartmentName string  `json:"department_name"`
+	Sales          int64   `json:"sales"`
+	Quantity       int64   `json:"quantity"`
+	Revenue        float64 `json:"revenue"`
+}
+
+// newProductSalesResponses возвращает лучшие товары, а при bottom —
+// худшие.
+func newProductSalesResponses(analytics *services.SalesAnalytics, bottom bool) []ProductSalesResponse {
+	rows := analytics.TopProducts
+	if bottom {
+		rows = analytics.BottomProducts
+	}
+
+	result := make([]ProductSalesResponse, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, ProductSalesResponse{
+			ProductID:      row.ProductID,
+			Name:           row.Name,
+			DepartmentName: row.DepartmentName,
+			Sales:          row.SalesCount,
+			Quantity:       row.Quantity,
+			Revenue:        roundMoney(row.Revenue),
+		})
+	}
+	return result
+}
+
+// SalesAnalyticsResponse — аналитика продаж за период. previous — тот же
+// по длине период непосредственно перед запрошенным; payment_methods —
+// платежи по способам оплаты; bottom_products включает и товары без продаж.
+type SalesAnalyticsResponse struct {
+	SalesSummaryResponse
+	GroupBy        string                           `json:"group_by" enums:"day,week,month,hour,department,cashier,product"`
+	PaymentMethods map[string]*PaymentMethodSummary `json:"payment_methods"`
+	Previous       SalesSummaryResponse             `json:"previous"`
+	Change         SalesChangeResponse              `json:"change"`
+	Groups         []SalesGroupResponse             `json:"groups"`
+	TopProducts    []ProductSalesResponse           `json:"top_products"`
+	BottomProducts []ProductSalesResponse           `json:"bottom_products"`
+}
+
+func newSalesAnalyticsResponse(analytics *services.SalesAnalytics) SalesAnalyticsResponse {
+	response := SalesAnalyticsResponse{
+		SalesSummaryResponse: newSalesSummaryResponse(analytics, false),
+		GroupBy:              analytics.GroupBy,
+		PaymentMethods:       make(map[string]*PaymentMethodSummary, len(models.PaymentMethods)),
+		Previous:             newSalesSummaryResponse(analytics, true),
+		Groups:               make([]SalesGroupResponse, 0, len(analytics.Groups)),
+		TopProducts:          newProductSalesResponses(analytics, false),
+		BottomProducts:       newProductSalesResponses(analytics, true),
+	}
+
+	response.Change = SalesChangeResponse{
+		Sales:     percentChange(float64(response.TotalSales), float64(response.Previous.TotalSales)),
+		Revenue:   percentChange(response.TotalRevenue, response.Previous.TotalRevenue),
+		AvgBasket: percentChange(response.AvgBasket, response.Previous.AvgBasket),
+	}
+
+	for _, method := range models.PaymentMethods {
+		response.PaymentMethods[method] = &PaymentMethodSummary{}
+	}
+	for _, total := range analytics.Payments {
+		response.PaymentMethods[total.Method] = &PaymentMethodSummary{
+			Count:  int(total.Count),
+			Amount: roundMoney(total.Amount),
+		}
+	}
+
+	for _, group := range analytics.Groups {
+		row := SalesGroupResponse{
+			Key:      group.Key,
+			Name:     group.Name,
+			Sales:    group.SalesCount,
+			Quantity: group.Quantity,
+			Revenue:  roundMoney(group.Revenue),
+		}
+		if group.SalesCount > 0 {
+			row.AvgBasket = roundMoney(group.Revenue / float64(group.SalesCount))
+		}
+		if analytics.Summary.Revenue > 0 {
+			row.RevenueShare = roundShare(group.Revenue / analytics.Summary.Revenue)
+		}
+		response.Groups = append(response.Groups, row)
+	}
+	return response
+}
+
+// ABCXYZCellResponse — ячейка матрицы ABC/XYZ: сколько в ней товаров и
+// какую долю выручки они дают.
+type ABCXYZCellResponse struct {
+	Class        string  `json:"class" example:"AX"`
+	Products     int     `json:"products"`
+	Revenue      float64 `json:"revenue"`
+	RevenueShare float64 `json:"revenue_share"`
+}
+
+// ProductClassResponse — классы товара. grade — сорт, записанный в товаре
+// сейчас; variation — коэффициент вариации недельного спроса за
+// demand_weeks недель (null, если товар не продавался).
+type ProductClassResponse struct {
+	ProductID       uint     `json:"product_id"`
+	Name            string   `json:"name"`
+	DepartmentName  string   `json:"department_name"`
+	Grade           string   `json:"grade"`
+	ABC             string   `json:"abc" enums:"A,B,C"`
+	XYZ             string   `json:"xyz" enums:"X,Y,Z"`
+	Class           string   `json:"class" example:"AX"`
+	Revenue         float64  `json:"revenue"`
+	RevenueShare    float64  `json:"revenue_share"`
+	CumulativeShare float64  `json:"cumulative_share"`
+	Quantity        int64    `json:"quantity"`
+	DemandWeeks     int      `json:"demand_weeks"`
+	AvgWeekly       float64  `json:"avg_weekly"`
+	Variation       *float64 `json:"variation"`
+}
+
+// ABCXYZResponse — ABC/XYZ-анализ товаров. A — товары, дающие первые
+// a_share выручки, B — следующие до b_share; X — спрос с коэффициентом
+// вариации не больше x_max_variation, Y — не больше y_max_variation.
+type ABCXYZResponse struct {
+	StartDate     string                 `json:"start_date" example:"2026-07-21"`
+	EndDate       string                 `json:"end_date" example:"2026-10-19"`
+	Weeks         int                    `json:"weeks"`
+	AShare        float64                `json:"a_share"`
+	BShare        float64                `json:"b_share"`
+	XMaxVariation float64                `json:"x_max_variation"`
+	YMaxVariation float64                `json:"y_max_variation"`
+	TotalRevenue  float64                `json:"total_revenue"`
+	Matrix        []ABCXYZCellResponse   `json:"matrix"`
+	Products      []ProductClassResponse `json:"products"`
+}
+
+func newABCXYZResponse(analysis *services.ABCXYZAnalysis) ABCXYZResponse {
+	policy := analysis.Policy
+	response := ABCXYZResponse{
+		StartDate:     analysis.From.Format(exportDateLayout),
+		EndDate:       analysis.To.AddDate(0, 0, -1).Format(exportDateLayout),
+		Weeks:         policy.Weeks,
+		AShare:        policy.AShare,
+		BShare:        policy.BShare,
+		XMaxVariation: policy.XMaxVariation,
+		YMaxVariation: policy.YMaxVariation,
+		TotalRevenue:  analysis.Revenue,
+		Matrix:        make([]ABCXYZCellResponse, 0, len(analysis.Cells)),
+		Products:      make([]ProductClassResponse, 0, len(analysis.Products)),
+	}
+	for _, cell := range analysis.Cells {
+		response.Matrix = append(response.Matrix, ABCXYZCellResponse{
+			Class:        cell.Class,
+			Products:     cell.Products,
+			Revenue:      cell.Revenue,
+			RevenueShare: roundShare(cell.Share),
+		})
+	}
+	for i := range analysis.Products {
+		class := &analysis.Products[i]
+		row := ProductClassResponse{
+			ProductID:       class.Product.ID,
+			Name:            class.Product.Name,
+			DepartmentName:  class.Product.Department.Name,
+			Grade:           class.Product.Grade,
+			ABC:             class.ABC,
+			XYZ:             class.XYZ,
+			Class:           class.Class(),
+			Revenue:         class.Revenue,
+			RevenueShare:    roundShare(class.Share),
+			CumulativeShare: roundShare(class.CumulativeShare),
+			Quantity:        class.Quantity,
+			DemandWeeks:     class.DemandWeeks,
+			AvgWeekly:       roundMoney(class.AvgWeekly),
+		}
+		if class.Variation != nil {
+			variation := roundShare(*class.Variation)
+			row.Variation = &variation
+		}
+		response.Products = append(response.Products, row)
+	}
+	return response
+}
+
+type GradeChangeResponse struct {
+	ProductID uint   `json:"product_id"`
+	Name      string `json:"name"`
+	From      string `json:"from"`
+	To        string `json:"to"`
+}
+
+// ABCXYZApplyResponse — результат записи классов ABC в сорт товаров.
+type ABCXYZApplyResponse struct {
+	Updated int                   `json:"updated"`
+	Changes []GradeChangeResponse `json:"changes"`
+}
+
+func newABCXYZApplyResponse(changes []services.GradeChange) ABCXYZApplyResponse {
+	response := ABCXYZApplyResponse{
+		Updated: len(changes),
+		Changes: make([]GradeChangeResponse, 0, len(changes)),
+	}
+	for _, change := range changes {
+		response.Changes = append(response.Changes, GradeChangeResponse{
+			ProductID: change.Product.ID,
+			Name:      change.Product.Name,
+			From:      change.From,
+			To:        change.To,
+		})
+	}
+	return response
+}
+
+// roundShare округляет долю до трех знаков.
+func roundShare(share float64) float64 {
+	return math.Round(share*1000) / 1000
+}
+
+type ForecastDayResponse struct {
+	Date          string  `json:"date" example:"2026-10-19"`
+	MovingAverage float64 `json:"moving_average"`
+	Seasonal      float64 `json:"seasonal"`
+}
+
+// ForecastAccuracyResponse — ошибки метода на последних days днях
+// истории: mae и rmse в единицах товара в день, wape — доля от продаж,
+// bias — на какую долю прогноз завышен (отрицательный — занижен).
+type ForecastAccuracyResponse struct {
+	Method string   `json:"method" enums:"moving_average,seasonal"`
+	Days   int      `json:"days"`
+	MAE    float64  `json:"mae"`
+	RMSE   float64  `json:"rmse"`
+	WAPE   *float64 `json:"wape"`
+	Bias   *float64 `json:"bias"`
+}
+
+// ForecastTotalResponse — прогноз продаж за весь горизонт.
+type ForecastTotalResponse struct {
+	MovingAverage float64 `json:"moving_average"`
+	Seasonal      float64 `json:"seasonal"`
+}
+
+// ForecastResponse — прогноз дневного спроса на товар. history_from и
+// history_to не указываются, если товар еще не продавался; total — сумма
+// прогноза за горизонт по каждому методу, recommended — метод с меньшей
+// ошибкой на истории; alpha и gamma — подобранные параметры сезонной
+// модели, если истории для нее хватило.
+type ForecastResponse struct {
+	ProductID   uint                       `json:"product_id"`
+	Name        string                     `json:"name"`
+	HistoryFrom string                     `json:"history_from,omitempty" example:"2026-07-27"`
+	HistoryTo   string                     `json:"history_to,omitempty" example:"2026-10-18"`
+	HistoryDays int                        `json:"history_days"`
+	AvgDaily    float64                    `json:"avg_daily"`
+	Recommended string                     `json:"recommended" enums:"moving_average,seasonal"`
+	Alpha       float64                    `json:"alpha,omitempty"`
+	Gamma       float64                    `json:"gamma,omitempty"`
+	Total       ForecastTotalResponse      `json:"total"`
+	Days        []ForecastDayResponse      `json:"days"`
+	Backtest    []ForecastAccuracyResponse `json:"backtest"`
+}
+
+func newForecastResponse(forecast *services.DemandForecast) ForecastResponse {
+	response := ForecastResponse{
+		ProductID:   forecast.Product.ID,
+		Name:        forecast.Product.Name,
+		HistoryDays: forecast.HistoryDays,
+		AvgDaily:    roundMoney(forecast.AvgDaily),
+		Recommended: forecast.Recommended,
+		Alpha:       forecast.Alpha,
+		Gamma:       forecast.Gamma,
+		Days:        make([]ForecastDayResponse, 0, len(forecast.Days)),
+		Backtest:    make([]ForecastAccuracyResponse, 0, len(forecast.Backtest)),
+	}
+	if forecast.HistoryDays > 0 {
+		response.HistoryFrom = forecast.HistoryFrom.Format(exportDateLayout)
+		response.HistoryTo = forecast.HistoryTo.AddDate(0, 0, -1).Format(exportDateLayout)
+	}
+	for _, day := range forecast.Days {
+		response.Days = append(response.Days, ForecastDayResponse{
+			Date:          day.Date.Format(exportDateLayout),
+			MovingAverage: roundMoney(day.MovingAverage),
+			Seasonal:      roundMoney(day.Seasonal),
+		})
+		response.Total.MovingAverage += day.MovingAverage
+		response.Total.Seasonal += day.Seasonal
+	}
+	response.Total.MovingAverage = roundMoney(response.Total.MovingAverage)
+	response.Total.Seasonal = roundMoney(response.Total.Seasonal)
+
+	for _, result := range forecast.Backtest {
+		row := ForecastAccuracyResponse{
+			Method: result.Method,
+			Days:   result.Days,
+			MAE:    roundMoney(result.MAE),
+			RMSE:   roundMoney(result.RMSE),
+		}
+		if result.WAPE != nil {
+			wape, bias := roundShare(*result.WAPE), roundShare(*result.Bias)
+			row.WAPE, row.Bias = &wape, &bias
+		}
+		response.Backtest = append(response.Backtest, row)
+	}
+	return response
+}
+
+// StockValueResponse — стоимость остатка: cost_value — по себестоимости
+// поставок, retail_value — в текущих розничных ценах, margin — их разница.
+// uncosted_quantity — количество без единой поставки, не вошедшее в
+// cost_value.
+type StockValueResponse struct {
+	Products         int     `json:"products"`
+	Quantity         int64   `json:"quantity"`
+	UncostedQuantity int64   `json:"uncosted_quantity"`
+	CostValue        float64 `json:"cost_value"`
+	RetailValue      float64 `json:"retail_value"`
+	Margin           float64 `json:"margin"`
+}
+
+func newStockValueResponse(value *services.StockValue) StockValueResponse {
+	return StockValueResponse{
+		Products:         value.Products,
+		Quantity:         value.Quantity,
+		UncostedQuantity: value.Uncosted,
+		CostValue:        value.CostValue,
+		RetailValue:      value.RetailValue,
+		Margin:           value.Margin(),
+	}
+}
+
+// ValuationGroupResponse — стоимость остатков отдела или поставщика.
+type ValuationGroupResponse struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+	StockValueResponse
+}
+
+// StockValuationResponse — оценка остатков на конец дня date.
+type StockValuationResponse struct {
+	Date    string                   `json:"date" example:"2026-10-19"`
+	GroupBy string                   `json:"group_by" enums:"department,supplier"`
+	Groups  []ValuationGroupResponse `json:"groups"`
+	Total   StockValueResponse       `json:"total"`
+}
+
+func newStockValuationResponse(valuation *services.StockValuation) StockValuationResponse {
+	response := StockValuationResponse{
+		Date:    valuation.Date.Format(exportDateLayout),
+		GroupBy: valuation.GroupBy,
+		Groups:  make([]ValuationGroupResponse, 0, len(valuation.Groups)),
+		Total:   newStockValueResponse(&valuation.Total),
+	}
+	for i := range valuation.Groups {
+		group := &valuation.Groups[i]
+		response.Groups = append(response.Groups, ValuationGroupResponse{
+			ID:                 group.ID,
+			Name:               group.Name,
+			StockValueResponse: newStockValueResponse(&group.StockValue),
+		})
+	}
+	return response
+}
+
+// TurnoverFiguresResponse — оборачиваемость запаса в единицах товара:
+// turnover — продажи к среднему остатку, days_on_hand — на сколько дней
+// продаж хватает среднего остатка; null, если продаж не было или средний
+// остаток нулевой.
+type TurnoverFiguresResponse struct {
+	OpeningStock int64    `json:"opening_stock"`
+	ClosingStock int64    `json:"closing_stock"`
+	AverageStock float64  `json:"average_stock"`
+	Sold         int64    `json:"sold"`
+	Turnover     *float64 `json:"turnover"`
+	DaysOnHand   *float64 `json:"days_on_hand"`
+}
+
+func newTurnoverFiguresResponse(figures *services.TurnoverFigures) TurnoverFiguresResponse {
+	response := TurnoverFiguresResponse{
+		OpeningStock: figures.Opening,
+		ClosingStock: figures.Closing,
+		AverageStock: figures.Average,
+		Sold:         figures.Sold,
+	}
+	if figures.Turnover != nil {
+		turnover, onHand := roundMoney(*figures.Turnover), roundMoney(*figures.DaysOnHand)
+		response.Turnover, response.DaysOnHand = &turnover, &onHand
+	}
+	return response
+}
+
+type ProductTurnoverResponse struct {
+	ProductID      uint   `json:"product_id"`
+	Name           string `json:"name"`
+	DepartmentName string `json:"department_name"`
+	TurnoverFiguresResponse
+}
+
+// InventoryTurnoverResponse — оборачиваемость запасов за период, товары
+// от самых медленных.
+type InventoryTurnoverResponse struct {
+	StartDate string                    `json:"start_date" example:"2026-10-01"`
+	EndDate   string                    `json:"end_date" example:"2026-10-19"`
+	Days      int                       `json:"days"`
+	Products  []ProductTurnoverResponse `json:"products"`
+	Total     TurnoverFiguresResponse   `json:"total"`
+}
+
+func newInventoryTurnoverResponse(turnover *services.InventoryTurnover) InventoryTurnoverResponse {
+	response := InventoryTurnoverResponse{
+		StartDate: turnover.From.Format(exportDateLayout),
+		EndDate:   turnover.To.AddDate(0, 0, -1).Format(exportDateLayout),
+		Days:      turnover.Days,
+		Products:  make([]ProductTurnoverResponse, 0, len(turnover.Products)),
+		Total:     newTurnoverFiguresResponse(&turnover.Total),
+	}
+	for i := range turnover.Products {
+		row := &turnover.Products[i]
+		response.Products = append(response.Products, ProductTurnoverResponse{
+			ProductID:               row.Product.ID,
+			Name:                    row.Product.Name,
+			DepartmentName:          row.Product.Department.Name,
+			TurnoverFiguresResponse: newTurnoverFiguresResponse(&row.TurnoverFigures),
+		})
+	}
+	return response
+}
+
+// DeadStockItemResponse — остаток товара без продаж. last_sale и
+// days_without_sales равны null, если товар не продавался никогда.
+type DeadStockItemResponse struct {
+	ProductID        uint       `json:"product_id"`
+	Name             string     `json:"name"`
+	DepartmentName   string     `json:"department_name"`
+	SupplierName     string     `json:"supplier_name"`
+	Quantity         int64      `json:"quantity"`
+	LastSale         *time.Time `json:"last_sale"`
+	DaysWithoutSales *int       `json:"days_without_sales"`
+	CostValue        float64    `json:"cost_value"`
+	RetailValue      float64    `json:"retail_value"`
+}
+
+// DeadStockResponse — товары с остатком, не продававшиеся с since.
+type DeadStockResponse struct {
+	Days  int                     `json:"days"`
+	Since string                  `json:"since" example:"2026-09-19"`
+	Items []DeadStockItemResponse `json:"items"`
+	Total StockValueResponse      `json:"total"`
+}
+
+func newDeadStockResponse(report *services.DeadStock) DeadStockResponse {
+	response := DeadStockResponse{
+		Days:  report.Days,
+		Since: report.Since.Format(exportDateLayout),
+		Items: make([]DeadStockItemResponse, 0, len(report.Items)),
+		Total: newStockValueResponse(&report.Total),
+	}
+	for i := range report.Items {
+		item := &report.Items[i]
+		response.Items = append(response.Items, DeadStockItemResponse{
+			ProductID:        item.Product.ID,
+			Name:             item.Product.Name,
+			DepartmentName:   item.Product.Department.Name,
+			SupplierName:     item.Product.Supplier.Name,
+			Quantity:         item.Quantity,
+			LastSale:         item.LastSale,
+			DaysWithoutSales: item.DaysWithoutSales,
+			CostValue:        item.CostValue,
+			RetailValue:      item.RetailValue,
+		})
+	}
+	return response
+}
+
+// ShrinkageTotalsResponse — списания в единицах товара и по
+// себестоимости.
+type ShrinkageTotalsResponse struct {
+	WriteOffs int     `json:"write_offs"`
+	Quantity  int64   `json:"quantity"`
+	Cost      float64 `json:"cost"`
+}
+
+func newShrinkageTotalsResponse(totals *services.ShrinkageTotals) ShrinkageTotalsResponse {
+	return ShrinkageTotalsResponse{WriteOffs: totals.WriteOffs, Quantity: totals.Quantity, Cost: totals.Cost}
+}
+
+type ReasonShrinkageResponse struct {
+	Reason string `json:"reason" enums:"breakage,spoilage,theft,sample,other"`
+	ShrinkageTotalsResponse
+}
+
+func newReasonShrinkageResponses(reasons []services.ReasonShrinkage) []ReasonShrinkageResponse {
+	result := make([]ReasonShrinkageResponse, 0, len(reasons))
+	for i := range reasons {
+		result = append(result, ReasonShrinkageResponse{
+			Reason:                  reasons[i].Reason,
+			ShrinkageTotalsResponse: newShrinkageTotalsResponse(&reasons[i].ShrinkageTotals),
+		})
+	}
+	return result
+}
+
+type DepartmentShrinkageResponse struct {
+	DepartmentID   uint   `json:"department_id"`
+	DepartmentName string `json:"department_name"`
+	ShrinkageTotalsResponse
+	Reasons []ReasonShrinkageResponse `json:"reasons"`
+}
+
+// ShrinkageResponse — утвержденные списания за период по отделам и
+// причинам. reasons перечисляет все причины, в том числе без списаний.
+type ShrinkageResponse struct {
+	StartDate   string                        `json:"start_date" example:"2026-10-01"`
+	EndDate     string                        `json:"end_date" example:"2026-10-19"`
+	Departments []DepartmentShrinkageResponse `json:"departments"`
+	Reasons     []ReasonShrinkageResponse     `json:"reasons"`
+	Total       ShrinkageTotalsResponse       `json:"total"`
+}
+
+func newShrinkageResponse(report *services.ShrinkageReport) ShrinkageResponse {
+	response := ShrinkageResponse{
+		StartDate:   report.From.Format(exportDateLayout),
+		EndDate:     report.To.AddDate(0, 0, -1).Format(exportDateLayout),
+		Departments: make([]DepartmentShrinkageResponse, 0, len(report.Departments)),
+		Reasons:     newReasonShrinkageResponses(report.Reasons),
+		Total:       newShrinkageTotalsResponse(&report.Total),
+	}
+	for i := range report.Departments {
+		department := &report.Departments[i]
+		response.Departments = append(response.Departments, DepartmentShrinkageResponse{
+			DepartmentID:            department.Department.ID,
+			DepartmentName:          department.Department.Name,
+			ShrinkageTotalsResponse: newShrinkageTotalsResponse(&department.ShrinkageTotals),
+			Reasons:                 newReasonShrinkageResponses(department.Reasons),
+		})
+	}
+	return response
+}
+
+// SupplierScorecardResponse — показатели поставщика за период.
+// near_expiry_share — доля количества товара, привезенного меньше чем за
+// near_expiry_days дней до конца срока годности, среди позиций с указанным
+// сроком.
+type SupplierScorecardResponse struct {
+	Supplier  SupplierResponse `json:"supplier"`
+	StartDate string           `json:"start_date"`
+	EndDate   string           `json:"end_date"`
+
+	Deliveries      int        `json:"deliveries"`
+	FirstDelivery   *time.Time `json:"first_delivery"`
+	LastDelivery    *time.Time `json:"last_delivery"`
+	AvgIntervalDays *float64   `json:"avg_interval_days"`
+	Spend           float64    `json:"spend"`
+
+	Months   []SupplierSpendMonthResponse `json:"months"`
+	Products []SupplierPriceDriftResponse `json:"products"`
+
+	NearExpiryDays     int     `json:"near_expiry_days"`
+	LinesWithExpiry    int     `json:"lines_with_expiry"`
+	NearExpiryLines    int     `json:"near_expiry_lines"`
+	NearExpiryQuantity int     `json:"near_expiry_quantity"`
+	NearExpiryShare    float64 `json:"near_expiry_share"`
+}
+
+type SupplierSpendMonthResponse struct {
+	Month      string  `json:"month" example:"2026-10"`
+	Deliveries int     `json:"deliveries"`
+	Quantity   int     `json:"quantity"`
+	Spend      float64 `json:"spend"`
+}
+
+// SupplierPriceDriftResponse — изменение цены товара у поставщика.
+// change_percent — от первой поставки периода к последней; list_price —
+// цена единицы по действующему прайс-листу.
+type SupplierPriceDriftResponse struct {
+	Product       ProductSummary `json:"product"`
+	Deliveries    int            `json:"deliveries"`
+	Quantity      int            `json:"quantity"`
+	FirstPrice    float64        `json:"first_price"`
+	LastPrice     float64        `json:"last_price"`
+	MinPrice      float64        `json:"min_price"`
+	MaxPrice      float64        `json:"max_price"`
+	AvgPrice      float64        `json:"avg_price"`
+	ChangePercent float64        `json:"change_percent"`
+	ListPrice     *float64       `json:"list_price"`
+}
+
+func newSupplierScorecardResponse(card *services.SupplierScorecard) SupplierScorecardResponse {
+	response := SupplierScorecardResponse{
+		Supplier:           newSupplierResponse(&card.Supplier),
+		StartDate:          card.From.Format(exportDateLayout),
+		EndDate:            card.To.AddDate(0, 0, -1).Format(exportDateLayout),
+		Deliveries:         card.Deliveries,
+		FirstDelivery:      card.FirstDelivery,
+		LastDelivery:       card.LastDelivery,
+		Spend:              card.Spend,
+		Months:             make([]SupplierSpendMonthResponse, 0, len(card.Months)),
+		Products:           make([]SupplierPriceDriftResponse, 0, len(card.Products)),
+		NearExpiryDays:     card.NearExpiryDays,
+		LinesWithExpiry:    card.LinesWithExpiry,
+		NearExpiryLines:    card.NearExpiryLines,
+		NearExpiryQuantity: card.NearExpiryQuantity,
+	}
+	if card.AvgIntervalDays != nil {
+		interval := math.Round(*card.AvgIntervalDays*10) / 10
+		response.AvgIntervalDays = &interval
+	}
+	if card.QuantityWithExpiry > 0 {
+		response.NearExpiryShare = math.Round(float64(card.NearExpiryQuantity)/float64(card.QuantityWithExpiry)*1000) / 1000
+	}
+
+	for _, month := range card.Months {
+		response.Months = append(response.Months, SupplierSpendMonthResponse{
+			Month:      month.Month.Format("2006-01"),
+			Deliveries: month.Deliveries,
+			Quantity:   month.Quantity,
+			Spend:      month.Spend,
+		})
+	}
+	for i := range card.Products {
+		drift := &card.Products[i]
+		response.Products = append(response.Products, SupplierPriceDriftResponse{
+			Product:       *newProductSummary(&drift.Product),
+			Deliveries:    drift.Deliveries,
+			Quantity:      drift.Quantity,
+			FirstPrice:    drift.FirstPrice,
+			LastPrice:     drift.LastPrice,
+			MinPrice:      drift.MinPrice,
+			MaxPrice:      drift.MaxPrice,
+			AvgPrice:      drift.AvgPrice,
+			ChangePercent: math.Round(drift.Change()*10) / 10,
+			ListPrice:     drift.ListPrice,
+		})
+	}
+	return response
+}
+
+// roundMoney округляет сумму до копеек.
+func roundMoney(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+type AuditLogResponse struct {
+	ID         uint            `json:"id"`
+	ActorID    uint            `json:"actor_id"`
+	ActorName  string          `json:"actor_name"`
+	Action     string          `json:"action"`
+	EntityType string          `json:"entity_type"`
+	EntityID   uint            `json:"entity_id"`
+	Before     json.RawMessage `json:"before" swaggertype:"object"`
+	After      json.RawMessage `json:"after" swaggertype:"object"`
+	Diff       json.RawMessage `json:"diff" swaggertype:"object"`
+	IP         string          `json:"ip"`
+	RequestID  string          `json:"request_id"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// rawJSON возвращает сохраненный JSON как есть, а пустую строку — как null.
+func rawJSON(value string) json.RawMessage {
+	if value == "" {
+		return json.RawMessage("null")
+	}
+	return json.RawMessage(value)
+}
+
+func newAuditLogResponses(entries []models.AuditLog) []AuditLogResponse {
+	result := make([]AuditLogResponse, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, AuditLogResponse{
+			ID:         entry.ID,
+			ActorID:    entry.ActorID,
+			ActorName:  entry.ActorName,
+			Action:     entry.Action,
+			EntityType: entry.EntityType,
+			EntityID:   entry.EntityID,
+			Before:     rawJSON(entry.Before),
+			After:      rawJSON(entry.After),
+			Diff:       rawJSON(entry.Diff),
+			IP:         entry.IP,
+			RequestID:  entry.RequestID,
+			CreatedAt:  entry.CreatedAt,
+		})
+	}
+	return result
+}
@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"grocery-store-api/services/errs"
+)
+
+// ErrorResponse — тело ответа при любой ошибке API.
+type ErrorResponse struct {
+	Error  string            `json:"error" example:"товар не найден"`
+	Code   string            `json:"code" example:"product_not_found"`
+	Fields []errs.FieldError `json:"fields,omitempty"`
+}
+
+var errInvalidID = errs.NewValidation("invalid_id", "некорректный ID")
+
+// invalidQuery сообщает о некорректном или отсутствующем параметре запроса.
+func invalidQuery(message string) error {
+	return errs.NewValidation("invalid_query", message)
+}
+
+var kindStatus = map[errs.Kind]int{
+	errs.NotFound:           http.StatusNotFound,
+	errs.Conflict:           http.StatusConflict,
+	errs.Validation:         http.StatusBadRequest,
+	errs.Forbidden:          http.StatusForbidden,
+	errs.Unauthorized:       http.StatusUnauthorized,
+	errs.TooManyRequests:    http.StatusTooManyRequests,
+	errs.PreconditionFailed: http.StatusPreconditionFailed,
+	errs.PaymentRequired:    http.StatusPaymentRequired,
+	errs.Unavailable:        http.StatusServiceUnavailable,
+}
+
+// ErrorHandler превращает последнюю ошибку, добавленную через c.Error,
+// в JSON-ответ вида {"error": ..., "code": ...}. Обработчики и middleware
+// только добавляют ошибку и прерывают обработку, статус выбирается здесь
+// по виду ошибки.
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 {
+			return
+		}
+
+		// Ответ уже начал передаваться (например, выгрузка файла), поэтому
+		// ошибку можно только записать в журнал
+		if c.Writer.Written() {
+			log.Printf("request %s: ответ прерван: %v", c.GetString("requestID"), c.Errors.Last().Err)
+			return
+		}
+
+		status, body := errorBody(c.Errors.Last().Err)
+		if status == http.StatusInternalServerError {
+			log.Printf("request %s: %v", c.GetString("requestID"), c.Errors.Last().Err)
+		}
+		c.JSON(status, body)
+	}
+}
+
+func errorBody(err error) (int, ErrorResponse) {
+	if domainErr, ok := errs.As(err); ok {
+		status, known := kindStatus[domainErr.Kind]
+		if !known {
+			status = http.StatusInternalServerError
+		}
+		// Сообщение берется у всей цепочки: обертка может уточнять причину
+		return status, ErrorResponse{Error: err.Error(), Code: domainErr.Code, Fields: domainErr.Fields}
+	}
+
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound, ErrorResponse{Error: "запись не найдена", Code: "not_found"}
+	case errors.Is(err, gorm.ErrForeignKeyViolated):
+		return http.StatusConflict, ErrorResponse{Error: "нарушена ссылочная целостность данных", Code: "foreign_key_violation"}
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return http.StatusConflict, ErrorResponse{Error: "запись с такими данными уже существует", Code: "duplicate"}
+	default:
+		// Текст внутренних ошибок остается в логе и не уходит клиенту
+		return http.StatusInternalServerError, ErrorResponse{Error: "внутренняя ошибка сервера", Code: "internal_error"}
+	}
+}
@@ -0,0 +1,173 @@
+package controllers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"grocery-store-api/models"
+	"grocery-store-api/services"
+	"grocery-store-api/services/errs"
+	"grocery-store-api/spreadsheet"
+)
+
+type ExportHandler struct {
+	Service services.ExportService
+}
+
+const exportDateLayout = "2006-01-02"
+
+var (
+	saleExportHeader     = []string{"ID", "Дата", "Товар", "Отдел", "Количество", "Сумма", "Кассир"}
+	supplyExportHeader   = []string{"Поставка", "Дата", "Поставщик", "Принял", "Товар", "Количество", "Цена", "Сумма"}
+	stockExportHeader    = []string{"ID", "Название", "Отдел", "Поставщик", "Сорт", "Цена", "Остаток", "Минимальный остаток", "Стоимость", "Срок годности", "Условия хранения"}
+	lowStockExportHeader = []string{"ID", "Название", "Отдел", "Поставщик", "Остаток", "Минимальный остаток", "Не хватает"}
+	errPeriodMissing     = invalidQuery("требуются параметры start_date и end_date в формате YYYY-MM-DD")
+)
+
+// ExportSales выгружает продажи за период по товарам доступных отделов.
+func (h *ExportHandler) ExportSales(c *gin.Context) {
+	from, to, err := queryPeriod(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	name := fmt.Sprintf("sales_%s_%s", c.Query("start_date"), c.Query("end_date"))
+	streamExport(c, name, saleExportHeader, func(w spreadsheet.Writer) error {
+		return h.Service.EachSale(currentActor(c), from, to, func(sales []models.Sale) error {
+			for _, sale := range sales {
+				err := w.WriteRow(sale.ID, sale.SaleDate, sale.Product.Name, sale.Product.Department.Name,
+					sale.Quantity, sale.TotalPrice, sale.Cashier.Username)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+}
+
+// ExportSupplies выгружает поставки за период, по строке на каждую позицию.
+func (h *ExportHandler) ExportSupplies(c *gin.Context) {
+	from, to, err := queryPeriod(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	name := fmt.Sprintf("supplies_%s_%s", c.Query("start_date"), c.Query("end_date"))
+	streamExport(c, name, supplyExportHeader, func(w spreadsheet.Writer) error {
+		return h.Service.EachSupplyItem(from, to, func(items []models.SupplyItem) error {
+			for _, item := range items {
+				err := w.WriteRow(item.SupplyID, item.Supply.SupplyDate, item.Supply.Supplier.Name, item.Supply.Approver.Username,
+					item.Product.Name, item.Quantity, item.UnitPrice, float64(item.Quantity)*item.UnitPrice)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+}
+
+// ExportStock выгружает текущие остатки и их стоимость по цене продажи.
+func (h *ExportHandler) ExportStock(c *gin.Context) {
+	name := "stock_" + time.Now().Format(exportDateLayout)
+	streamExport(c, name, stockExportHeader, func(w spreadsheet.Writer) error {
+		return h.Service.EachProduct(currentActor(c), func(products []models.Product) error {
+			for _, product := range products {
+				err := w.WriteRow(product.ID, product.Name, product.Department.Name, product.Supplier.Name, product.Grade,
+					product.Price, product.CurrentQty, product.MinThreshold, float64(product.CurrentQty)*product.Price,
+					product.ExpiryDate, product.StorageCond)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+}
+
+// ExportLowStock выгружает товары, остаток которых не выше минимального.
+func (h *ExportHandler) ExportLowStock(c *gin.Context) {
+	name := "low-stock_" + time.Now().Format(exportDateLayout)
+	streamExport(c, name, lowStockExportHeader, func(w spreadsheet.Writer) error {
+		return h.Service.EachLowStockProduct(currentActor(c), func(products []models.Product) error {
+			for _, product := range products {
+				err := w.WriteRow(product.ID, product.Name, product.Department.Name, product.Supplier.Name,
+					product.CurrentQty, product.MinThreshold, product.MinThreshold-product.CurrentQty)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+}
+
+// queryPeriod разбирает start_date и end_date. Конец периода включается
+// в период целиком, поэтому возвращается начало следующего дня.
+func queryPeriod(c *gin.Context) (from, to time.Time, err error) {
+	from, err = time.ParseInLocation(exportDateLayout, c.Query("start_date"), time.Local)
+	if err != nil {
+		return from, to, errPeriodMissing
+	}
+	end, err := time.ParseInLocation(exportDateLayout, c.Query("end_date"), time.Local)
+	if err != nil {
+		return from, to, errPeriodMissing
+	}
+	if end.Before(from) {
+		return from, to, invalidQuery("end_date не может быть раньше start_date")
+	}
+	return from, end.AddDate(0, 0, 1), nil
+}
+
+// queryDate разбирает необязательную дату из параметра name в формате
+// YYYY-MM-DD. Без параметра возвращается начало текущего дня.
+func queryDate(c *gin.Context, name string) (time.Time, error) {
+	value := c.Query(name)
+	if value == "" {
+		now := time.Now()
+		return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local), nil
+	}
+
+	day, err := time.ParseInLocation(exportDateLayout, value, time.Local)
+	if err != nil {
+		return day, invalidQuery(fmt.Sprintf("параметр %s должен быть датой в формате YYYY-MM-DD", name))
+	}
+	return day, nil
+}
+
+// streamExport отдает таблицу файлом в формате из параметра format (csv по
+// умолчанию). Строки пишутся в ответ по мере чтения, поэтому ошибка после
+// начала передачи только обрывает файл и попадает в журнал.
+func streamExport(c *gin.Context, name string, header []string, write func(spreadsheet.Writer) error) {
+	format, err := spreadsheet.ParseFormat(c.DefaultQuery("format", string(spreadsheet.CSV)), "")
+	if err != nil {
+		c.Error(errs.NewValidation("unsupported_format", err.Error()))
+		return
+	}
+
+	c.Header("Content-Type", spreadsheet.ContentType(format))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, name, format))
+
+	w, err := spreadsheet.NewWriter(c.Writer, format, header)
+	if err == nil {
+		if err = write(w); err == nil {
+			err = w.Close()
+		} else {
+			w.Abort()
+		}
+	}
+	if err != nil {
+		// Пока ничего не отправлено, ответ станет обычной JSON-ошибкой, а
+		// c.JSON не заменяет уже выставленный Content-Type
+		if !c.Writer.Written() {
+			c.Writer.Header().Del("Content-Type")
+			c.Writer.Header().Del("Content-Disposition")
+		}
+		c.Error(err)
+	}
+}
@@ -16,41 +16,207 @@ type UserHandler struct {
 }
 
 func (h *UserHandler) Register(c *gin.Context) {
-	var req models.RegisterRequest
+	var req RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(bindError(err))
 		return
 	}
 
-	user, err := h.Service.Register(req)
+	user, err := h.Service.Register(currentActor(c), req.Username, req.Password, req.Role)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"message": "пользователь зарегистрирован", "user": user})
+	c.JSON(http.StatusCreated, gin.H{"message": "пользователь зарегистрирован", "user": newUserResponse(user)})
 }
 
 func (h *UserHandler) Login(c *gin.Context) {
-	var req models.LoginRequest
+	var req LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(bindError(err))
 		return
 	}
 
-	user, err := h.Service.Login(req)
+	user, err := h.Service.Login(req.Username, req.Password, c.ClientIP())
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
 	token, err := middlewares.GenerateToken(user)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "ошибка создания токена"})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"token": token, "user": user})
+	c.JSON(http.StatusOK, gin.H{"token": token, "user": newUserResponse(user)})
+}
+
+func (h *UserHandler) GetAll(c *gin.Context) {
+	users, err := h.Service.GetAllUsers()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newUserResponses(users))
+}
+
+func (h *UserHandler) GetByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID)
+		return
+	}
+
+	user, err := h.Service.GetUserByID(uint(id))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newUserResponse(user))
+}
+
+func (h *UserHandler) ChangeRole(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID)
+		return
+	}
+
+	var req UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindError(err))
+		return
+	}
+
+	user, err := h.Service.ChangeRole(currentActor(c), uint(id), req.Role)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newUserResponse(user))
+}
+
+func (h *UserHandler) SetDepartment(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID)
+		return
+	}
+
+	var req UserDepartmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindError(err))
+		return
+	}
+
+	user, err := h.Service.SetDepartment(currentActor(c), uint(id), req.DepartmentID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newUserResponse(user))
+}
+
+func (h *UserHandler) ResetPassword(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID)
+		return
+	}
+
+	var req ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindError(err))
+		return
+	}
+
+	if _, err := h.Service.ResetPassword(currentActor(c), uint(id), req.Password); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "пароль сброшен"})
+}
+
+func (h *UserHandler) Disable(c *gin.Context) {
+	h.setDisabled(c, true)
+}
+
+func (h *UserHandler) Enable(c *gin.Context) {
+	h.setDisabled(c, false)
+}
+
+func (h *UserHandler) setDisabled(c *gin.Context, disabled bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID)
+		return
+	}
+
+	user, err := h.Service.SetDisabled(currentActor(c), uint(id), disabled)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newUserResponse(user))
+}
+
+// currentActor возвращает пользователя, установленного JWTAuth, вместе
+// с IP и ID запроса для журнала изменений.
+func currentActor(c *gin.Context) services.Actor {
+	userID, _ := c.Get("userID")
+	username, _ := c.Get("username")
+	role, _ := c.Get("role")
+	departmentID, _ := c.Get("departmentID")
+	return services.Actor{
+		UserID:       userID.(uint),
+		Username:     username.(string),
+		Role:         role.(string),
+		DepartmentID: departmentID.(*uint),
+		IP:           c.ClientIP(),
+		RequestID:    c.GetString("requestID"),
+	}
+}
+
+type RoleHandler struct {
+	Service services.PermissionService
+}
+
+func (h *RoleHandler) GetAll(c *gin.Context) {
+	roles, err := h.Service.GetRolePermissions()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, roles)
+}
+
+func (h *RoleHandler) GetPermissions(c *gin.Context) {
+	c.JSON(http.StatusOK, models.Permissions)
+}
+
+func (h *RoleHandler) UpdatePermissions(c *gin.Context) {
+	var req RolePermissionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindError(err))
+		return
+	}
+
+	role, err := h.Service.SetRolePermissions(currentActor(c), c.Param("role"), req.Permissions)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, role)
 }
 
 type ProductHandler struct {
@@ -58,34 +224,37 @@ type ProductHandler struct {
 }
 
 func (h *ProductHandler) Create(c *gin.Context) {
-	var product models.Product
-	if err := c.ShouldBindJSON(&product); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var req ProductRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindError(err))
 		return
 	}
 
-	if err := h.Service.CreateProduct(&product); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	product := req.toModel()
+	if err := h.Service.CreateProduct(currentActor(c), &product); err != nil {
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, product)
+	setETag(c, product.Version)
+	c.JSON(http.StatusCreated, newProductResponse(&product))
 }
 
 func (h *ProductHandler) GetByID(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный ID"})
+		c.Error(errInvalidID)
 		return
 	}
 
 	product, err := h.Service.GetProductByID(uint(id))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "товар не найден"})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, product)
+	setETag(c, product.Version)
+	c.JSON(http.StatusOK, newProductResponse(product))
 }
 
 func (h *ProductHandler) GetAll(c *gin.Context) {
@@ -98,14 +267,14 @@ func (h *ProductHandler) GetAll(c *gin.Context) {
 	if departmentID != "" {
 		id, err := strconv.ParseUint(departmentID, 10, 32)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный ID отдела"})
+			c.Error(invalidQuery("некорректный ID отдела"))
 			return
 		}
 		products, err = h.Service.GetProductsByDepartment(uint(id))
 	} else if supplierID != "" {
 		id, err := strconv.ParseUint(supplierID, 10, 32)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный ID поставщика"})
+			c.Error(invalidQuery("некорректный ID поставщика"))
 			return
 		}
 		products, err = h.Service.GetProductsBySupplier(uint(id))
@@ -114,48 +283,97 @@ func (h *ProductHandler) GetAll(c *gin.Context) {
 	}
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, products)
+	c.JSON(http.StatusOK, newProductResponses(products))
 }
 
 func (h *ProductHandler) Update(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный ID"})
+		c.Error(errInvalidID)
 		return
 	}
 
-	var product models.Product
-	if err := c.ShouldBindJSON(&product); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
-	product.ID = uint(id)
-	if err := h.Service.UpdateProduct(&product); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	var req ProductRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindError(err))
 		return
 	}
 
-	c.JSON(http.StatusOK, product)
+	h.save(c, uint(id), req, version)
+}
+
+func (h *ProductHandler) Patch(c *gin.Context) {
+	patchRecord(c, func(id uint) (ProductRequest, uint, error) {
+		current, err := h.Service.GetProductByID(id)
+		if err != nil {
+			return ProductRequest{}, 0, err
+		}
+		return newProductRequest(current), current.Version, nil
+	}, h.save)
+}
+
+func (h *ProductHandler) save(c *gin.Context, id uint, req ProductRequest, version uint) {
+	product := req.toModel()
+	product.ID = id
+	if err := h.Service.UpdateProduct(currentActor(c), &product, version); err != nil {
+		c.Error(err)
+		return
+	}
+
+	setETag(c, product.Version)
+	c.JSON(http.StatusOK, newProductResponse(&product))
 }
 
 func (h *ProductHandler) Delete(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный ID"})
+		c.Error(errInvalidID)
+		return
+	}
+
+	if err := h.Service.DeleteProduct(currentActor(c), uint(id)); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "товар перемещен в архив"})
+}
+
+func (h *ProductHandler) GetArchived(c *gin.Context) {
+	items, err := h.Service.GetArchivedProducts()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newProductResponses(items))
+}
+
+func (h *ProductHandler) Restore(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID)
 		return
 	}
 
-	if err := h.Service.DeleteProduct(uint(id)); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	restored, err := h.Service.RestoreProduct(currentActor(c), uint(id))
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "товар удален"})
+	setETag(c, restored.Version)
+	c.JSON(http.StatusOK, newProductResponse(restored))
 }
 
 type DepartmentHandler struct {
@@ -163,81 +381,133 @@ type DepartmentHandler struct {
 }
 
 func (h *DepartmentHandler) Create(c *gin.Context) {
-	var department models.Department
-	if err := c.ShouldBindJSON(&department); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var req DepartmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindError(err))
 		return
 	}
 
-	if err := h.Service.CreateDepartment(&department); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	department := req.toModel()
+	if err := h.Service.CreateDepartment(currentActor(c), &department); err != nil {
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, department)
+	setETag(c, department.Version)
+	c.JSON(http.StatusCreated, newDepartmentResponse(&department))
 }
 
 func (h *DepartmentHandler) GetByID(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный ID"})
+		c.Error(errInvalidID)
 		return
 	}
 
 	department, err := h.Service.GetDepartmentByID(uint(id))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "отдел не найден"})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, department)
+	setETag(c, department.Version)
+	c.JSON(http.StatusOK, newDepartmentResponse(department))
 }
 
 func (h *DepartmentHandler) GetAll(c *gin.Context) {
 	departments, err := h.Service.GetAllDepartments()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, departments)
+	c.JSON(http.StatusOK, newDepartmentResponses(departments))
 }
 
 func (h *DepartmentHandler) Update(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный ID"})
+		c.Error(errInvalidID)
+		return
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
-	var department models.Department
-	if err := c.ShouldBindJSON(&department); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var req DepartmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindError(err))
 		return
 	}
 
-	department.ID = uint(id)
-	if err := h.Service.UpdateDepartment(&department); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	h.save(c, uint(id), req, version)
+}
+
+func (h *DepartmentHandler) Patch(c *gin.Context) {
+	patchRecord(c, func(id uint) (DepartmentRequest, uint, error) {
+		current, err := h.Service.GetDepartmentByID(id)
+		if err != nil {
+			return DepartmentRequest{}, 0, err
+		}
+		return newDepartmentRequest(current), current.Version, nil
+	}, h.save)
+}
+
+func (h *DepartmentHandler) save(c *gin.Context, id uint, req DepartmentRequest, version uint) {
+	department := req.toModel()
+	department.ID = id
+	if err := h.Service.UpdateDepartment(currentActor(c), &department, version); err != nil {
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, department)
+	setETag(c, department.Version)
+	c.JSON(http.StatusOK, newDepartmentResponse(&department))
 }
 
 func (h *DepartmentHandler) Delete(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный ID"})
+		c.Error(errInvalidID)
+		return
+	}
+
+	if err := h.Service.DeleteDepartment(currentActor(c), uint(id)); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "отдел перемещен в архив"})
+}
+
+func (h *DepartmentHandler) GetArchived(c *gin.Context) {
+	items, err := h.Service.GetArchivedDepartments()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newDepartmentResponses(items))
+}
+
+func (h *DepartmentHandler) Restore(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID)
 		return
 	}
 
-	if err := h.Service.DeleteDepartment(uint(id)); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	restored, err := h.Service.RestoreDepartment(currentActor(c), uint(id))
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "отдел удален"})
+	setETag(c, restored.Version)
+	c.JSON(http.StatusOK, newDepartmentResponse(restored))
 }
 
 type SupplierHandler struct {
@@ -245,81 +515,133 @@ type SupplierHandler struct {
 }
 
 func (h *SupplierHandler) Create(c *gin.Context) {
-	var supplier models.Supplier
-	if err := c.ShouldBindJSON(&supplier); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var req SupplierRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindError(err))
 		return
 	}
 
-	if err := h.Service.CreateSupplier(&supplier); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	supplier := req.toModel()
+	if err := h.Service.CreateSupplier(currentActor(c), &supplier); err != nil {
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, supplier)
+	setETag(c, supplier.Version)
+	c.JSON(http.StatusCreated, newSupplierResponse(&supplier))
 }
 
 func (h *SupplierHandler) GetByID(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный ID"})
+		c.Error(errInvalidID)
 		return
 	}
 
 	supplier, err := h.Service.GetSupplierByID(uint(id))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "поставщик не найден"})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, supplier)
+	setETag(c, supplier.Version)
+	c.JSON(http.StatusOK, newSupplierResponse(supplier))
 }
 
 func (h *SupplierHandler) GetAll(c *gin.Context) {
 	suppliers, err := h.Service.GetAllSuppliers()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, suppliers)
+	c.JSON(http.StatusOK, newSupplierResponses(suppliers))
 }
 
 func (h *SupplierHandler) Update(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный ID"})
+		c.Error(errInvalidID)
 		return
 	}
 
-	var supplier models.Supplier
-	if err := c.ShouldBindJSON(&supplier); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
-	supplier.ID = uint(id)
-	if err := h.Service.UpdateSupplier(&supplier); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	var req SupplierRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindError(err))
 		return
 	}
 
-	c.JSON(http.StatusOK, supplier)
+	h.save(c, uint(id), req, version)
+}
+
+func (h *SupplierHandler) Patch(c *gin.Context) {
+	patchRecord(c, func(id uint) (SupplierRequest, uint, error) {
+		current, err := h.Service.GetSupplierByID(id)
+		if err != nil {
+			return SupplierRequest{}, 0, err
+		}
+		return newSupplierRequest(current), current.Version, nil
+	}, h.save)
+}
+
+func (h *SupplierHandler) save(c *gin.Context, id uint, req SupplierRequest, version uint) {
+	supplier := req.toModel()
+	supplier.ID = id
+	if err := h.Service.UpdateSupplier(currentActor(c), &supplier, version); err != nil {
+		c.Error(err)
+		return
+	}
+
+	setETag(c, supplier.Version)
+	c.JSON(http.StatusOK, newSupplierResponse(&supplier))
 }
 
 func (h *SupplierHandler) Delete(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный ID"})
+		c.Error(errInvalidID)
+		return
+	}
+
+	if err := h.Service.DeleteSupplier(currentActor(c), uint(id)); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "поставщик перемещен в архив"})
+}
+
+func (h *SupplierHandler) GetArchived(c *gin.Context) {
+	items, err := h.Service.GetArchivedSuppliers()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newSupplierResponses(items))
+}
+
+func (h *SupplierHandler) Restore(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID)
 		return
 	}
 
-	if err := h.Service.DeleteSupplier(uint(id)); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	restored, err := h.Service.RestoreSupplier(currentActor(c), uint(id))
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "поставщик удален"})
+	setETag(c, restored.Version)
+	c.JSON(http.StatusOK, newSupplierResponse(restored))
 }
 
 type SaleHandler struct {
@@ -327,38 +649,39 @@ type SaleHandler struct {
 }
 
 func (h *SaleHandler) Create(c *gin.Context) {
-	var sale models.Sale
-	if err := c.ShouldBindJSON(&sale); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var req SaleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindError(err))
 		return
 	}
 
+	sale := req.toModel()
 	userID, _ := c.Get("userID")
 	sale.CashierID = userID.(uint)
 	sale.SaleDate = time.Now()
 
-	if err := h.Service.CreateSale(&sale); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.Service.CreateSale(currentActor(c), &sale); err != nil {
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, sale)
+	c.JSON(http.StatusCreated, newSaleResponse(&sale))
 }
 
 func (h *SaleHandler) GetByID(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный ID"})
+		c.Error(errInvalidID)
 		return
 	}
 
 	sale, err := h.Service.GetSaleByID(uint(id))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "продажа не найдена"})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, sale)
+	c.JSON(http.StatusOK, newSaleResponse(sale))
 }
 
 func (h *SaleHandler) GetAll(c *gin.Context) {
@@ -375,11 +698,11 @@ func (h *SaleHandler) GetAll(c *gin.Context) {
 	}
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, sales)
+	c.JSON(http.StatusOK, newSaleResponses(sales))
 }
 
 type SupplyHandler struct {
@@ -387,103 +710,316 @@ type SupplyHandler struct {
 }
 
 func (h *SupplyHandler) Create(c *gin.Context) {
-	var requestData struct {
-		Supply models.Supply       `json:"supply"`
-		Items  []models.SupplyItem `json:"items"`
-	}
-
-	if err := c.ShouldBindJSON(&requestData); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var req SupplyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindError(err))
 		return
 	}
 
+	supply, items := req.toModel()
 	userID, _ := c.Get("userID")
-	requestData.Supply.ApprovedBy = userID.(uint)
-	requestData.Supply.SupplyDate = time.Now()
+	supply.ApprovedBy = userID.(uint)
+	supply.SupplyDate = time.Now()
 
-	if err := h.Service.CreateSupply(&requestData.Supply, requestData.Items); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.Service.CreateSupply(currentActor(c), &supply, items); err != nil {
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, requestData.Supply)
+	c.JSON(http.StatusCreated, newSupplyResponse(&supply))
 }
 
 func (h *SupplyHandler) GetByID(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный ID"})
+		c.Error(errInvalidID)
 		return
 	}
 
 	supply, err := h.Service.GetSupplyByID(uint(id))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "поставка не найдена"})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, supply)
+	c.JSON(http.StatusOK, newSupplyResponse(supply))
 }
 
 func (h *SupplyHandler) GetAll(c *gin.Context) {
 	supplies, err := h.Service.GetAllSupplies()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, supplies)
+	c.JSON(http.StatusOK, newSupplyResponses(supplies))
 }
 
 type AnalyticsHandler struct {
-	SaleService    services.SaleService
-	ProductService services.ProductService
+	SaleService           services.SaleService
+	ProductService        services.ProductService
+	SupplyService         services.SupplyService
+	ClassificationService services.ClassificationService
+	ForecastService       services.ForecastService
+	InventoryService      services.InventoryService
+	WriteOffService       services.WriteOffService
 }
 
 func (h *AnalyticsHandler) GetLowStockProducts(c *gin.Context) {
-	products, err := h.ProductService.GetAllProducts()
+	lowStockProducts, err := h.ProductService.GetLowStockProducts(currentActor(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	var lowStockProducts []models.Product
-	for _, product := range products {
-		if product.CurrentQty <= product.MinThreshold {
-			lowStockProducts = append(lowStockProducts, product)
-		}
+	c.JSON(http.StatusOK, newProductResponses(lowStockProducts))
+}
+
+// GetSalesByPeriod возвращает аналитику продаж за период: итоги с
+// сравнением с предыдущим периодом, разбивку по group_by и top лучших и
+// худших товаров.
+func (h *AnalyticsHandler) GetSalesByPeriod(c *gin.Context) {
+	from, to, err := queryPeriod(c)
+	if err != nil {
+		c.Error(err)
+		return
 	}
 
-	c.JSON(http.StatusOK, lowStockProducts)
+	top, _ := strconv.Atoi(c.Query("top"))
+
+	analytics, err := h.SaleService.GetSalesAnalytics(currentActor(c), from, to, c.Query("group_by"), top)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newSalesAnalyticsResponse(analytics))
 }
 
-func (h *AnalyticsHandler) GetSalesByPeriod(c *gin.Context) {
-	startDate := c.Query("start_date")
-	endDate := c.Query("end_date")
+// GetCustomers возвращает выручку за период в разрезе покупателей:
+// продажи с картой покупателя, повторные покупки и лучших покупателей.
+func (h *AnalyticsHandler) GetCustomers(c *gin.Context) {
+	from, to, err := queryPeriod(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	analytics, err := h.SaleService.GetCustomerAnalytics(currentActor(c), from, to)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newCustomerAnalyticsResponse(analytics))
+}
+
+// GetSupplierScorecard возвращает показатели поставщика за период.
+// near_expiry_days задает, сколько дней до конца срока годности считается
+// поставкой на исходе срока.
+func (h *AnalyticsHandler) GetSupplierScorecard(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID)
+		return
+	}
+
+	from, to, err := queryPeriod(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	nearExpiryDays, _ := strconv.Atoi(c.Query("near_expiry_days"))
+
+	card, err := h.SupplyService.GetSupplierScorecard(uint(id), from, to, nearExpiryDays)
+	if err != nil {
+		c.Error(err)
+		return
+	}
 
-	if startDate == "" || endDate == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "требуются параметры start_date и end_date"})
+	c.JSON(http.StatusOK, newSupplierScorecardResponse(card))
+}
+
+// GetABCXYZ возвращает ABC/XYZ-анализ товаров за weeks недель,
+// заканчивающихся датой date (по умолчанию сегодня).
+func (h *AnalyticsHandler) GetABCXYZ(c *gin.Context) {
+	day, err := queryDate(c, "date")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	weeks, _ := strconv.Atoi(c.Query("weeks"))
+
+	analysis, err := h.ClassificationService.Classify(currentActor(c), day, weeks)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newABCXYZResponse(analysis))
+}
+
+// ApplyABCXYZ проводит ABC/XYZ-анализ всех товаров с теми же параметрами,
+// что и GetABCXYZ, и записывает класс ABC в сорт товаров доступных отделов.
+func (h *AnalyticsHandler) ApplyABCXYZ(c *gin.Context) {
+	day, err := queryDate(c, "date")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	weeks, _ := strconv.Atoi(c.Query("weeks"))
+
+	changes, err := h.ClassificationService.Apply(currentActor(c), day, weeks)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newABCXYZApplyResponse(changes))
+}
+
+// GetForecast прогнозирует дневной спрос на товар product_id на days дней
+// по продажам за history_days дней.
+func (h *AnalyticsHandler) GetForecast(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Query("product_id"), 10, 32)
+	if err != nil {
+		c.Error(invalidQuery("требуется параметр product_id"))
+		return
+	}
+
+	days, _ := strconv.Atoi(c.Query("days"))
+	historyDays, _ := strconv.Atoi(c.Query("history_days"))
+
+	forecast, err := h.ForecastService.Forecast(currentActor(c), uint(productID), days, historyDays)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newForecastResponse(forecast))
+}
+
+// GetStockValuation оценивает остатки на конец дня (параметр date, по
+// умолчанию сегодня) по отделам или поставщикам (group_by).
+func (h *AnalyticsHandler) GetStockValuation(c *gin.Context) {
+	day, err := queryDate(c, "date")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	valuation, err := h.InventoryService.GetValuation(currentActor(c), day, c.Query("group_by"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newStockValuationResponse(valuation))
+}
+
+// GetInventoryTurnover возвращает оборачиваемость запасов по товарам за
+// период.
+func (h *AnalyticsHandler) GetInventoryTurnover(c *gin.Context) {
+	from, to, err := queryPeriod(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	turnover, err := h.InventoryService.GetTurnover(currentActor(c), from, to)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newInventoryTurnoverResponse(turnover))
+}
+
+// GetDeadStock возвращает товары с остатком, не продававшиеся days дней.
+func (h *AnalyticsHandler) GetDeadStock(c *gin.Context) {
+	days, _ := strconv.Atoi(c.Query("days"))
+
+	report, err := h.InventoryService.GetDeadStock(currentActor(c), days)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
-	sales, err := h.SaleService.GetSalesByDateRange(startDate, endDate)
+	c.JSON(http.StatusOK, newDeadStockResponse(report))
+}
+
+// GetShrinkage возвращает утвержденные списания за период по отделам и
+// причинам.
+func (h *AnalyticsHandler) GetShrinkage(c *gin.Context) {
+	from, to, err := queryPeriod(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	report, err := h.WriteOffService.GetShrinkage(currentActor(c), from, to)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	// Аналитика продаж
-	var totalRevenue float64
-	productSales := make(map[uint]int)
+	c.JSON(http.StatusOK, newShrinkageResponse(report))
+}
 
-	for _, sale := range sales {
-		totalRevenue += sale.TotalPrice
-		productSales[sale.ProductID] += sale.Quantity
+type AuditHandler struct {
+	Service services.AuditService
+}
+
+func (h *AuditHandler) GetAll(c *gin.Context) {
+	var filter models.AuditFilter
+	filter.Action = c.Query("action")
+	filter.EntityType = c.Query("entity_type")
+	filter.RequestID = c.Query("request_id")
+
+	for param, target := range map[string]*uint{
+		"actor_id":  &filter.ActorID,
+		"entity_id": &filter.EntityID,
+	} {
+		if value := c.Query(param); value != "" {
+			id, err := strconv.ParseUint(value, 10, 32)
+			if err != nil {
+				c.Error(invalidQuery("некорректный параметр " + param))
+				return
+			}
+			*target = uint(id)
+		}
+	}
+
+	for param, target := range map[string]**time.Time{
+		"start_date": &filter.Start,
+		"end_date":   &filter.End,
+	} {
+		if value := c.Query(param); value != "" {
+			date, err := time.Parse("2006-01-02", value)
+			if err != nil {
+				c.Error(invalidQuery("некорректная дата " + param + ", ожидается YYYY-MM-DD"))
+				return
+			}
+			*target = &date
+		}
+	}
+	// Конечная дата включается в период целиком
+	if filter.End != nil {
+		end := filter.End.AddDate(0, 0, 1)
+		filter.End = &end
+	}
+
+	filter.Limit, _ = strconv.Atoi(c.Query("limit"))
+	filter.Offset, _ = strconv.Atoi(c.Query("offset"))
+
+	entries, err := h.Service.Find(filter)
+	if err != nil {
+		c.Error(err)
+		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"total_sales":   len(sales),
-		"total_revenue": totalRevenue,
-		"product_sales": productSales,
-	})
+	c.JSON(http.StatusOK, newAuditLogResponses(entries))
 }
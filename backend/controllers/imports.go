@@ -0,0 +1,374 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/xuri/excelize/v2"
+
+	"grocery-store-api/models"
+	"grocery-store-api/services"
+	"grocery-store-api/services/errs"
+	"grocery-store-api/spreadsheet"
+)
+
+// maxImportFileSize ограничивает размер загружаемого файла импорта.
+const maxImportFileSize = 10 << 20
+
+type ImportHandler struct {
+	Service services.ImportService
+}
+
+// productImportRecord — строка файла импорта товаров после разбора ячеек.
+// Правила совпадают с ProductRequest, но отдел и поставщик заданы названиями.
+type productImportRecord struct {
+	Name         string    `json:"name" binding:"required,max=100"`
+	Department   string    `json:"department" binding:"required,max=100"`
+	Supplier     string    `json:"supplier" binding:"required,max=100"`
+	Grade        string    `json:"grade" binding:"required,grade"`
+	Price        float64   `json:"price" binding:"gt=0"`
+	CurrentQty   int       `json:"current_quantity" binding:"gte=0"`
+	MinThreshold int       `json:"min_threshold" binding:"gte=0"`
+	ExpiryDate   time.Time `json:"expiry_date" binding:"required,not_past"`
+	StorageCond  string    `json:"storage_cond" binding:"required,storage_cond"`
+	Unit         string    `json:"unit" binding:"omitempty,unit"`
+	NetQuantity  float64   `json:"net_quantity" binding:"gte=0"`
+}
+
+// importColumn описывает колонку файла: ее обязательность и разбор ячейки
+// в поле записи.
+type importColumn[T any] struct {
+	required bool
+	set      func(record *T, value string) error
+}
+
+var productImportColumns = map[string]importColumn[productImportRecord]{
+	"name":             {true, func(r *productImportRecord, v string) error { r.Name = v; return nil }},
+	"department":       {true, func(r *productImportRecord, v string) error { r.Department = v; return nil }},
+	"supplier":         {true, func(r *productImportRecord, v string) error { r.Supplier = v; return nil }},
+	"grade":            {true, func(r *productImportRecord, v string) error { r.Grade = strings.ToUpper(v); return nil }},
+	"price":            {true, func(r *productImportRecord, v string) (err error) { r.Price, err = parseImportFloat(v); return }},
+	"current_quantity": {false, func(r *productImportRecord, v string) (err error) { r.CurrentQty, err = parseImportInt(v); return }},
+	"min_threshold":    {false, func(r *productImportRecord, v string) (err error) { r.MinThreshold, err = parseImportInt(v); return }},
+	"expiry_date":      {true, func(r *productImportRecord, v string) (err error) { r.ExpiryDate, err = parseImportDate(v); return }},
+	"storage_cond":     {true, func(r *productImportRecord, v string) error { r.StorageCond = strings.ToLower(v); return nil }},
+	"unit":             {false, func(r *productImportRecord, v string) error { r.Unit = strings.ToLower(v); return nil }},
+	"net_quantity":     {false, func(r *productImportRecord, v string) (err error) { r.NetQuantity, err = parseImportFloat(v); return }},
+}
+
+var supplierImportColumns = map[string]importColumn[SupplierRequest]{
+	"name":              {true, func(r *SupplierRequest, v string) error { r.Name = v; return nil }},
+	"phone":             {false, func(r *SupplierRequest, v string) error { r.Phone = v; return nil }},
+	"contact_person":    {false, func(r *SupplierRequest, v string) error { r.ContactPerson = v; return nil }},
+	"payment_term_days": {false, func(r *SupplierRequest, v string) (err error) { r.PaymentTermDays, err = parseImportInt(v); return }},
+}
+
+// importColumnAliases — русские заголовки колонок, которые принимаются
+// наравне с именами полей API.
+var importColumnAliases = map[string]string{
+	"название":            "name",
+	"наименование":        "name",
+	"отдел":               "department",
+	"поставщик":           "supplier",
+	"сорт":                "grade",
+	"цена":                "price",
+	"остаток":             "current_quantity",
+	"количество":          "current_quantity",
+	"минимальный остаток": "min_threshold",
+	"срок годности":       "expiry_date",
+	"условия хранения":    "storage_cond",
+	"единица измерения":   "unit",
+	"фасовка":             "net_quantity",
+	"телефон":             "phone",
+	"контактное лицо":     "contact_person",
+	"отсрочка платежа":    "payment_term_days",
+}
+
+// ImportProducts загружает товары из CSV или XLSX. С dry_run=true файл
+// только проверяется.
+func (h *ImportHandler) ImportProducts(c *gin.Context) {
+	table, dryRun, err := readImportFile(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	records, err := parseImportTable(table, productImportColumns)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	rows := make([]services.ProductImportRow, 0, len(records))
+	for _, record := range records {
+		unit, netQuantity := productPackaging(record.value.Unit, record.value.NetQuantity)
+		rows = append(rows, services.ProductImportRow{
+			Line: record.line,
+			Product: models.Product{
+				Name:         record.value.Name,
+				Grade:        record.value.Grade,
+				Price:        record.value.Price,
+				CurrentQty:   record.value.CurrentQty,
+				MinThreshold: record.value.MinThreshold,
+				ExpiryDate:   record.value.ExpiryDate,
+				StorageCond:  record.value.StorageCond,
+				Unit:         unit,
+				NetQuantity:  netQuantity,
+			},
+			Department: record.value.Department,
+			Supplier:   record.value.Supplier,
+			Errors:     record.errors,
+		})
+	}
+
+	report, err := h.Service.ImportProducts(currentActor(c), rows, dryRun)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(importStatus(report), report)
+}
+
+func (h *ImportHandler) ImportSuppliers(c *gin.Context) {
+	table, dryRun, err := readImportFile(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	records, err := parseImportTable(table, supplierImportColumns)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	rows := make([]services.SupplierImportRow, 0, len(records))
+	for _, record := range records {
+		rows = append(rows, services.SupplierImportRow{
+			Line:     record.line,
+			Supplier: record.value.toModel(),
+			Errors:   record.errors,
+		})
+	}
+
+	report, err := h.Service.ImportSuppliers(currentActor(c), rows, dryRun)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(importStatus(report), report)
+}
+
+func importStatus(report *services.ImportReport) int {
+	if report.DryRun {
+		return http.StatusOK
+	}
+	return http.StatusCreated
+}
+
+// readImportFile читает таблицу из поля file формы. Формат берется из
+// параметра format или из расширения файла.
+func readImportFile(c *gin.Context) ([][]string, bool, error) {
+	dryRun := false
+	if value := c.Query("dry_run"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, false, invalidQuery("некорректный параметр dry_run")
+		}
+		dryRun = parsed
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportFileSize)
+	header, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, false, errs.NewValidation("import_too_large", fmt.Sprintf("файл больше %d МБ", maxImportFileSize>>20))
+		}
+		return nil, false, errs.NewValidation("invalid_request", "нужен файл в поле file")
+	}
+
+	format, err := spreadsheet.ParseFormat(c.Query("format"), header.Filename)
+	if err != nil {
+		return nil, false, errs.NewValidation("unsupported_format", err.Error())
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return nil, false, err
+	}
+	defer file.Close()
+
+	table, err := spreadsheet.ReadRows(file, format)
+	if err != nil {
+		return nil, false, errs.NewValidation("invalid_file", "не удалось прочитать файл: "+err.Error())
+	}
+
+	return table, dryRun, nil
+}
+
+type importRecord[T any] struct {
+	line   int
+	value  T
+	errors []errs.FieldError
+}
+
+// parseImportTable разбирает таблицу с заголовком в первой строке. Ошибки
+// формата отдельных ячеек и правил валидации привязываются к строкам и не
+// прерывают разбор; ошибкой всего файла считаются только проблемы заголовка.
+func parseImportTable[T any](table [][]string, columns map[string]importColumn[T]) ([]importRecord[T], error) {
+	if len(table) == 0 {
+		return nil, errs.NewValidation("empty_file", "файл не содержит строк")
+	}
+
+	header := make([]string, len(table[0]))
+	seen := make(map[string]bool)
+	var headerErrors []errs.FieldError
+	for i, title := range table[0] {
+		name := importColumnName(title)
+		if _, ok := columns[name]; !ok {
+			if name != "" {
+				headerErrors = append(headerErrors, errs.FieldError{Field: fmt.Sprintf("columns[%d]", i+1), Rule: "unknown", Message: fmt.Sprintf("неизвестная колонка «%s»", title)})
+			}
+			continue
+		}
+		if seen[name] {
+			headerErrors = append(headerErrors, errs.FieldError{Field: fmt.Sprintf("columns[%d]", i+1), Rule: "duplicate", Message: fmt.Sprintf("колонка «%s» указана дважды", title)})
+			continue
+		}
+		seen[name] = true
+		header[i] = name
+	}
+	names := make([]string, 0, len(columns))
+	for name := range columns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if columns[name].required && !seen[name] {
+			headerErrors = append(headerErrors, errs.FieldError{Field: "columns", Rule: "required", Message: "нет обязательной колонки " + name})
+		}
+	}
+	if len(headerErrors) > 0 {
+		return nil, errs.NewFieldValidation("invalid_columns", "некорректный заголовок файла", headerErrors)
+	}
+
+	records := make([]importRecord[T], 0, len(table)-1)
+	for i, cells := range table[1:] {
+		if blankRow(cells) {
+			continue
+		}
+
+		// Номер строки в файле: первая строка — заголовок
+		record := importRecord[T]{line: i + 2}
+		for j, cell := range cells {
+			if j >= len(header) || header[j] == "" {
+				continue
+			}
+			value := strings.TrimSpace(cell)
+			if value == "" {
+				continue
+			}
+			if err := columns[header[j]].set(&record.value, value); err != nil {
+				record.errors = append(record.errors, services.RowError(record.line, header[j], "type", err.Error()))
+			}
+		}
+
+		if err := binding.Validator.ValidateStruct(&record.value); err != nil {
+			var validationErrs validator.ValidationErrors
+			if !errors.As(err, &validationErrs) {
+				return nil, err
+			}
+			for _, fieldErr := range fieldErrors(validationErrs) {
+				if !hasRowError(record.errors, record.line, fieldErr.Field) {
+					record.errors = append(record.errors, services.RowError(record.line, fieldErr.Field, fieldErr.Rule, fieldErr.Message))
+				}
+			}
+		}
+
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+func importColumnName(title string) string {
+	name := strings.ToLower(strings.Join(strings.Fields(title), " "))
+	if alias, ok := importColumnAliases[name]; ok {
+		return alias
+	}
+	return strings.ReplaceAll(name, " ", "_")
+}
+
+func blankRow(cells []string) bool {
+	for _, cell := range cells {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// hasRowError сообщает, есть ли уже ошибка разбора для поля: тогда ошибка
+// валидации того же поля (например, required для нераспознанной даты) лишняя.
+func hasRowError(rowErrors []errs.FieldError, line int, field string) bool {
+	path := services.RowError(line, field, "", "").Field
+	for _, fieldErr := range rowErrors {
+		if fieldErr.Field == path {
+			return true
+		}
+	}
+	return false
+}
+
+func parseImportFloat(value string) (float64, error) {
+	number, err := strconv.ParseFloat(normalizeNumber(value), 64)
+	if err != nil {
+		return 0, errors.New("ожидается число")
+	}
+	return number, nil
+}
+
+func parseImportInt(value string) (int, error) {
+	number, err := strconv.ParseFloat(normalizeNumber(value), 64)
+	if err != nil || number != float64(int(number)) {
+		return 0, errors.New("ожидается целое число")
+	}
+	return int(number), nil
+}
+
+// normalizeNumber убирает пробелы-разделители разрядов и заменяет
+// десятичную запятую точкой.
+func normalizeNumber(value string) string {
+	return strings.NewReplacer(" ", "", "\u00a0", "", ",", ".").Replace(value)
+}
+
+var importDateLayouts = []string{"2006-01-02", "02.01.2006", time.RFC3339}
+
+// parseImportDate принимает даты в форматах YYYY-MM-DD, DD.MM.YYYY, RFC 3339
+// и серийные номера дат Excel.
+func parseImportDate(value string) (time.Time, error) {
+	for _, layout := range importDateLayouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date, nil
+		}
+	}
+
+	if serial, err := strconv.ParseFloat(value, 64); err == nil {
+		if date, err := excelize.ExcelDateToTime(serial, false); err == nil {
+			return date, nil
+		}
+	}
+
+	return time.Time{}, errors.New("ожидается дата в формате YYYY-MM-DD или DD.MM.YYYY")
+}
@@ -0,0 +1,153 @@
+package controllers
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+
+	"grocery-store-api/services/errs"
+)
+
+// MergePatchContentType — тип тела PATCH-запросов (RFC 7386). Обычный
+// application/json тоже принимается.
+const MergePatchContentType = "application/merge-patch+json"
+
+var errInvalidIfMatch = errs.NewValidation("invalid_if_match", "некорректный заголовок If-Match, ожидается версия записи в кавычках")
+
+// setETag отдает версию записи в заголовке ETag, чтобы клиент мог
+// вернуть ее в If-Match при следующем изменении.
+func setETag(c *gin.Context, version uint) {
+	c.Header("ETag", strconv.Quote(strconv.FormatUint(uint64(version), 10)))
+}
+
+// ifMatchVersion возвращает версию из заголовка If-Match. 0 означает, что
+// заголовок не передан или равен "*", то есть версия не проверяется.
+func ifMatchVersion(c *gin.Context) (uint, error) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		return 0, nil
+	}
+
+	tag := strings.TrimSpace(strings.Split(header, ",")[0])
+	tag = strings.TrimPrefix(tag, "W/")
+	unquoted, err := strconv.Unquote(tag)
+	if err != nil {
+		return 0, errInvalidIfMatch
+	}
+
+	version, err := strconv.ParseUint(unquoted, 10, 32)
+	if err != nil || version == 0 {
+		return 0, errInvalidIfMatch
+	}
+	return uint(version), nil
+}
+
+// patchRecord выполняет PATCH записи: читает ее текущее состояние через
+// load, применяет к нему JSON merge patch и передает результат в save,
+// который сохраняет запись и отдает ее вместе с ETag. Без If-Match версия
+// берется из прочитанной записи, так что одновременное изменение все равно
+// не будет потеряно.
+func patchRecord[R any](c *gin.Context, load func(id uint) (R, uint, error), save func(c *gin.Context, id uint, req R, version uint)) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID)
+		return
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	req, currentVersion, err := load(uint(id))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if version == 0 {
+		version = currentVersion
+	}
+
+	if err := bindMergePatch(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	save(c, uint(id), req, version)
+}
+
+// bindMergePatch применяет тело запроса как JSON merge patch к req, в
+// котором уже лежит текущее состояние записи, и проверяет результат теми
+// же правилами, что и полную замену.
+func bindMergePatch(c *gin.Context, req interface{}) error {
+	patch, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return bindError(err)
+	}
+
+	current, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	merged, err := mergePatch(current, patch)
+	if err != nil {
+		return bindError(err)
+	}
+
+	// Поля, удаленные патчем (null), должны получить нулевые значения,
+	// поэтому результат разбирается в пустую структуру
+	target := reflect.ValueOf(req).Elem()
+	target.Set(reflect.Zero(target.Type()))
+	if err := json.Unmarshal(merged, req); err != nil {
+		return bindError(err)
+	}
+
+	if err := binding.Validator.ValidateStruct(req); err != nil {
+		return bindError(err)
+	}
+	return nil
+}
+
+// mergePatch реализует RFC 7386: объекты сливаются рекурсивно, null
+// удаляет поле, любое другое значение заменяет его целиком.
+func mergePatch(document, patch []byte) ([]byte, error) {
+	var patchValue interface{}
+	if err := json.Unmarshal(patch, &patchValue); err != nil {
+		return nil, err
+	}
+
+	var documentValue interface{}
+	if err := json.Unmarshal(document, &documentValue); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(mergeValue(documentValue, patchValue))
+}
+
+func mergeValue(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = make(map[string]interface{})
+	}
+
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+			continue
+		}
+		targetObject[key] = mergeValue(targetObject[key], value)
+	}
+	return targetObject
+}
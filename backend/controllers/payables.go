@@ -0,0 +1,189 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"grocery-store-api/models"
+	"grocery-store-api/services"
+)
+
+// PayablesHandler обслуживает счета поставщиков и задолженность перед ними.
+type PayablesHandler struct {
+	Service services.PayablesService
+}
+
+// RegisterInvoice регистрирует счет поставщика за поставку.
+func (h *PayablesHandler) RegisterInvoice(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID)
+		return
+	}
+
+	var req InvoiceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindError(err))
+		return
+	}
+
+	invoice := models.SupplierInvoice{
+		SupplyID:    uint(id),
+		Number:      req.Number,
+		InvoiceDate: req.InvoiceDate,
+		DueDate:     req.DueDate,
+		Amount:      req.Amount,
+	}
+	if err := h.Service.RegisterInvoice(currentActor(c), &invoice); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, newInvoiceResponse(&invoice, time.Now()))
+}
+
+func (h *PayablesHandler) GetAll(c *gin.Context) {
+	var filter models.InvoiceFilter
+	filter.Status = c.Query("status")
+
+	if value := c.Query("supplier_id"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			c.Error(invalidQuery("некорректный параметр supplier_id"))
+			return
+		}
+		filter.SupplierID = uint(id)
+	}
+	if value := c.Query("overdue"); value != "" {
+		overdue, err := strconv.ParseBool(value)
+		if err != nil {
+			c.Error(invalidQuery("некорректный параметр overdue, ожидается true или false"))
+			return
+		}
+		filter.Overdue = overdue
+	}
+
+	filter.Limit, _ = strconv.Atoi(c.Query("limit"))
+	filter.Offset, _ = strconv.Atoi(c.Query("offset"))
+
+	invoices, err := h.Service.GetInvoices(filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newInvoiceResponses(invoices, time.Now()))
+}
+
+func (h *PayablesHandler) GetByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID)
+		return
+	}
+
+	invoice, err := h.Service.GetInvoice(uint(id))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newInvoiceResponse(invoice, time.Now()))
+}
+
+// Pay записывает оплату счета, в том числе частичную.
+func (h *PayablesHandler) Pay(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID)
+		return
+	}
+
+	var req InvoicePaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindError(err))
+		return
+	}
+
+	payment := models.InvoicePayment{
+		InvoiceID: uint(id),
+		Amount:    req.Amount,
+		PaidAt:    req.PaidAt,
+		Method:    req.Method,
+		Reference: req.Reference,
+	}
+	invoice, err := h.Service.PayInvoice(currentActor(c), &payment)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, newInvoiceResponse(invoice, time.Now()))
+}
+
+// GetAging возвращает задолженность перед поставщиками по срокам
+// просрочки на дату (параметр date, по умолчанию сегодня).
+func (h *PayablesHandler) GetAging(c *gin.Context) {
+	day, err := queryDate(c, "date")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	aging, err := h.Service.GetAging(day)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newPayablesAgingResponse(aging))
+}
+
+// GetAlerts возвращает неподтвержденные оповещения о просроченных счетах.
+func (h *PayablesHandler) GetAlerts(c *gin.Context) {
+	invoices, err := h.Service.GetOverdueAlerts()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newInvoiceResponses(invoices, time.Now()))
+}
+
+// Acknowledge подтверждает оповещение о просрочке счета.
+func (h *PayablesHandler) Acknowledge(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID)
+		return
+	}
+
+	invoice, err := h.Service.AcknowledgeOverdue(currentActor(c), uint(id))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newInvoiceResponse(invoice, time.Now()))
+}
+
+// GetOverdue возвращает счета, просроченные на дату (параметр date, по
+// умолчанию сегодня), начиная с самых старых.
+func (h *PayablesHandler) GetOverdue(c *gin.Context) {
+	day, err := queryDate(c, "date")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	invoices, err := h.Service.GetOverdue(day)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newInvoiceResponses(invoices, day))
+}
@@ -0,0 +1,55 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"grocery-store-api/services"
+)
+
+// ReturnHandler обслуживает возвраты товара по продажам.
+type ReturnHandler struct {
+	Service services.ReturnService
+}
+
+// Create оформляет возврат части товара продажи в открытой смене
+// текущего пользователя.
+func (h *ReturnHandler) Create(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID)
+		return
+	}
+
+	var req SaleReturnRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindError(err))
+		return
+	}
+
+	ret, err := h.Service.CreateReturn(currentActor(c), uint(id), req.Quantity, req.Reason)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, newSaleReturnResponse(ret))
+}
+
+func (h *ReturnHandler) GetBySale(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID)
+		return
+	}
+
+	returns, err := h.Service.GetReturns(uint(id))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newSaleReturnResponses(returns))
+}
@@ -0,0 +1,157 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"grocery-store-api/models"
+	"grocery-store-api/services"
+)
+
+type ShiftHandler struct {
+	Service services.ShiftService
+}
+
+func (h *ShiftHandler) Open(c *gin.Context) {
+	var req OpenShiftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindError(err))
+		return
+	}
+
+	shift, err := h.Service.OpenShift(currentActor(c), req.Register, req.OpeningFloat)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, newShiftResponse(shift))
+}
+
+func (h *ShiftHandler) GetCurrent(c *gin.Context) {
+	shift, err := h.Service.GetCurrentShift(currentActor(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newShiftResponse(shift))
+}
+
+func (h *ShiftHandler) GetByID(c *gin.Context) {
+	id, ok := shiftID(c)
+	if !ok {
+		return
+	}
+
+	shift, err := h.Service.GetShift(currentActor(c), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newShiftResponse(shift))
+}
+
+func (h *ShiftHandler) GetAll(c *gin.Context) {
+	var filter models.ShiftFilter
+	filter.Register = c.Query("register")
+
+	if value := c.Query("cashier_id"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			c.Error(invalidQuery("некорректный параметр cashier_id"))
+			return
+		}
+		filter.CashierID = uint(id)
+	}
+	if value := c.Query("open"); value != "" {
+		open, err := strconv.ParseBool(value)
+		if err != nil {
+			c.Error(invalidQuery("некорректный параметр open, ожидается true или false"))
+			return
+		}
+		filter.Open = &open
+	}
+
+	filter.Limit, _ = strconv.Atoi(c.Query("limit"))
+	filter.Offset, _ = strconv.Atoi(c.Query("offset"))
+
+	shifts, err := h.Service.GetShifts(filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newShiftResponses(shifts))
+}
+
+func (h *ShiftHandler) AddCashMovement(c *gin.Context) {
+	id, ok := shiftID(c)
+	if !ok {
+		return
+	}
+
+	var req CashMovementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindError(err))
+		return
+	}
+
+	movement, err := h.Service.AddCashMovement(currentActor(c), id, req.Type, req.Amount, req.Reason)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, newCashMovementResponse(movement))
+}
+
+// GetReport возвращает X-отчет открытой смены или Z-отчет закрытой.
+func (h *ShiftHandler) GetReport(c *gin.Context) {
+	id, ok := shiftID(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.Service.GetReport(currentActor(c), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newShiftReportResponse(report))
+}
+
+// Close закрывает смену и возвращает Z-отчет.
+func (h *ShiftHandler) Close(c *gin.Context) {
+	id, ok := shiftID(c)
+	if !ok {
+		return
+	}
+
+	var req CloseShiftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindError(err))
+		return
+	}
+
+	report, err := h.Service.CloseShift(currentActor(c), id, *req.CountedCash)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newShiftReportResponse(report))
+}
+
+func shiftID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID)
+		return 0, false
+	}
+	return uint(id), true
+}
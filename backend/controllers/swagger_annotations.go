@@ -1,41 +1,237 @@
 package controllers
 
 // @Summary Регистрация нового пользователя
-// @Description Создание нового пользователя в системе
+// @Description Создание нового пользователя в системе (только для администратора). Роль должна быть одной из admin, manager, cashier
 // @Tags auth
 // @Accept json
 // @Produce json
-// @Param user body models.RegisterRequest true "Данные пользователя"
+// @Security BearerAuth
+// @Param user body controllers.RegisterRequest true "Данные пользователя"
 // @Success 201 {object} map[string]interface{} "Пользователь зарегистрирован"
-// @Failure 400 {object} map[string]interface{} "Ошибка в данных запроса"
-// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Failure 400 {object} controllers.ErrorResponse "Ошибка в данных запроса, недопустимая роль или слабый пароль"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 409 {object} controllers.ErrorResponse "Имя пользователя уже занято"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
 // @Router /register [post]
 func swaggerRegister() {}
 
 // @Summary Вход в систему
-// @Description Аутентификация пользователя и получение JWT токена
+// @Description Аутентификация пользователя и получение JWT токена. После нескольких неудачных попыток вход для учетной записи или IP-адреса временно блокируется. Заблокированная учетная запись получает тот же ответ 401, что и неверный пароль, чтобы по ответам нельзя было узнать, существует ли имя пользователя
 // @Tags auth
 // @Accept json
 // @Produce json
-// @Param credentials body models.LoginRequest true "Учетные данные"
+// @Param credentials body controllers.LoginRequest true "Учетные данные"
 // @Success 200 {object} map[string]interface{} "Успешная аутентификация с токеном"
-// @Failure 400 {object} map[string]interface{} "Ошибка в данных запроса"
-// @Failure 401 {object} map[string]interface{} "Неверные учетные данные"
+// @Failure 400 {object} controllers.ErrorResponse "Ошибка в данных запроса"
+// @Failure 401 {object} controllers.ErrorResponse "Неверные учетные данные"
+// @Failure 429 {object} controllers.ErrorResponse "Слишком много неудачных попыток входа"
 // @Router /login [post]
 func swaggerLogin() {}
 
+// @Summary Получение списка пользователей
+// @Description Получение списка всех пользователей системы
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} controllers.UserResponse "Список пользователей"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /users [get]
+func swaggerGetAllUsers() {}
+
+// @Summary Получение пользователя по ID
+// @Description Получение информации о пользователе по ID
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID пользователя"
+// @Success 200 {object} controllers.UserResponse "Данные пользователя"
+// @Failure 400 {object} controllers.ErrorResponse "Некорректный ID"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 404 {object} controllers.ErrorResponse "Пользователь не найден"
+// @Router /users/{id} [get]
+func swaggerGetUser() {}
+
+// @Summary Создание пользователя
+// @Description Создание нового пользователя администратором
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param user body controllers.RegisterRequest true "Данные пользователя"
+// @Success 201 {object} map[string]interface{} "Пользователь создан"
+// @Failure 400 {object} controllers.ErrorResponse "Ошибка в данных запроса, недопустимая роль или слабый пароль"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 409 {object} controllers.ErrorResponse "Имя пользователя уже занято"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /users [post]
+func swaggerCreateUser() {}
+
+// @Summary Изменение роли пользователя
+// @Description Назначение пользователю одной из ролей admin, manager, cashier
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID пользователя"
+// @Param role body controllers.UpdateRoleRequest true "Новая роль"
+// @Success 200 {object} controllers.UserResponse "Роль изменена"
+// @Failure 400 {object} controllers.ErrorResponse "Ошибка в данных запроса, недопустимая роль или слабый пароль"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 404 {object} controllers.ErrorResponse "Пользователь не найден"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /users/{id}/role [put]
+func swaggerChangeUserRole() {}
+
+// @Summary Прикрепление сотрудника к отделу
+// @Description Сотрудник получает доступ к отделу наравне с его руководителем: например, кассир может оформлять в нем акты списания. department_id: null открепляет сотрудника
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID пользователя"
+// @Param department body controllers.UserDepartmentRequest true "Отдел сотрудника"
+// @Success 200 {object} controllers.UserResponse "Отдел изменен"
+// @Failure 400 {object} controllers.ErrorResponse "Ошибка в данных запроса"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 404 {object} controllers.ErrorResponse "Пользователь или отдел не найден"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /users/{id}/department [put]
+func swaggerSetUserDepartment() {}
+
+// @Summary Сброс пароля пользователя
+// @Description Установка нового пароля пользователю администратором. Снимает блокировку входа
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID пользователя"
+// @Param password body controllers.ResetPasswordRequest true "Новый пароль"
+// @Success 200 {object} map[string]interface{} "Пароль сброшен"
+// @Failure 400 {object} controllers.ErrorResponse "Ошибка в данных запроса или пароль не соответствует политике"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 404 {object} controllers.ErrorResponse "Пользователь не найден"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /users/{id}/password [put]
+func swaggerResetUserPassword() {}
+
+// @Summary Отключение пользователя
+// @Description Блокировка входа пользователя в систему
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID пользователя"
+// @Success 200 {object} controllers.UserResponse "Пользователь отключен"
+// @Failure 400 {object} controllers.ErrorResponse "Некорректный ID"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 404 {object} controllers.ErrorResponse "Пользователь не найден"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /users/{id}/disable [post]
+func swaggerDisableUser() {}
+
+// @Summary Включение пользователя
+// @Description Снятие блокировки входа пользователя
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID пользователя"
+// @Success 200 {object} controllers.UserResponse "Пользователь включен"
+// @Failure 400 {object} controllers.ErrorResponse "Некорректный ID"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 404 {object} controllers.ErrorResponse "Пользователь не найден"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /users/{id}/enable [post]
+func swaggerEnableUser() {}
+
+// @Summary Получение разрешений ролей
+// @Description Получение списка ролей с назначенными им разрешениями
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.RolePermissions "Роли и их разрешения"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /roles [get]
+func swaggerGetAllRoles() {}
+
+// @Summary Получение списка разрешений
+// @Description Получение списка всех разрешений, которые можно назначить роли
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} string "Список разрешений"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Router /roles/permissions [get]
+func swaggerGetPermissions() {}
+
+// @Summary Изменение разрешений роли
+// @Description Полная замена набора разрешений роли. Изменения действуют сразу, без повторного входа пользователей
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param role path string true "Роль (admin, manager, cashier)"
+// @Param permissions body controllers.RolePermissionsRequest true "Новый набор разрешений"
+// @Success 200 {object} models.RolePermissions "Разрешения роли обновлены"
+// @Failure 400 {object} controllers.ErrorResponse "Недопустимая роль или неизвестное разрешение"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /roles/{role}/permissions [put]
+func swaggerUpdateRolePermissions() {}
+
+// @Summary Журнал изменений
+// @Description Получение записей журнала изменений с фильтрацией. Записи отсортированы от новых к старым
+// @Tags audit
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param actor_id query int false "ID пользователя, выполнившего изменение"
+// @Param action query string false "Действие (create, update, delete, change_role, reset_password, disable, enable)"
+// @Param entity_type query string false "Тип сущности (user, role_permissions, product, department, supplier, sale, supply)"
+// @Param entity_id query int false "ID сущности"
+// @Param request_id query string false "ID запроса (заголовок X-Request-ID)"
+// @Param start_date query string false "Начальная дата (YYYY-MM-DD)"
+// @Param end_date query string false "Конечная дата включительно (YYYY-MM-DD)"
+// @Param limit query int false "Количество записей (по умолчанию 100, максимум 1000)"
+// @Param offset query int false "Смещение"
+// @Success 200 {array} controllers.AuditLogResponse "Записи журнала"
+// @Failure 400 {object} controllers.ErrorResponse "Некорректные параметры фильтра"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /audit [get]
+func swaggerGetAuditLog() {}
+
 // @Summary Создание нового товара
-// @Description Добавление нового товара в базу данных
+// @Description Добавление нового товара в базу данных. Руководитель может создавать товары только в своих отделах
 // @Tags products
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param product body models.Product true "Данные товара"
-// @Success 201 {object} models.Product "Товар создан"
-// @Failure 400 {object} map[string]interface{} "Ошибка в данных запроса"
-// @Failure 401 {object} map[string]interface{} "Не авторизован"
-// @Failure 403 {object} map[string]interface{} "Доступ запрещен"
-// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Param product body controllers.ProductRequest true "Данные товара"
+// @Success 201 {object} controllers.ProductResponse "Товар создан"
+// @Failure 400 {object} controllers.ErrorResponse "Ошибка в данных запроса, отдел или поставщик не существует"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен или отдел вне зоны ответственности"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
 // @Router /products [post]
 func swaggerCreateProduct() {}
 
@@ -46,10 +242,11 @@ func swaggerCreateProduct() {}
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "ID товара"
-// @Success 200 {object} models.Product "Данные товара"
-// @Failure 400 {object} map[string]interface{} "Некорректный ID"
-// @Failure 401 {object} map[string]interface{} "Не авторизован"
-// @Failure 404 {object} map[string]interface{} "Товар не найден"
+// @Success 200 {object} controllers.ProductResponse "Данные товара"
+// @Header 200 {string} ETag "Версия записи"
+// @Failure 400 {object} controllers.ErrorResponse "Некорректный ID"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 404 {object} controllers.ErrorResponse "Товар не найден"
 // @Router /products/{id} [get]
 func swaggerGetProduct() {}
 
@@ -61,55 +258,142 @@ func swaggerGetProduct() {}
 // @Security BearerAuth
 // @Param department_id query int false "ID отдела"
 // @Param supplier_id query int false "ID поставщика"
-// @Success 200 {array} models.Product "Список товаров"
-// @Failure 401 {object} map[string]interface{} "Не авторизован"
-// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Success 200 {array} controllers.ProductResponse "Список товаров"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
 // @Router /products [get]
 func swaggerGetAllProducts() {}
 
 // @Summary Обновление товара
-// @Description Обновление информации о товаре
+// @Description Обновление информации о товаре. Руководитель может изменять товары только своих отделов
 // @Tags products
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "ID товара"
-// @Param product body models.Product true "Данные товара"
-// @Success 200 {object} models.Product "Товар обновлен"
-// @Failure 400 {object} map[string]interface{} "Ошибка в данных запроса"
-// @Failure 401 {object} map[string]interface{} "Не авторизован"
-// @Failure 403 {object} map[string]interface{} "Доступ запрещен"
-// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Param If-Match header string false "Версия записи из ETag, например \"3\""
+// @Param product body controllers.ProductRequest true "Данные товара"
+// @Success 200 {object} controllers.ProductResponse "Товар обновлен"
+// @Header 200 {string} ETag "Новая версия записи"
+// @Failure 400 {object} controllers.ErrorResponse "Ошибка в данных запроса, отдел или поставщик не существует"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен или отдел вне зоны ответственности"
+// @Failure 404 {object} controllers.ErrorResponse "Товар не найден"
+// @Failure 412 {object} controllers.ErrorResponse "Запись изменена другим пользователем"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
 // @Router /products/{id} [put]
 func swaggerUpdateProduct() {}
 
+// @Summary Частичное обновление товара
+// @Description Изменение отдельных полей в формате JSON merge patch (RFC 7386): переданные поля заменяются, остальные остаются прежними. Без If-Match изменение применяется к версии, прочитанной сервером
+// @Tags products
+// @Accept json,application/merge-patch+json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID товара"
+// @Param If-Match header string false "Версия записи из ETag, например \"3\""
+// @Param product body controllers.ProductRequest true "Изменяемые поля"
+// @Success 200 {object} controllers.ProductResponse "Товар обновлен"
+// @Header 200 {string} ETag "Новая версия записи"
+// @Failure 400 {object} controllers.ErrorResponse "Ошибка в данных запроса, отдел или поставщик не существует"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен или отдел вне зоны ответственности"
+// @Failure 404 {object} controllers.ErrorResponse "Товар не найден"
+// @Failure 412 {object} controllers.ErrorResponse "Запись изменена другим пользователем"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /products/{id} [patch]
+func swaggerPatchProduct() {}
+
+// @Summary Импорт товаров из CSV или XLSX
+// @Description Массовое создание товаров из файла. Первая строка — заголовок с именами полей API или русскими названиями колонок (название, отдел, поставщик, сорт, цена, остаток, минимальный остаток, срок годности, условия хранения, единица измерения, фасовка). Импорт выполняется по принципу «все или ничего»: при ошибке хотя бы в одной строке ничего не сохраняется, а ошибки возвращаются с путями rows[<номер строки>].<поле>. С dry_run=true файл только проверяется
+// @Tags products
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "Файл CSV (разделитель «,» или «;») или XLSX (первый лист)"
+// @Param dry_run query bool false "Только проверить файл"
+// @Param format query string false "Формат файла, если его нельзя определить по расширению" Enums(csv, xlsx)
+// @Success 200 {object} services.ImportReport "Результат проверки (dry_run)"
+// @Success 201 {object} services.ImportReport "Товаров импортированы"
+// @Failure 400 {object} controllers.ErrorResponse "Некорректный файл или ошибки в строках"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /products/import [post]
+func swaggerImportProducts() {}
+
+// @Summary Ценники товаров
+// @Description Ценники выбранных товаров в PDF на листах A4 (сетка 3×7, этикетки 63,5×38,1 мм): название, цена, цена за единицу измерения и внутренний штрихкод EAN-13 с префиксом 200
+// @Tags products
+// @Accept json
+// @Produce application/pdf
+// @Security BearerAuth
+// @Param request body controllers.LabelRequest true "Товары и число копий"
+// @Success 200 {file} file "Ценники в PDF"
+// @Failure 400 {object} controllers.ErrorResponse "Неверные данные или неизвестный товар"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /products/labels [post]
+func swaggerProductLabels() {}
+
 // @Summary Удаление товара
-// @Description Удаление товара из базы данных
+// @Description Перемещение товара в архив. Архивный товар скрыт из списков и недоступен для продажи, но остается в истории продаж и поставок. Доступно только в пределах своих отделов, если пользователь не администратор
 // @Tags products
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "ID товара"
 // @Success 200 {object} map[string]interface{} "Товар удален"
-// @Failure 400 {object} map[string]interface{} "Некорректный ID"
-// @Failure 401 {object} map[string]interface{} "Не авторизован"
-// @Failure 403 {object} map[string]interface{} "Доступ запрещен"
-// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Failure 400 {object} controllers.ErrorResponse "Некорректный ID"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен или отдел вне зоны ответственности"
+// @Failure 404 {object} controllers.ErrorResponse "Товар не найден"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
 // @Router /products/{id} [delete]
 func swaggerDeleteProduct() {}
 
+// @Summary Получение архивных товаров
+// @Description Получение списка товаров, перемещенных в архив
+// @Tags products
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} controllers.ProductResponse "Список архивных товаров"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /products/archived [get]
+func swaggerGetArchivedProducts() {}
+
+// @Summary Восстановление товара из архива
+// @Description Возврат архивного товара в активные записи
+// @Tags products
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID товара"
+// @Success 200 {object} controllers.ProductResponse "Запись восстановлена"
+// @Failure 400 {object} controllers.ErrorResponse "Некорректный ID, отдел или поставщик товара находится в архиве"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 404 {object} controllers.ErrorResponse "Товар не найден"
+// @Failure 409 {object} controllers.ErrorResponse "Запись не находится в архиве"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /products/{id}/restore [post]
+func swaggerRestoreProduct() {}
+
 // @Summary Создание отдела
 // @Description Добавление нового отдела
 // @Tags departments
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param department body models.Department true "Данные отдела"
-// @Success 201 {object} models.Department "Отдел создан"
-// @Failure 400 {object} map[string]interface{} "Ошибка в данных запроса"
-// @Failure 401 {object} map[string]interface{} "Не авторизован"
-// @Failure 403 {object} map[string]interface{} "Доступ запрещен"
-// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Param department body controllers.DepartmentRequest true "Данные отдела"
+// @Success 201 {object} controllers.DepartmentResponse "Отдел создан"
+// @Failure 400 {object} controllers.ErrorResponse "Ошибка в данных запроса"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
 // @Router /departments [post]
 func swaggerCreateDepartment() {}
 
@@ -120,10 +404,11 @@ func swaggerCreateDepartment() {}
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "ID отдела"
-// @Success 200 {object} models.Department "Данные отдела"
-// @Failure 400 {object} map[string]interface{} "Некорректный ID"
-// @Failure 401 {object} map[string]interface{} "Не авторизован"
-// @Failure 404 {object} map[string]interface{} "Отдел не найден"
+// @Success 200 {object} controllers.DepartmentResponse "Данные отдела"
+// @Header 200 {string} ETag "Версия записи"
+// @Failure 400 {object} controllers.ErrorResponse "Некорректный ID"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 404 {object} controllers.ErrorResponse "Отдел не найден"
 // @Router /departments/{id} [get]
 func swaggerGetDepartment() {}
 
@@ -133,55 +418,111 @@ func swaggerGetDepartment() {}
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Success 200 {array} models.Department "Список отделов"
-// @Failure 401 {object} map[string]interface{} "Не авторизован"
-// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Success 200 {array} controllers.DepartmentResponse "Список отделов"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
 // @Router /departments [get]
 func swaggerGetAllDepartments() {}
 
 // @Summary Обновление отдела
-// @Description Обновление информации об отделе
+// @Description Обновление информации об отделе. Руководитель может изменять только свои отделы и не может переназначать руководителя
 // @Tags departments
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "ID отдела"
-// @Param department body models.Department true "Данные отдела"
-// @Success 200 {object} models.Department "Отдел обновлен"
-// @Failure 400 {object} map[string]interface{} "Ошибка в данных запроса"
-// @Failure 401 {object} map[string]interface{} "Не авторизован"
-// @Failure 403 {object} map[string]interface{} "Доступ запрещен"
-// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Param If-Match header string false "Версия записи из ETag, например \"3\""
+// @Param department body controllers.DepartmentRequest true "Данные отдела"
+// @Success 200 {object} controllers.DepartmentResponse "Отдел обновлен"
+// @Header 200 {string} ETag "Новая версия записи"
+// @Failure 400 {object} controllers.ErrorResponse "Ошибка в данных запроса"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен или отдел вне зоны ответственности"
+// @Failure 404 {object} controllers.ErrorResponse "Отдел не найден"
+// @Failure 412 {object} controllers.ErrorResponse "Запись изменена другим пользователем"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
 // @Router /departments/{id} [put]
 func swaggerUpdateDepartment() {}
 
+// @Summary Частичное обновление отдела
+// @Description Изменение отдельных полей в формате JSON merge patch (RFC 7386): переданные поля заменяются, остальные остаются прежними. Без If-Match изменение применяется к версии, прочитанной сервером
+// @Tags departments
+// @Accept json,application/merge-patch+json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID отдела"
+// @Param If-Match header string false "Версия записи из ETag, например \"3\""
+// @Param department body controllers.DepartmentRequest true "Изменяемые поля"
+// @Success 200 {object} controllers.DepartmentResponse "Отдел обновлен"
+// @Header 200 {string} ETag "Новая версия записи"
+// @Failure 400 {object} controllers.ErrorResponse "Ошибка в данных запроса"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен или отдел вне зоны ответственности"
+// @Failure 404 {object} controllers.ErrorResponse "Отдел не найден"
+// @Failure 412 {object} controllers.ErrorResponse "Запись изменена другим пользователем"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /departments/{id} [patch]
+func swaggerPatchDepartment() {}
+
 // @Summary Удаление отдела
-// @Description Удаление отдела из базы данных
+// @Description Перемещение отдела в архив. Архивный отдел скрыт из списков, но остается в исторических записях
 // @Tags departments
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "ID отдела"
 // @Success 200 {object} map[string]interface{} "Отдел удален"
-// @Failure 400 {object} map[string]interface{} "Некорректный ID"
-// @Failure 401 {object} map[string]interface{} "Не авторизован"
-// @Failure 403 {object} map[string]interface{} "Доступ запрещен"
-// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Failure 400 {object} controllers.ErrorResponse "Некорректный ID"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 404 {object} controllers.ErrorResponse "Отдел не найден"
+// @Failure 409 {object} controllers.ErrorResponse "В отделе есть активные товары"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
 // @Router /departments/{id} [delete]
 func swaggerDeleteDepartment() {}
 
+// @Summary Получение архивных отделов
+// @Description Получение списка отделов, перемещенных в архив
+// @Tags departments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} controllers.DepartmentResponse "Список архивных отделов"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /departments/archived [get]
+func swaggerGetArchivedDepartments() {}
+
+// @Summary Восстановление отдела из архива
+// @Description Возврат архивного отдела в активные записи
+// @Tags departments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID отдела"
+// @Success 200 {object} controllers.DepartmentResponse "Запись восстановлена"
+// @Failure 400 {object} controllers.ErrorResponse "Некорректный ID"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 404 {object} controllers.ErrorResponse "Отдел не найден"
+// @Failure 409 {object} controllers.ErrorResponse "Запись не находится в архиве"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /departments/{id}/restore [post]
+func swaggerRestoreDepartment() {}
+
 // @Summary Создание поставщика
 // @Description Добавление нового поставщика
 // @Tags suppliers
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param supplier body models.Supplier true "Данные поставщика"
-// @Success 201 {object} models.Supplier "Поставщик создан"
-// @Failure 400 {object} map[string]interface{} "Ошибка в данных запроса"
-// @Failure 401 {object} map[string]interface{} "Не авторизован"
-// @Failure 403 {object} map[string]interface{} "Доступ запрещен"
-// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Param supplier body controllers.SupplierRequest true "Данные поставщика"
+// @Success 201 {object} controllers.SupplierResponse "Поставщик создан"
+// @Failure 400 {object} controllers.ErrorResponse "Ошибка в данных запроса"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
 // @Router /suppliers [post]
 func swaggerCreateSupplier() {}
 
@@ -192,10 +533,11 @@ func swaggerCreateSupplier() {}
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "ID поставщика"
-// @Success 200 {object} models.Supplier "Данные поставщика"
-// @Failure 400 {object} map[string]interface{} "Некорректный ID"
-// @Failure 401 {object} map[string]interface{} "Не авторизован"
-// @Failure 404 {object} map[string]interface{} "Поставщик не найден"
+// @Success 200 {object} controllers.SupplierResponse "Данные поставщика"
+// @Header 200 {string} ETag "Версия записи"
+// @Failure 400 {object} controllers.ErrorResponse "Некорректный ID"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 404 {object} controllers.ErrorResponse "Поставщик не найден"
 // @Router /suppliers/{id} [get]
 func swaggerGetSupplier() {}
 
@@ -205,9 +547,9 @@ func swaggerGetSupplier() {}
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Success 200 {array} models.Supplier "Список поставщиков"
-// @Failure 401 {object} map[string]interface{} "Не авторизован"
-// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Success 200 {array} controllers.SupplierResponse "Список поставщиков"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
 // @Router /suppliers [get]
 func swaggerGetAllSuppliers() {}
 
@@ -218,42 +560,117 @@ func swaggerGetAllSuppliers() {}
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "ID поставщика"
-// @Param supplier body models.Supplier true "Данные поставщика"
-// @Success 200 {object} models.Supplier "Поставщик обновлен"
-// @Failure 400 {object} map[string]interface{} "Ошибка в данных запроса"
-// @Failure 401 {object} map[string]interface{} "Не авторизован"
-// @Failure 403 {object} map[string]interface{} "Доступ запрещен"
-// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Param If-Match header string false "Версия записи из ETag, например \"3\""
+// @Param supplier body controllers.SupplierRequest true "Данные поставщика"
+// @Success 200 {object} controllers.SupplierResponse "Поставщик обновлен"
+// @Header 200 {string} ETag "Новая версия записи"
+// @Failure 400 {object} controllers.ErrorResponse "Ошибка в данных запроса"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 412 {object} controllers.ErrorResponse "Запись изменена другим пользователем"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
 // @Router /suppliers/{id} [put]
 func swaggerUpdateSupplier() {}
 
+// @Summary Частичное обновление поставщика
+// @Description Изменение отдельных полей в формате JSON merge patch (RFC 7386): переданные поля заменяются, остальные остаются прежними. Без If-Match изменение применяется к версии, прочитанной сервером
+// @Tags suppliers
+// @Accept json,application/merge-patch+json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID поставщика"
+// @Param If-Match header string false "Версия записи из ETag, например \"3\""
+// @Param supplier body controllers.SupplierRequest true "Изменяемые поля"
+// @Success 200 {object} controllers.SupplierResponse "Поставщик обновлен"
+// @Header 200 {string} ETag "Новая версия записи"
+// @Failure 400 {object} controllers.ErrorResponse "Ошибка в данных запроса"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 412 {object} controllers.ErrorResponse "Запись изменена другим пользователем"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /suppliers/{id} [patch]
+func swaggerPatchSupplier() {}
+
+// @Summary Импорт поставщиков из CSV или XLSX
+// @Description Массовое создание поставщиков из файла. Первая строка — заголовок с именами полей API или русскими названиями колонок (название, телефон, контактное лицо). Импорт выполняется по принципу «все или ничего»: при ошибке хотя бы в одной строке ничего не сохраняется, а ошибки возвращаются с путями rows[<номер строки>].<поле>. С dry_run=true файл только проверяется
+// @Tags suppliers
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "Файл CSV (разделитель «,» или «;») или XLSX (первый лист)"
+// @Param dry_run query bool false "Только проверить файл"
+// @Param format query string false "Формат файла, если его нельзя определить по расширению" Enums(csv, xlsx)
+// @Success 200 {object} services.ImportReport "Результат проверки (dry_run)"
+// @Success 201 {object} services.ImportReport "Поставщиков импортированы"
+// @Failure 400 {object} controllers.ErrorResponse "Некорректный файл или ошибки в строках"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /suppliers/import [post]
+func swaggerImportSuppliers() {}
+
 // @Summary Удаление поставщика
-// @Description Удаление поставщика из базы данных
+// @Description Перемещение поставщика в архив. Архивный поставщик скрыт из списков, но остается в исторических записях
 // @Tags suppliers
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "ID поставщика"
 // @Success 200 {object} map[string]interface{} "Поставщик удален"
-// @Failure 400 {object} map[string]interface{} "Некорректный ID"
-// @Failure 401 {object} map[string]interface{} "Не авторизован"
-// @Failure 403 {object} map[string]interface{} "Доступ запрещен"
-// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Failure 400 {object} controllers.ErrorResponse "Некорректный ID"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 404 {object} controllers.ErrorResponse "Поставщик не найден"
+// @Failure 409 {object} controllers.ErrorResponse "У поставщика есть активные товары"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
 // @Router /suppliers/{id} [delete]
 func swaggerDeleteSupplier() {}
 
+// @Summary Получение архивных поставщиков
+// @Description Получение списка поставщиков, перемещенных в архив
+// @Tags suppliers
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} controllers.SupplierResponse "Список архивных поставщиков"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /suppliers/archived [get]
+func swaggerGetArchivedSuppliers() {}
+
+// @Summary Восстановление поставщика из архива
+// @Description Возврат архивного поставщика в активные записи
+// @Tags suppliers
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID поставщика"
+// @Success 200 {object} controllers.SupplierResponse "Запись восстановлена"
+// @Failure 400 {object} controllers.ErrorResponse "Некорректный ID"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 404 {object} controllers.ErrorResponse "Поставщик не найден"
+// @Failure 409 {object} controllers.ErrorResponse "Запись не находится в архиве"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /suppliers/{id}/restore [post]
+func swaggerRestoreSupplier() {}
+
 // @Summary Создание продажи
-// @Description Регистрация новой продажи
+// @Description Регистрация новой продажи в открытой смене кассира. Стоимость считается по текущей цене товара, остаток уменьшается вместе с сохранением продажи. Оплата может состоять из нескольких платежей (наличные со сдачей, карта через платежный терминал); без payments продажа оплачивается наличными без сдачи. К продаже можно привязать покупателя (customer_id) и оплатить часть баллами (redeem_points) в пределах доли, разрешенной отделом; баллы начисляются по проценту отдела на сумму, оплаченную деньгами. Продажа передается в фискальный регистратор в фоне: ответ приходит с fiscal_status pending, а реквизиты чека появляются в продаже после регистрации; если регистратор недоступен, fiscal_status станет failed, и регистрация повторится автоматически
 // @Tags sales
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param sale body models.Sale true "Данные продажи"
-// @Success 201 {object} models.Sale "Продажа зарегистрирована"
-// @Failure 400 {object} map[string]interface{} "Ошибка в данных запроса"
-// @Failure 401 {object} map[string]interface{} "Не авторизован"
-// @Failure 403 {object} map[string]interface{} "Доступ запрещен"
-// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Param sale body controllers.SaleRequest true "Данные продажи"
+// @Success 201 {object} controllers.SaleResponse "Продажа зарегистрирована"
+// @Failure 400 {object} controllers.ErrorResponse "Ошибка в данных запроса или товар не существует"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 402 {object} controllers.ErrorResponse "Оплата картой отклонена"
+// @Failure 409 {object} controllers.ErrorResponse "Товар находится в архиве, остатка товара недостаточно, у кассира нет открытой смены или недостаточно баллов"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Failure 503 {object} controllers.ErrorResponse "Платежный терминал недоступен"
 // @Router /sales [post]
 func swaggerCreateSale() {}
 
@@ -264,14 +681,62 @@ func swaggerCreateSale() {}
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "ID продажи"
-// @Success 200 {object} models.Sale "Данные продажи"
-// @Failure 400 {object} map[string]interface{} "Некорректный ID"
-// @Failure 401 {object} map[string]interface{} "Не авторизован"
-// @Failure 403 {object} map[string]interface{} "Доступ запрещен"
-// @Failure 404 {object} map[string]interface{} "Продажа не найдена"
+// @Success 200 {object} controllers.SaleResponse "Данные продажи"
+// @Failure 400 {object} controllers.ErrorResponse "Некорректный ID"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 404 {object} controllers.ErrorResponse "Продажа не найдена"
 // @Router /sales/{id} [get]
 func swaggerGetSale() {}
 
+// @Summary Возврат товара по продаже
+// @Description Возврат части или всего товара продажи в открытой смене текущего пользователя. Деньги и баллы возвращаются теми же способами, которыми была оплачена продажа, пропорционально количеству: на карту — через платежный терминал по исходной операции, наличные — из кассы смены, баллы — на бонусный счет. Баллы, начисленные за возвращенный товар, списываются в пределах остатка счета. Товар возвращается на склад. Чек возврата прихода регистрируется в фоне: возврат возвращается со статусом fiscal_status pending, а при недоступности регистратора попытки повторяются
+// @Tags sales
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID продажи"
+// @Param return body controllers.SaleReturnRequest true "Количество и причина возврата"
+// @Success 201 {object} controllers.SaleReturnResponse "Возврат оформлен"
+// @Failure 400 {object} controllers.ErrorResponse "Ошибка в данных запроса или количество больше невозвращенного"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 404 {object} controllers.ErrorResponse "Продажа не найдена"
+// @Failure 409 {object} controllers.ErrorResponse "Нет открытой смены, в кассе недостаточно наличных или возврат на карту отклонен"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Failure 503 {object} controllers.ErrorResponse "Платежный терминал недоступен"
+// @Router /sales/{id}/returns [post]
+func swaggerCreateSaleReturn() {}
+
+// @Summary Возвраты по продаже
+// @Description Список возвратов продажи в порядке оформления
+// @Tags sales
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID продажи"
+// @Success 200 {array} controllers.SaleReturnResponse "Возвраты"
+// @Failure 400 {object} controllers.ErrorResponse "Некорректный ID"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 404 {object} controllers.ErrorResponse "Продажа не найдена"
+// @Router /sales/{id}/returns [get]
+func swaggerGetSaleReturns() {}
+
+// @Summary Товарный чек продажи
+// @Description Товарный чек продажи в PDF для ленты шириной 80 мм. Это не кассовый (фискальный) чек
+// @Tags sales
+// @Produce application/pdf
+// @Security BearerAuth
+// @Param id path int true "ID продажи"
+// @Success 200 {file} file "Чек в PDF"
+// @Failure 400 {object} controllers.ErrorResponse "Некорректный ID"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 404 {object} controllers.ErrorResponse "Продажа не найдена"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /sales/{id}/receipt [get]
+func swaggerSaleReceipt() {}
+
 // @Summary Получение списка продаж
 // @Description Получение списка всех продаж с возможностью фильтрации по дате
 // @Tags sales
@@ -280,28 +745,602 @@ func swaggerGetSale() {}
 // @Security BearerAuth
 // @Param start_date query string false "Начальная дата (YYYY-MM-DD)"
 // @Param end_date query string false "Конечная дата (YYYY-MM-DD)"
-// @Success 200 {array} models.Sale "Список продаж"
-// @Failure 401 {object} map[string]interface{} "Не авторизован"
-// @Failure 403 {object} map[string]interface{} "Доступ запрещен"
-// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Success 200 {array} controllers.SaleResponse "Список продаж"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
 // @Router /sales [get]
 func swaggerGetAllSales() {}
 
+// @Summary Регистрация покупателя
+// @Description Регистрация покупателя в программе лояльности. Телефон приводится к виду 7XXXXXXXXXX; если номер карты не указан, он выдается автоматически
+// @Tags customers
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param customer body controllers.CustomerRequest true "Данные покупателя"
+// @Success 201 {object} controllers.CustomerResponse "Покупатель зарегистрирован"
+// @Header 201 {string} ETag "Версия записи"
+// @Failure 400 {object} controllers.ErrorResponse "Ошибка в данных запроса или некорректный телефон"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 409 {object} controllers.ErrorResponse "Телефон или карта уже зарегистрированы"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /customers [post]
+func swaggerCreateCustomer() {}
+
+// @Summary Поиск покупателей
+// @Description Поиск по подстроке имени, телефона или номера карты. Телефон можно вводить в любом формате
+// @Tags customers
+// @Produce json
+// @Security BearerAuth
+// @Param q query string false "Имя, телефон или номер карты"
+// @Param limit query int false "Количество записей (по умолчанию 100, не больше 1000)"
+// @Param offset query int false "Смещение"
+// @Success 200 {array} controllers.CustomerResponse "Список покупателей"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /customers [get]
+func swaggerGetAllCustomers() {}
+
+// @Summary Получение покупателя по ID
+// @Tags customers
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID покупателя"
+// @Success 200 {object} controllers.CustomerResponse "Данные покупателя"
+// @Header 200 {string} ETag "Версия записи"
+// @Failure 400 {object} controllers.ErrorResponse "Некорректный ID"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 404 {object} controllers.ErrorResponse "Покупатель не найден"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /customers/{id} [get]
+func swaggerGetCustomerByID() {}
+
+// @Summary Обновление покупателя
+// @Description Обновление данных покупателя. Пустой номер карты оставляет прежний. Баланс баллов так не меняется
+// @Tags customers
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID покупателя"
+// @Param If-Match header string false "Версия записи из ETag, например \"3\""
+// @Param customer body controllers.CustomerRequest true "Данные покупателя"
+// @Success 200 {object} controllers.CustomerResponse "Покупатель обновлен"
+// @Header 200 {string} ETag "Новая версия записи"
+// @Failure 400 {object} controllers.ErrorResponse "Ошибка в данных запроса"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 404 {object} controllers.ErrorResponse "Покупатель не найден"
+// @Failure 409 {object} controllers.ErrorResponse "Телефон или карта уже зарегистрированы"
+// @Failure 412 {object} controllers.ErrorResponse "Запись изменена другим пользователем"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /customers/{id} [put]
+func swaggerUpdateCustomer() {}
+
+// @Summary Частичное обновление покупателя
+// @Description Изменение отдельных полей в формате JSON merge patch (RFC 7386): переданные поля заменяются, остальные остаются прежними. Без If-Match изменение применяется к версии, прочитанной сервером
+// @Tags customers
+// @Accept json,application/merge-patch+json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID покупателя"
+// @Param If-Match header string false "Версия записи из ETag, например \"3\""
+// @Param customer body controllers.CustomerRequest true "Изменяемые поля"
+// @Success 200 {object} controllers.CustomerResponse "Покупатель обновлен"
+// @Header 200 {string} ETag "Новая версия записи"
+// @Failure 400 {object} controllers.ErrorResponse "Ошибка в данных запроса"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 404 {object} controllers.ErrorResponse "Покупатель не найден"
+// @Failure 409 {object} controllers.ErrorResponse "Телефон или карта уже зарегистрированы"
+// @Failure 412 {object} controllers.ErrorResponse "Запись изменена другим пользователем"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /customers/{id} [patch]
+func swaggerPatchCustomer() {}
+
+// @Summary Баланс баллов
+// @Description Текущий баланс бонусного счета и сколько баллов покупатель получил и потратил за все время
+// @Tags customers
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID покупателя"
+// @Success 200 {object} controllers.LoyaltyBalanceResponse "Бонусный счет"
+// @Failure 400 {object} controllers.ErrorResponse "Некорректный ID"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 404 {object} controllers.ErrorResponse "Покупатель не найден"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /customers/{id}/points [get]
+func swaggerGetCustomerPoints() {}
+
+// @Summary История баллов
+// @Description Операции по бонусному счету: начисления за покупки, списания в оплату и ручные корректировки, новые первыми
+// @Tags customers
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID покупателя"
+// @Param limit query int false "Количество записей (по умолчанию 100, не больше 1000)"
+// @Param offset query int false "Смещение"
+// @Success 200 {array} controllers.LoyaltyTransactionResponse "Операции"
+// @Failure 400 {object} controllers.ErrorResponse "Некорректный ID"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 404 {object} controllers.ErrorResponse "Покупатель не найден"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /customers/{id}/points/history [get]
+func swaggerGetCustomerPointsHistory() {}
+
+// @Summary Корректировка баллов
+// @Description Ручное начисление (points > 0) или списание (points < 0) баллов с обязательным комментарием. Баланс не может стать отрицательным
+// @Tags customers
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID покупателя"
+// @Param adjustment body controllers.PointsAdjustmentRequest true "Количество баллов и причина"
+// @Success 201 {object} controllers.LoyaltyTransactionResponse "Операция проведена"
+// @Failure 400 {object} controllers.ErrorResponse "Ошибка в данных запроса"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 404 {object} controllers.ErrorResponse "Покупатель не найден"
+// @Failure 409 {object} controllers.ErrorResponse "Недостаточно баллов"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /customers/{id}/points [post]
+func swaggerAdjustCustomerPoints() {}
+
+// @Summary Открытие смены
+// @Description Открытие кассовой смены текущего пользователя на кассе с разменом на начало смены. У кассира и у кассы может быть только одна открытая смена
+// @Tags shifts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param shift body controllers.OpenShiftRequest true "Касса и размен"
+// @Success 201 {object} controllers.ShiftResponse "Смена открыта"
+// @Failure 400 {object} controllers.ErrorResponse "Ошибка в данных запроса"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 409 {object} controllers.ErrorResponse "У кассира или на кассе уже открыта смена"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /shifts [post]
+func swaggerOpenShift() {}
+
+// @Summary Текущая смена
+// @Description Открытая смена текущего пользователя
+// @Tags shifts
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} controllers.ShiftResponse "Открытая смена"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 404 {object} controllers.ErrorResponse "Нет открытой смены"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /shifts/current [get]
+func swaggerGetCurrentShift() {}
+
+// @Summary Список смен
+// @Description Смены всех кассиров, новые первыми
+// @Tags shifts
+// @Produce json
+// @Security BearerAuth
+// @Param cashier_id query int false "ID кассира"
+// @Param register query string false "Касса"
+// @Param open query bool false "Только открытые (true) или только закрытые (false)"
+// @Param limit query int false "Количество записей (по умолчанию 100, не больше 1000)"
+// @Param offset query int false "Смещение"
+// @Success 200 {array} controllers.ShiftResponse "Список смен"
+// @Failure 400 {object} controllers.ErrorResponse "Некорректные параметры"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /shifts [get]
+func swaggerGetAllShifts() {}
+
+// @Summary Получение смены по ID
+// @Description Кассир видит только свои смены, пользователь с разрешением shift.manage — любые
+// @Tags shifts
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID смены"
+// @Success 200 {object} controllers.ShiftResponse "Данные смены"
+// @Failure 400 {object} controllers.ErrorResponse "Некорректный ID"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен или смена другого кассира"
+// @Failure 404 {object} controllers.ErrorResponse "Смена не найдена"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /shifts/{id} [get]
+func swaggerGetShiftByID() {}
+
+// @Summary Внесение или изъятие наличных
+// @Description Движение наличных в открытой смене вне продаж. Изъять можно не больше ожидаемой суммы в кассе
+// @Tags shifts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID смены"
+// @Param movement body controllers.CashMovementRequest true "Вид, сумма и причина"
+// @Success 201 {object} controllers.CashMovementResponse "Движение записано"
+// @Failure 400 {object} controllers.ErrorResponse "Ошибка в данных запроса"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен или смена другого кассира"
+// @Failure 404 {object} controllers.ErrorResponse "Смена не найдена"
+// @Failure 409 {object} controllers.ErrorResponse "Смена закрыта или недостаточно наличных"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /shifts/{id}/cash [post]
+func swaggerAddCashMovement() {}
+
+// @Summary Отчет по смене
+// @Description X-отчет открытой смены или Z-отчет закрытой: продажи, оплаты по способам, возвраты и выплаты по ним, внесения, изъятия, ожидаемые и пересчитанные наличные. Возвраты наличными уменьшают ожидаемую сумму
+// @Tags shifts
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID смены"
+// @Success 200 {object} controllers.ShiftReportResponse "Отчет"
+// @Failure 400 {object} controllers.ErrorResponse "Некорректный ID"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен или смена другого кассира"
+// @Failure 404 {object} controllers.ErrorResponse "Смена не найдена"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /shifts/{id}/report [get]
+func swaggerGetShiftReport() {}
+
+// @Summary Закрытие смены
+// @Description Закрытие смены с пересчитанной суммой наличных. Возвращает Z-отчет с расхождением (counted_cash − expected_cash)
+// @Tags shifts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID смены"
+// @Param shift body controllers.CloseShiftRequest true "Пересчитанные наличные"
+// @Success 200 {object} controllers.ShiftReportResponse "Z-отчет"
+// @Failure 400 {object} controllers.ErrorResponse "Ошибка в данных запроса"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен или смена другого кассира"
+// @Failure 404 {object} controllers.ErrorResponse "Смена не найдена"
+// @Failure 409 {object} controllers.ErrorResponse "Смена уже закрыта"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /shifts/{id}/close [post]
+func swaggerCloseShift() {}
+
 // @Summary Создание поставки
-// @Description Регистрация новой поставки с товарами
+// @Description Регистрация новой поставки с товарами. Все позиции должны относиться к отделам, доступным пользователю
 // @Tags supplies
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param supply body object true "Данные поставки с товарами"
-// @Success 201 {object} models.Supply "Поставка зарегистрирована"
-// @Failure 400 {object} map[string]interface{} "Ошибка в данных запроса"
-// @Failure 401 {object} map[string]interface{} "Не авторизован"
-// @Failure 403 {object} map[string]interface{} "Доступ запрещен"
-// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Param supply body controllers.SupplyRequest true "Данные поставки с товарами"
+// @Success 201 {object} controllers.SupplyResponse "Поставка зарегистрирована"
+// @Failure 400 {object} controllers.ErrorResponse "Ошибка в данных запроса, товар или поставщик не существует"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен или отдел вне зоны ответственности"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
 // @Router /supplies [post]
 func swaggerCreateSupply() {}
 
+// @Summary Регистрация счета поставщика
+// @Description Регистрирует счет поставщика за поставку. На поставку можно выставить один счет. Без суммы берется стоимость поставки, без даты — сегодняшний день, без срока оплаты — дата счета плюс отсрочка платежа поставщика
+// @Tags payables
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID поставки"
+// @Param invoice body controllers.InvoiceRequest true "Данные счета"
+// @Success 201 {object} controllers.InvoiceResponse "Счет зарегистрирован"
+// @Failure 400 {object} controllers.ErrorResponse "Ошибка в данных запроса"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 404 {object} controllers.ErrorResponse "Поставка не найдена"
+// @Failure 409 {object} controllers.ErrorResponse "По поставке уже есть счет или номер счета занят"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /supplies/{id}/invoice [post]
+func swaggerRegisterInvoice() {}
+
+// @Summary Список счетов поставщиков
+// @Description Счета поставщиков, ближайшие по сроку оплаты первыми
+// @Tags payables
+// @Produce json
+// @Security BearerAuth
+// @Param supplier_id query int false "ID поставщика"
+// @Param status query string false "Статус счета" Enums(open, partial, paid)
+// @Param overdue query bool false "Только просроченные"
+// @Param limit query int false "Количество записей (по умолчанию 100, максимум 1000)"
+// @Param offset query int false "Смещение"
+// @Success 200 {array} controllers.InvoiceResponse "Список счетов"
+// @Failure 400 {object} controllers.ErrorResponse "Некорректные параметры"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /invoices [get]
+func swaggerGetInvoices() {}
+
+// @Summary Счет поставщика
+// @Description Счет поставщика с историей оплат
+// @Tags payables
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID счета"
+// @Success 200 {object} controllers.InvoiceResponse "Счет"
+// @Failure 400 {object} controllers.ErrorResponse "Некорректный ID"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 404 {object} controllers.ErrorResponse "Счет не найден"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /invoices/{id} [get]
+func swaggerGetInvoice() {}
+
+// @Summary Оплата счета поставщика
+// @Description Записывает оплату счета, в том числе частичную. Сумма оплаты не может превышать остаток по счету. Возвращает счет после оплаты
+// @Tags payables
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID счета"
+// @Param payment body controllers.InvoicePaymentRequest true "Данные оплаты"
+// @Success 201 {object} controllers.InvoiceResponse "Оплата записана"
+// @Failure 400 {object} controllers.ErrorResponse "Ошибка в данных запроса или оплата больше остатка"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 404 {object} controllers.ErrorResponse "Счет не найден"
+// @Failure 409 {object} controllers.ErrorResponse "Счет уже оплачен"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /invoices/{id}/payments [post]
+func swaggerPayInvoice() {}
+
+// @Summary Задолженность перед поставщиками по срокам
+// @Description Неоплаченные остатки счетов на дату по поставщикам и в целом, разбитые по срокам просрочки: срок не наступил, 1–30, 31–60, 61–90 и больше 90 дней. Поставщики идут по убыванию задолженности
+// @Tags payables
+// @Produce json
+// @Security BearerAuth
+// @Param date query string false "Дата (YYYY-MM-DD), по умолчанию сегодня"
+// @Success 200 {object} controllers.PayablesAgingResponse "Задолженность по срокам"
+// @Failure 400 {object} controllers.ErrorResponse "Некорректная дата"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /payables/aging [get]
+func swaggerGetPayablesAging() {}
+
+// @Summary Просроченные счета поставщиков
+// @Description Неоплаченные счета, срок оплаты которых прошел к дате, начиная с самых старых
+// @Tags payables
+// @Produce json
+// @Security BearerAuth
+// @Param date query string false "Дата (YYYY-MM-DD), по умолчанию сегодня"
+// @Success 200 {array} controllers.InvoiceResponse "Просроченные счета"
+// @Failure 400 {object} controllers.ErrorResponse "Некорректная дата"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /payables/overdue [get]
+func swaggerGetOverdueInvoices() {}
+
+// @Summary Оповещения о просроченных счетах
+// @Description Неоплаченные счета, о просрочке которых сервер оповестил, но оповещение еще не подтверждено, начиная с самых старых. Счет пропадает из списка после подтверждения или полной оплаты
+// @Tags payables
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} controllers.InvoiceResponse "Неподтвержденные оповещения"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /payables/alerts [get]
+func swaggerGetOverdueAlerts() {}
+
+// @Summary Подтверждение оповещения о просрочке
+// @Description Отмечает оповещение о просрочке счета как прочитанное: счет пропадает из /payables/alerts
+// @Tags payables
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID счета"
+// @Success 200 {object} controllers.InvoiceResponse "Оповещение подтверждено"
+// @Failure 400 {object} controllers.ErrorResponse "Некорректный ID"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 404 {object} controllers.ErrorResponse "Счет не найден"
+// @Failure 409 {object} controllers.ErrorResponse "Оповещения о просрочке не было или оно уже подтверждено"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /invoices/{id}/acknowledge [post]
+func swaggerAcknowledgeOverdue() {}
+
+// @Summary Составление акта списания
+// @Description Составляет акт списания товаров отдела: бой, порча, кража, дегустация или прочее (для прочего нужен комментарий). Все товары должны относиться к отделу акта, списать можно не больше текущего остатка. Акт создается на утверждение, остатки пока не меняются
+// @Tags write-offs
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param write_off body controllers.WriteOffRequest true "Данные акта"
+// @Success 201 {object} controllers.WriteOffResponse "Акт составлен"
+// @Failure 400 {object} controllers.ErrorResponse "Ошибка в данных запроса"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен или отдел вне зоны ответственности"
+// @Failure 409 {object} controllers.ErrorResponse "Товар в архиве или остатка недостаточно"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /write-offs [post]
+func swaggerCreateWriteOff() {}
+
+// @Summary Список актов списания
+// @Description Акты списания отделов, доступных пользователю, новые первыми
+// @Tags write-offs
+// @Produce json
+// @Security BearerAuth
+// @Param department_id query int false "ID отдела"
+// @Param status query string false "Статус акта" Enums(pending, approved, rejected)
+// @Param reason query string false "Причина списания" Enums(breakage, spoilage, theft, sample, other)
+// @Param limit query int false "Количество записей (по умолчанию 100, максимум 1000)"
+// @Param offset query int false "Смещение"
+// @Success 200 {array} controllers.WriteOffResponse "Список актов"
+// @Failure 400 {object} controllers.ErrorResponse "Некорректные параметры"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /write-offs [get]
+func swaggerGetWriteOffs() {}
+
+// @Summary Акт списания
+// @Tags write-offs
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID акта"
+// @Success 200 {object} controllers.WriteOffResponse "Акт списания"
+// @Failure 400 {object} controllers.ErrorResponse "Некорректный ID"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен или отдел вне зоны ответственности"
+// @Failure 404 {object} controllers.ErrorResponse "Акт не найден"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /write-offs/{id} [get]
+func swaggerGetWriteOff() {}
+
+// @Summary Утверждение акта списания
+// @Description Утверждает акт и уменьшает остатки товаров. Себестоимость строк считается по средней себестоимости текущего остатка (FIFO по поставкам). Если остаток уменьшился после составления акта и его не хватает, акт не утверждается. Утвердить собственный акт нельзя
+// @Tags write-offs
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID акта"
+// @Success 200 {object} controllers.WriteOffResponse "Акт утвержден"
+// @Failure 400 {object} controllers.ErrorResponse "Некорректный ID"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен, отдел вне зоны ответственности или акт составлен самим пользователем"
+// @Failure 404 {object} controllers.ErrorResponse "Акт не найден"
+// @Failure 409 {object} controllers.ErrorResponse "Акт уже рассмотрен или остатка недостаточно"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /write-offs/{id}/approve [post]
+func swaggerApproveWriteOff() {}
+
+// @Summary Отклонение акта списания
+// @Description Отклоняет акт с указанием причины, остатки не меняются
+// @Tags write-offs
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID акта"
+// @Param rejection body controllers.WriteOffRejectRequest true "Причина отклонения"
+// @Success 200 {object} controllers.WriteOffResponse "Акт отклонен"
+// @Failure 400 {object} controllers.ErrorResponse "Ошибка в данных запроса"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен или отдел вне зоны ответственности"
+// @Failure 404 {object} controllers.ErrorResponse "Акт не найден"
+// @Failure 409 {object} controllers.ErrorResponse "Акт уже рассмотрен"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /write-offs/{id}/reject [post]
+func swaggerRejectWriteOff() {}
+
+// @Summary Поставщики товара
+// @Description Поставщики, у которых можно заказать товар, с ценами на дату. Сначала самые дешевые за единицу, поставщики без цены в конце
+// @Tags catalog
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID товара"
+// @Param date query string false "Дата цен (YYYY-MM-DD), по умолчанию сегодня"
+// @Success 200 {array} controllers.SupplierOfferResponse "Предложения поставщиков"
+// @Failure 400 {object} controllers.ErrorResponse "Некорректный ID или дата"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 404 {object} controllers.ErrorResponse "Товар не найден"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /products/{id}/suppliers [get]
+func swaggerGetProductSuppliers() {}
+
+// @Summary Связь товара с поставщиком
+// @Description Добавление поставщика в каталог товара или изменение артикула. preferred=true делает поставщика основным (он же supplier_id товара); снять признак можно только назначив основным другого поставщика
+// @Tags catalog
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID товара"
+// @Param supplier_id path int true "ID поставщика"
+// @Param link body controllers.ProductSupplierRequest true "Артикул и признак основного поставщика"
+// @Success 200 {object} controllers.ProductSupplierResponse "Связь сохранена"
+// @Failure 400 {object} controllers.ErrorResponse "Ошибка валидации"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен или отдел вне зоны ответственности"
+// @Failure 404 {object} controllers.ErrorResponse "Товар или поставщик не найден"
+// @Failure 409 {object} controllers.ErrorResponse "Нельзя снять признак основного поставщика"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /products/{id}/suppliers/{supplier_id} [put]
+func swaggerSaveProductSupplier() {}
+
+// @Summary Удаление товара из каталога поставщика
+// @Description Поставщик больше не предлагает товар. История цен сохраняется. Основного поставщика убрать нельзя
+// @Tags catalog
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID товара"
+// @Param supplier_id path int true "ID поставщика"
+// @Success 200 {object} map[string]interface{} "Поставщик убран из каталога товара"
+// @Failure 400 {object} controllers.ErrorResponse "Некорректный ID"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен или отдел вне зоны ответственности"
+// @Failure 404 {object} controllers.ErrorResponse "Товар не найден или не связан с поставщиком"
+// @Failure 409 {object} controllers.ErrorResponse "Поставщик основной для товара"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /products/{id}/suppliers/{supplier_id} [delete]
+func swaggerDeleteProductSupplier() {}
+
+// @Summary Прайс-лист поставщика
+// @Description Каталог поставщика с ценами, действующими на дату. price равен null, если цена не задана
+// @Tags catalog
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID поставщика"
+// @Param date query string false "Дата цен (YYYY-MM-DD), по умолчанию сегодня"
+// @Success 200 {array} controllers.PriceListItemResponse "Прайс-лист"
+// @Failure 400 {object} controllers.ErrorResponse "Некорректный ID или дата"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 404 {object} controllers.ErrorResponse "Поставщик не найден"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /suppliers/{id}/prices [get]
+func swaggerGetPriceList() {}
+
+// @Summary Загрузка прайс-листа
+// @Description Цены поставщика (за упаковку из pack_size единиц), действующие с valid_from до следующего прайс-листа. Товары добавляются в каталог поставщика; цена с той же датой заменяется
+// @Tags catalog
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID поставщика"
+// @Param prices body controllers.PriceListRequest true "Прайс-лист"
+// @Success 201 {array} controllers.SupplierPriceResponse "Сохраненные цены"
+// @Failure 400 {object} controllers.ErrorResponse "Ошибка валидации или неизвестный товар"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен или отдел вне зоны ответственности"
+// @Failure 404 {object} controllers.ErrorResponse "Поставщик не найден"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /suppliers/{id}/prices [post]
+func swaggerUploadPriceList() {}
+
+// @Summary История цен товара у поставщика
+// @Tags catalog
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID поставщика"
+// @Param product_id path int true "ID товара"
+// @Success 200 {array} controllers.SupplierPriceResponse "Цены, новые первыми"
+// @Failure 400 {object} controllers.ErrorResponse "Некорректный ID"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 404 {object} controllers.ErrorResponse "Товар не связан с поставщиком"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /suppliers/{id}/prices/{product_id} [get]
+func swaggerGetPriceHistory() {}
+
+// @Summary Сравнение цен поставщиков
+// @Description Для подготовки поставки: цены всех поставщиков по товарам на дату, основной и самый дешевый поставщик и экономия на единице товара
+// @Tags supplies
+// @Produce json
+// @Security BearerAuth
+// @Param product_id query []int true "ID товаров" collectionFormat(multi)
+// @Param date query string false "Дата цен (YYYY-MM-DD), по умолчанию сегодня"
+// @Success 200 {array} controllers.PriceComparisonResponse "Сравнение по товарам"
+// @Failure 400 {object} controllers.ErrorResponse "Некорректные параметры или неизвестный товар"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /supplies/price-comparison [get]
+func swaggerComparePrices() {}
+
 // @Summary Получение поставки по ID
 // @Description Получение информации о поставке по ID
 // @Tags supplies
@@ -309,52 +1348,272 @@ func swaggerCreateSupply() {}
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "ID поставки"
-// @Success 200 {object} models.Supply "Данные поставки"
-// @Failure 400 {object} map[string]interface{} "Некорректный ID"
-// @Failure 401 {object} map[string]interface{} "Не авторизован"
-// @Failure 403 {object} map[string]interface{} "Доступ запрещен"
-// @Failure 404 {object} map[string]interface{} "Поставка не найдена"
+// @Success 200 {object} controllers.SupplyResponse "Данные поставки"
+// @Failure 400 {object} controllers.ErrorResponse "Некорректный ID"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 404 {object} controllers.ErrorResponse "Поставка не найдена"
 // @Router /supplies/{id} [get]
 func swaggerGetSupply() {}
 
+// @Summary Приходная накладная поставки
+// @Description Приходная накладная поставки в PDF (A4) с позициями, поставщиком и принявшим поставку пользователем. Итог считается по позициям
+// @Tags supplies
+// @Produce application/pdf
+// @Security BearerAuth
+// @Param id path int true "ID поставки"
+// @Success 200 {file} file "Накладная в PDF"
+// @Failure 400 {object} controllers.ErrorResponse "Некорректный ID"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 404 {object} controllers.ErrorResponse "Поставка не найдена"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /supplies/{id}/waybill [get]
+func swaggerSupplyWaybill() {}
+
 // @Summary Получение списка поставок
 // @Description Получение списка всех поставок
 // @Tags supplies
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Success 200 {array} models.Supply "Список поставок"
-// @Failure 401 {object} map[string]interface{} "Не авторизован"
-// @Failure 403 {object} map[string]interface{} "Доступ запрещен"
-// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Success 200 {array} controllers.SupplyResponse "Список поставок"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
 // @Router /supplies [get]
 func swaggerGetAllSupplies() {}
 
 // @Summary Получение товаров с низким запасом
-// @Description Получение списка товаров, количество которых меньше или равно минимальному порогу
+// @Description Получение списка товаров, количество которых меньше или равно минимальному порогу, в отделах, доступных пользователю
 // @Tags analytics
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Success 200 {array} models.Product "Список товаров с низким запасом"
-// @Failure 401 {object} map[string]interface{} "Не авторизован"
-// @Failure 403 {object} map[string]interface{} "Доступ запрещен"
-// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Success 200 {array} controllers.ProductResponse "Список товаров с низким запасом"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
 // @Router /analytics/low-stock [get]
 func swaggerGetLowStockProducts() {}
 
 // @Summary Аналитика продаж по периоду
-// @Description Получение аналитики продаж за указанный период
+// @Description Аналитика продаж за период по товарам отделов, доступных пользователю: количество чеков, выручка, средний чек и среднее количество товара в чеке, сравнение с предыдущим периодом той же длины, разбивка оплат по способам (payment_methods), продажи в разрезе group_by, лучшие и худшие товары по выручке. В разрезах по времени пропущенные дни, недели, месяцы и часы заполняются нулями; hour — распределение по часам суток за весь период. В худшие товары попадают и товары без продаж за период
 // @Tags analytics
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param start_date query string true "Начальная дата (YYYY-MM-DD)"
-// @Param end_date query string true "Конечная дата (YYYY-MM-DD)"
-// @Success 200 {object} map[string]interface{} "Аналитика продаж"
-// @Failure 400 {object} map[string]interface{} "Отсутствуют обязательные параметры"
-// @Failure 401 {object} map[string]interface{} "Не авторизован"
-// @Failure 403 {object} map[string]interface{} "Доступ запрещен"
-// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Param end_date query string true "Конечная дата включительно (YYYY-MM-DD)"
+// @Param group_by query string false "Разрез, по умолчанию day" Enums(day, week, month, hour, department, cashier, product)
+// @Param top query int false "Сколько лучших и худших товаров вернуть (по умолчанию 10, максимум 100)"
+// @Success 200 {object} controllers.SalesAnalyticsResponse "Аналитика продаж"
+// @Failure 400 {object} controllers.ErrorResponse "Отсутствуют или некорректны параметры"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
 // @Router /analytics/sales [get]
 func swaggerGetSalesByPeriod() {}
+
+// @Summary Аналитика по покупателям
+// @Description Выручка за период по товарам отделов, доступных пользователю: доля продаж с картой покупателя, повторные продажи (покупателю, у которого уже была более ранняя покупка), начисленные и списанные баллы, десять покупателей с наибольшей выручкой
+// @Tags analytics
+// @Produce json
+// @Security BearerAuth
+// @Param start_date query string true "Начальная дата (YYYY-MM-DD)"
+// @Param end_date query string true "Конечная дата (YYYY-MM-DD), включительно"
+// @Success 200 {object} controllers.CustomerAnalyticsResponse "Аналитика по покупателям"
+// @Failure 400 {object} controllers.ErrorResponse "Отсутствуют или некорректны параметры"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /analytics/customers [get]
+func swaggerGetCustomerAnalytics() {}
+
+// @Summary Показатели поставщика
+// @Description Оценка поставщика по истории поставок за период: частота поставок, расходы по месяцам, изменение закупочных цен по товарам (сначала самые подорожавшие или подешевевшие) и доля товара, привезенного на исходе срока годности. Учитываются только позиции, для которых при приемке указан срок годности
+// @Tags analytics
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID поставщика"
+// @Param start_date query string true "Начальная дата (YYYY-MM-DD)"
+// @Param end_date query string true "Конечная дата включительно (YYYY-MM-DD)"
+// @Param near_expiry_days query int false "Порог остатка срока годности в днях, по умолчанию 7"
+// @Success 200 {object} controllers.SupplierScorecardResponse "Показатели поставщика"
+// @Failure 400 {object} controllers.ErrorResponse "Некорректный ID или период"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 404 {object} controllers.ErrorResponse "Поставщик не найден"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /analytics/suppliers/{id} [get]
+func swaggerGetSupplierScorecard() {}
+
+// @Summary ABC/XYZ-анализ товаров
+// @Description Классы активных товаров отделов, доступных пользователю, за окно в несколько недель, заканчивающееся датой. ABC — по вкладу в выручку: A дают первые 80% выручки, B — следующие до 95%, остальные и товары без продаж — C. XYZ — по коэффициенту вариации недельных продаж: X — до 0.25, Y — до 0.5, выше и без продаж — Z. Товар, впервые проданный внутри окна, оценивается с недели первой продажи, но не меньше чем по 4 неделям. Пороги задаются переменными окружения. Товары идут по убыванию выручки
+// @Tags analytics
+// @Produce json
+// @Security BearerAuth
+// @Param date query string false "Последний день окна (YYYY-MM-DD), по умолчанию сегодня"
+// @Param weeks query int false "Окно анализа в неделях, от 4 до 104, по умолчанию 13"
+// @Success 200 {object} controllers.ABCXYZResponse "Матрица ABC/XYZ и классы товаров"
+// @Failure 400 {object} controllers.ErrorResponse "Некорректная дата или окно"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /analytics/abc-xyz [get]
+func swaggerGetABCXYZ() {}
+
+// @Summary Запись классов ABC в сорт товаров
+// @Description Проводит ABC/XYZ-анализ с теми же параметрами и записывает класс ABC в сорт (grade) товаров, у которых он отличается. Классы считаются по всем товарам магазина, как при фоновом пересчете, а меняется сорт только товаров отделов, доступных пользователю. Каждое изменение попадает в журнал аудита
+// @Tags analytics
+// @Produce json
+// @Security BearerAuth
+// @Param date query string false "Последний день окна (YYYY-MM-DD), по умолчанию сегодня"
+// @Param weeks query int false "Окно анализа в неделях, от 4 до 104, по умолчанию 13"
+// @Success 200 {object} controllers.ABCXYZApplyResponse "Измененные сорта"
+// @Failure 400 {object} controllers.ErrorResponse "Некорректная дата или окно"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /analytics/abc-xyz/apply [post]
+func swaggerApplyABCXYZ() {}
+
+// @Summary Прогноз спроса на товар
+// @Description Прогноз дневных продаж товара на несколько дней начиная с сегодняшнего двумя методами: скользящее среднее за 28 последних дней и экспоненциальное сглаживание с недельной сезонностью (параметры подбираются по истории). Если товар впервые продан внутри окна истории, история начинается с первой продажи. Для оценки точности каждый метод прогнозирует последние 14 дней истории по данным до них (backtest, нужно не меньше 28 дней истории); recommended — метод с меньшей ошибкой
+// @Tags analytics
+// @Produce json
+// @Security BearerAuth
+// @Param product_id query int true "ID товара"
+// @Param days query int false "Горизонт прогноза в днях, от 1 до 90, по умолчанию 14"
+// @Param history_days query int false "История продаж в днях, от 28 до 730, по умолчанию 84"
+// @Success 200 {object} controllers.ForecastResponse "Прогноз спроса"
+// @Failure 400 {object} controllers.ErrorResponse "Некорректные параметры"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен или отдел вне зоны ответственности"
+// @Failure 404 {object} controllers.ErrorResponse "Товар не найден"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /analytics/forecast [get]
+func swaggerGetForecast() {}
+
+// @Summary Оценка товарных запасов
+// @Description Стоимость остатков на конец дня по себестоимости и в розничных ценах с группировкой по отделам или поставщикам. Остаток на прошлую дату восстанавливается от текущего по продажам, поставкам и утвержденным списаниям после нее; ручные правки остатка не учитываются. Себестоимость считается по FIFO: остаток состоит из последних поставок, а если их не хватает — оценивается по цене самой ранней. Количество товаров без единой поставки указывается в uncosted_quantity. Розничная стоимость — по текущей цене товара
+// @Tags analytics
+// @Produce json
+// @Security BearerAuth
+// @Param date query string false "Дата (YYYY-MM-DD), по умолчанию сегодня"
+// @Param group_by query string false "Группировка" Enums(department, supplier) default(department)
+// @Success 200 {object} controllers.StockValuationResponse "Оценка запасов"
+// @Failure 400 {object} controllers.ErrorResponse "Некорректная дата или группировка"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /analytics/inventory/valuation [get]
+func swaggerGetStockValuation() {}
+
+// @Summary Оборачиваемость запасов
+// @Description Оборачиваемость запаса по товарам за период в единицах товара: продажи к среднему остатку (среднее остатков на начало и конец периода) и на сколько дней продаж хватает среднего остатка. Товары без остатка и продаж за период не выводятся; сначала идут самые медленные
+// @Tags analytics
+// @Produce json
+// @Security BearerAuth
+// @Param start_date query string true "Начало периода (YYYY-MM-DD)"
+// @Param end_date query string true "Конец периода включительно (YYYY-MM-DD)"
+// @Success 200 {object} controllers.InventoryTurnoverResponse "Оборачиваемость запасов"
+// @Failure 400 {object} controllers.ErrorResponse "Некорректный период"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /analytics/inventory/turnover [get]
+func swaggerGetInventoryTurnover() {}
+
+// @Summary Неликвидные остатки
+// @Description Товары с ненулевым остатком, не продававшиеся заданное число дней, со стоимостью остатка. Сначала идут остатки с наибольшей себестоимостью
+// @Tags analytics
+// @Produce json
+// @Security BearerAuth
+// @Param days query int false "Дней без продаж, от 1 до 365, по умолчанию 30"
+// @Success 200 {object} controllers.DeadStockResponse "Неликвидные остатки"
+// @Failure 400 {object} controllers.ErrorResponse "Некорректный срок"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /analytics/inventory/dead-stock [get]
+func swaggerGetDeadStock() {}
+
+// @Summary Отчет о списаниях
+// @Description Утвержденные за период списания в единицах товара и по себестоимости: по отделам с разбивкой по причинам и по причинам в целом. Списание относится к дню утверждения акта
+// @Tags analytics
+// @Produce json
+// @Security BearerAuth
+// @Param start_date query string true "Начало периода (YYYY-MM-DD)"
+// @Param end_date query string true "Конец периода включительно (YYYY-MM-DD)"
+// @Success 200 {object} controllers.ShrinkageResponse "Отчет о списаниях"
+// @Failure 400 {object} controllers.ErrorResponse "Некорректный период"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /analytics/shrinkage [get]
+func swaggerGetShrinkage() {}
+
+// @Summary Выгрузка продаж
+// @Description Выгрузка продаж за период по товарам отделов, доступных пользователю. CSV выгружается с BOM, разделителем «;», десятичной запятой и датами ДД.ММ.ГГГГ; XLSX — первый лист с типизированными ячейками. Файл передается потоково
+// @Tags export
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Security BearerAuth
+// @Param start_date query string true "Начальная дата (YYYY-MM-DD)"
+// @Param end_date query string true "Конечная дата (YYYY-MM-DD), включительно"
+// @Param format query string false "Формат файла" Enums(csv, xlsx) default(csv)
+// @Success 200 {file} file "Файл выгрузки"
+// @Failure 400 {object} controllers.ErrorResponse "Отсутствуют или некорректны параметры"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /export/sales [get]
+func swaggerExportSales() {}
+
+// @Summary Выгрузка поставок
+// @Description Выгрузка поставок за период, по строке на каждую позицию поставки. CSV выгружается с BOM, разделителем «;», десятичной запятой и датами ДД.ММ.ГГГГ; XLSX — первый лист с типизированными ячейками. Файл передается потоково
+// @Tags export
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Security BearerAuth
+// @Param start_date query string true "Начальная дата (YYYY-MM-DD)"
+// @Param end_date query string true "Конечная дата (YYYY-MM-DD), включительно"
+// @Param format query string false "Формат файла" Enums(csv, xlsx) default(csv)
+// @Success 200 {file} file "Файл выгрузки"
+// @Failure 400 {object} controllers.ErrorResponse "Отсутствуют или некорректны параметры"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /export/supplies [get]
+func swaggerExportSupplies() {}
+
+// @Summary Выгрузка остатков
+// @Description Выгрузка текущих остатков товаров доступных отделов и их стоимости по цене продажи. CSV выгружается с BOM, разделителем «;», десятичной запятой и датами ДД.ММ.ГГГГ; XLSX — первый лист с типизированными ячейками. Файл передается потоково
+// @Tags export
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Security BearerAuth
+// @Param format query string false "Формат файла" Enums(csv, xlsx) default(csv)
+// @Success 200 {file} file "Файл выгрузки"
+// @Failure 400 {object} controllers.ErrorResponse "Неподдерживаемый формат"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /export/stock [get]
+func swaggerExportStock() {}
+
+// @Summary Выгрузка товаров с низким остатком
+// @Description Выгрузка товаров доступных отделов, остаток которых не выше минимального. CSV выгружается с BOM, разделителем «;», десятичной запятой и датами ДД.ММ.ГГГГ; XLSX — первый лист с типизированными ячейками. Файл передается потоково
+// @Tags export
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Security BearerAuth
+// @Param format query string false "Формат файла" Enums(csv, xlsx) default(csv)
+// @Success 200 {file} file "Файл выгрузки"
+// @Failure 400 {object} controllers.ErrorResponse "Неподдерживаемый формат"
+// @Failure 401 {object} controllers.ErrorResponse "Не авторизован"
+// @Failure 403 {object} controllers.ErrorResponse "Доступ запрещен"
+// @Failure 500 {object} controllers.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /export/low-stock [get]
+func swaggerExportLowStock() {}
@@ -0,0 +1,190 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+
+	"grocery-store-api/models"
+	"grocery-store-api/services/errs"
+)
+
+// RegisterValidators подключает к валидатору gin собственные правила,
+// используемые в тегах binding запросов, и включает имена полей из JSON
+// в сообщениях об ошибках.
+func RegisterValidators() error {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return errors.New("неподдерживаемый валидатор gin")
+	}
+
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+
+	rules := map[string]validator.Func{
+		"role":           stringIn(models.IsValidRole),
+		"permission":     stringIn(models.IsValidPermission),
+		"grade":          stringIn(models.IsValidGrade),
+		"storage_cond":   stringIn(models.IsValidStorageCond),
+		"unit":           stringIn(models.IsValidUnit),
+		"cash_movement":  stringIn(models.IsValidCashMovementType),
+		"payment_method": stringIn(models.IsValidTenderMethod),
+		"not_past":       notPast,
+
+		"invoice_payment_method": stringIn(models.IsValidInvoicePaymentMethod),
+		"write_off_reason":       stringIn(models.IsValidWriteOffReason),
+	}
+	for tag, fn := range rules {
+		if err := v.RegisterValidation(tag, fn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func stringIn(valid func(string) bool) validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		return valid(fl.Field().String())
+	}
+}
+
+// notPast проверяет, что дата не раньше сегодняшнего дня.
+func notPast(fl validator.FieldLevel) bool {
+	date, ok := fl.Field().Interface().(time.Time)
+	if !ok {
+		return false
+	}
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	return !date.Before(today)
+}
+
+// ruleMessages — тексты ошибок для правил валидации. %s заменяется
+// параметром правила.
+var ruleMessages = map[string]string{
+	"required":               "обязательное поле",
+	"gt":                     "должно быть больше %s",
+	"gte":                    "должно быть не меньше %s",
+	"lt":                     "должно быть меньше %s",
+	"lte":                    "должно быть не больше %s",
+	"oneof":                  "допустимые значения: %s",
+	"role":                   "допустимые значения: " + strings.Join(models.Roles, ", "),
+	"permission":             "неизвестное разрешение",
+	"grade":                  "допустимые значения: " + strings.Join(models.Grades, ", "),
+	"storage_cond":           "допустимые значения: " + strings.Join(models.StorageConditions, ", "),
+	"unit":                   "допустимые значения: " + strings.Join(models.Units, ", "),
+	"cash_movement":          "допустимые значения: " + strings.Join(models.CashMovementTypes, ", "),
+	"payment_method":         "допустимые значения: " + strings.Join(models.TenderMethods, ", "),
+	"invoice_payment_method": "допустимые значения: " + strings.Join(models.InvoicePaymentMethods, ", "),
+	"write_off_reason":       "допустимые значения: " + strings.Join(models.WriteOffReasons, ", "),
+	"url":                    "должно быть ссылкой",
+	"not_past":               "дата не может быть в прошлом",
+	"min":                    "должно быть не меньше %s",
+	"max":                    "должно быть не больше %s",
+}
+
+var stringLengthMessages = map[string]string{
+	"min": "длина не меньше %s символов",
+	"max": "длина не больше %s символов",
+}
+
+var sliceLengthMessages = map[string]string{
+	"min": "минимальное количество элементов: %s",
+	"max": "максимальное количество элементов: %s",
+}
+
+// bindError превращает ошибку разбора тела запроса в ошибку валидации.
+// Нарушения правил binding и несовпадения типов возвращаются списком
+// ошибок по полям.
+func bindError(err error) error {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		return errs.NewFieldValidation("validation_failed", "ошибка в данных запроса", fieldErrors(validationErrs))
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return errs.NewFieldValidation("validation_failed", "ошибка в данных запроса", []errs.FieldError{{
+			Field:   typeErr.Field,
+			Rule:    "type",
+			Message: "ожидается " + jsonTypeName(typeErr.Type),
+		}})
+	}
+
+	return errs.NewValidation("invalid_request", err.Error())
+}
+
+func fieldErrors(validationErrs validator.ValidationErrors) []errs.FieldError {
+	fields := make([]errs.FieldError, 0, len(validationErrs))
+	for _, fe := range validationErrs {
+		fields = append(fields, errs.FieldError{
+			Field:   fieldPath(fe.Namespace()),
+			Rule:    fe.Tag(),
+			Message: ruleMessage(fe),
+		})
+	}
+	return fields
+}
+
+// fieldPath убирает из пути поля имя корневой структуры запроса.
+func fieldPath(namespace string) string {
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return namespace
+}
+
+func ruleMessage(fe validator.FieldError) string {
+	message, ok := ruleMessages[fe.Tag()]
+
+	// Для строк и списков min/max ограничивают длину, а не значение
+	switch fe.Kind() {
+	case reflect.String:
+		if m, found := stringLengthMessages[fe.Tag()]; found {
+			message, ok = m, true
+		}
+	case reflect.Slice:
+		if m, found := sliceLengthMessages[fe.Tag()]; found {
+			message, ok = m, true
+		}
+	}
+
+	if !ok {
+		return "нарушено правило " + fe.Tag()
+	}
+	if strings.Contains(message, "%s") {
+		return fmt.Sprintf(message, fe.Param())
+	}
+	return message
+}
+
+// jsonTypeName называет тип поля так, как он выглядит в JSON.
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "целое число"
+	case reflect.Float32, reflect.Float64:
+		return "число"
+	case reflect.Bool:
+		return "логическое значение"
+	case reflect.Slice, reflect.Array:
+		return "массив"
+	case reflect.Struct, reflect.Map:
+		return "объект"
+	default:
+		return "строка"
+	}
+}
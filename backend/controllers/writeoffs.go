@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"grocery-store-api/models"
+	"grocery-store-api/services"
+)
+
+// WriteOffHandler обслуживает акты списания товаров.
+type WriteOffHandler struct {
+	Service services.WriteOffService
+}
+
+// Create составляет акт списания. Остатки уменьшаются после утверждения.
+func (h *WriteOffHandler) Create(c *gin.Context) {
+	var req WriteOffRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindError(err))
+		return
+	}
+
+	writeOff := req.toModel()
+	if err := h.Service.CreateWriteOff(currentActor(c), &writeOff); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, newWriteOffResponse(&writeOff))
+}
+
+func (h *WriteOffHandler) GetAll(c *gin.Context) {
+	var filter models.WriteOffFilter
+	filter.Status = c.Query("status")
+	filter.Reason = c.Query("reason")
+
+	if value := c.Query("department_id"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			c.Error(invalidQuery("некорректный параметр department_id"))
+			return
+		}
+		filter.DepartmentID = uint(id)
+	}
+
+	filter.Limit, _ = strconv.Atoi(c.Query("limit"))
+	filter.Offset, _ = strconv.Atoi(c.Query("offset"))
+
+	writeOffs, err := h.Service.GetWriteOffs(currentActor(c), filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newWriteOffResponses(writeOffs))
+}
+
+func (h *WriteOffHandler) GetByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID)
+		return
+	}
+
+	writeOff, err := h.Service.GetWriteOff(currentActor(c), uint(id))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newWriteOffResponse(writeOff))
+}
+
+// Approve утверждает акт списания и уменьшает остатки.
+func (h *WriteOffHandler) Approve(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID)
+		return
+	}
+
+	writeOff, err := h.Service.ApproveWriteOff(currentActor(c), uint(id))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newWriteOffResponse(writeOff))
+}
+
+// Reject отклоняет акт списания.
+func (h *WriteOffHandler) Reject(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID)
+		return
+	}
+
+	var req WriteOffRejectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindError(err))
+		return
+	}
+
+	writeOff, err := h.Service.RejectWriteOff(currentActor(c), uint(id), req.Reason)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newWriteOffResponse(writeOff))
+}
@@ -0,0 +1,123 @@
+// Package documents формирует печатные документы в PDF: товарные чеки,
+// приходные накладные и ценники.
+package documents
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/boombuler/barcode/ean"
+	"github.com/go-fonts/dejavu/dejavusans"
+	"github.com/go-fonts/dejavu/dejavusansbold"
+	"github.com/go-pdf/fpdf"
+)
+
+// ContentType — MIME-тип всех документов пакета.
+const ContentType = "application/pdf"
+
+// Встроенные шрифты PDF не содержат кириллицы, поэтому в документ
+// встраивается DejaVu Sans.
+const fontFamily = "DejaVu"
+
+func newPDF(init *fpdf.InitType) *fpdf.Fpdf {
+	pdf := fpdf.NewCustom(init)
+	pdf.AddUTF8FontFromBytes(fontFamily, "", dejavusans.TTF)
+	pdf.AddUTF8FontFromBytes(fontFamily, "B", dejavusansbold.TTF)
+	pdf.SetFont(fontFamily, "", 10)
+	return pdf
+}
+
+// formatMoney форматирует сумму в рублях: «1 234,50 руб.».
+func formatMoney(amount float64) string {
+	return formatNumber(amount, 2) + " руб."
+}
+
+// formatNumber форматирует число с разделителями разрядов и десятичной
+// запятой, отбрасывая незначащие нули, если decimals < 0.
+func formatNumber(value float64, decimals int) string {
+	text := strconv.FormatFloat(math.Abs(value), 'f', decimals, 64)
+	integer, fraction, _ := strings.Cut(text, ".")
+
+	var grouped strings.Builder
+	for i, digit := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			grouped.WriteRune(' ')
+		}
+		grouped.WriteRune(digit)
+	}
+	if fraction != "" {
+		grouped.WriteString("," + fraction)
+	}
+
+	if value < 0 && strings.Trim(text, "0.") != "" {
+		return "-" + grouped.String()
+	}
+	return grouped.String()
+}
+
+// ProductBarcode возвращает внутренний штрихкод EAN-13 товара. Префикс 2
+// зарезервирован для кодов, которые магазин назначает сам, поэтому код
+// не пересекается со штрихкодами производителей.
+func ProductBarcode(productID uint) string {
+	code := fmt.Sprintf("200%09d", productID)
+	return code + strconv.Itoa(eanCheckDigit(code))
+}
+
+func eanCheckDigit(code string) int {
+	sum := 0
+	for i, digit := range code {
+		weight := 1
+		if i%2 == 1 {
+			weight = 3
+		}
+		sum += int(digit-'0') * weight
+	}
+	return (10 - sum%10) % 10
+}
+
+// drawBarcode рисует штрихкод EAN-13 векторными полосами шириной module
+// и высотой height, начиная с точки (x, y).
+func drawBarcode(pdf *fpdf.Fpdf, code string, x, y, module, height float64) error {
+	bc, err := ean.Encode(code)
+	if err != nil {
+		return err
+	}
+
+	bounds := bc.Bounds()
+	for i := bounds.Min.X; i < bounds.Max.X; {
+		if !isDark(bc.At(i, bounds.Min.Y)) {
+			i++
+			continue
+		}
+		start := i
+		for i < bounds.Max.X && isDark(bc.At(i, bounds.Min.Y)) {
+			i++
+		}
+		pdf.Rect(x+float64(start-bounds.Min.X)*module, y, float64(i-start)*module, height, "F")
+	}
+	return nil
+}
+
+func isDark(c interface{ RGBA() (r, g, b, a uint32) }) bool {
+	r, g, b, _ := c.RGBA()
+	return r+g+b < 3*0x8000
+}
+
+// fitText обрезает текст до maxLines строк шириной width, добавляя
+// многоточие, если текст не поместился.
+func fitText(pdf *fpdf.Fpdf, text string, width float64, maxLines int) []string {
+	lines := pdf.SplitText(text, width)
+	if len(lines) <= maxLines {
+		return lines
+	}
+
+	lines = lines[:maxLines]
+	last := []rune(strings.TrimSpace(lines[maxLines-1]))
+	for len(last) > 0 && pdf.GetStringWidth(string(last)+"…") > width-2*pdf.GetCellMargin() {
+		last = last[:len(last)-1]
+	}
+	lines[maxLines-1] = string(last) + "…"
+	return lines
+}
@@ -0,0 +1,103 @@
+package documents
+
+import (
+	"io"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+
+	"grocery-store-api/models"
+)
+
+// Ценники раскладываются на листе A4 сеткой 3×7 под стандартные листы
+// самоклеящихся этикеток 63,5×38,1 мм.
+const (
+	labelColumns = 3
+	labelRows    = 7
+	labelWidth   = 63.5
+	labelHeight  = 38.1
+	labelPadding = 2.5
+
+	// barcodeModule — ширина одного модуля штрихкода EAN-13 (95 модулей)
+	barcodeModule = 0.28
+)
+
+// Labels формирует ценники по одному на каждый элемент products: чтобы
+// напечатать несколько копий, товар передается несколько раз. На ценнике
+// название, цена, цена за единицу измерения и внутренний штрихкод товара.
+func Labels(w io.Writer, products []models.Product, printedAt time.Time) error {
+	pdf := newPDF(&fpdf.InitType{UnitStr: "mm", SizeStr: "A4", OrientationStr: "P"})
+	pdf.SetMargins(0, 0, 0)
+	pdf.SetAutoPageBreak(false, 0)
+
+	pageWidth, pageHeight := pdf.GetPageSize()
+	left := (pageWidth - labelColumns*labelWidth) / 2
+	top := (pageHeight - labelRows*labelHeight) / 2
+
+	for i := range products {
+		slot := i % (labelColumns * labelRows)
+		if slot == 0 {
+			pdf.AddPage()
+		}
+		x := left + float64(slot%labelColumns)*labelWidth
+		y := top + float64(slot/labelColumns)*labelHeight
+		if err := drawLabel(pdf, &products[i], x, y, printedAt); err != nil {
+			return err
+		}
+	}
+
+	// Пустой документ без страниц не открывается в просмотрщиках
+	if len(products) == 0 {
+		pdf.AddPage()
+	}
+
+	return pdf.Output(w)
+}
+
+func drawLabel(pdf *fpdf.Fpdf, product *models.Product, x, y float64, printedAt time.Time) error {
+	// Контур для вырезания
+	pdf.SetDrawColor(180, 180, 180)
+	pdf.SetLineWidth(0.1)
+	pdf.SetDashPattern([]float64{1, 1}, 0)
+	pdf.Rect(x, y, labelWidth, labelHeight, "D")
+	pdf.SetDashPattern([]float64{}, 0)
+	pdf.SetDrawColor(0, 0, 0)
+
+	inner := labelWidth - 2*labelPadding
+	left := x + labelPadding
+
+	pdf.SetFont(fontFamily, "B", 9)
+	for i, line := range fitText(pdf, product.Name, inner, 2) {
+		pdf.SetXY(left, y+labelPadding+float64(i)*4)
+		pdf.CellFormat(inner, 4, line, "", 0, "L", false, 0, "")
+	}
+
+	// Крупные суммы печатаются мельче, чтобы поместиться в ширину ценника
+	price := formatMoney(product.Price)
+	for size := 20.0; size >= 10; size-- {
+		pdf.SetFont(fontFamily, "B", size)
+		if pdf.GetStringWidth(price) <= inner-2*pdf.GetCellMargin() {
+			break
+		}
+	}
+	pdf.SetXY(left, y+12)
+	pdf.CellFormat(inner, 9, price, "", 0, "R", false, 0, "")
+
+	pdf.SetFont(fontFamily, "", 7)
+	pdf.SetXY(left, y+21)
+	unitPrice := "Цена за 1 " + unitLabel(product.Unit) + ": " + formatMoney(product.UnitPrice())
+	pdf.CellFormat(inner, 3.5, unitPrice, "", 0, "R", false, 0, "")
+
+	code := ProductBarcode(product.ID)
+	barcodeTop := y + labelHeight - labelPadding - 10
+	if err := drawBarcode(pdf, code, left, barcodeTop, barcodeModule, 7); err != nil {
+		return err
+	}
+	pdf.SetFont(fontFamily, "", 6)
+	pdf.SetXY(left, barcodeTop+7)
+	pdf.CellFormat(95*barcodeModule, 3, code, "", 0, "C", false, 0, "")
+
+	pdf.SetXY(left, barcodeTop+7)
+	pdf.CellFormat(inner, 3, printedAt.Format("02.01.2006"), "", 0, "R", false, 0, "")
+	return nil
+}
@@ -0,0 +1,97 @@
+package documents
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/go-pdf/fpdf"
+
+	"grocery-store-api/models"
+)
+
+// Чек печатается на ленте шириной 80 мм.
+const (
+	receiptWidth  = 80.0
+	receiptMargin = 4.0
+)
+
+// Receipt формирует товарный чек продажи. Это не кассовый (фискальный)
+// чек, а документ для покупателя с составом покупки и оплатой. В sale
+// должны быть загружены товар, кассир и платежи.
+func Receipt(w io.Writer, store string, sale *models.Sale) error {
+	pdf := newPDF(&fpdf.InitType{
+		UnitStr: "mm",
+		Size:    fpdf.SizeType{Wd: receiptWidth, Ht: 130},
+	})
+	pdf.SetMargins(receiptMargin, receiptMargin, receiptMargin)
+	pdf.SetAutoPageBreak(true, receiptMargin)
+	pdf.AddPage()
+
+	width := receiptWidth - 2*receiptMargin
+
+	pdf.SetFont(fontFamily, "B", 11)
+	pdf.MultiCell(width, 5, store, "", "C", false)
+	pdf.SetFont(fontFamily, "", 9)
+	pdf.CellFormat(width, 5, "ТОВАРНЫЙ ЧЕК № "+fmt.Sprint(sale.ID), "", 1, "C", false, 0, "")
+	pdf.Ln(1)
+
+	pdf.CellFormat(width/2, 4.5, sale.SaleDate.Format("02.01.2006"), "", 0, "L", false, 0, "")
+	pdf.CellFormat(width/2, 4.5, sale.SaleDate.Format("15:04"), "", 1, "R", false, 0, "")
+	pdf.CellFormat(width, 4.5, "Кассир: "+sale.Cashier.Username, "", 1, "L", false, 0, "")
+	receiptRule(pdf, width)
+
+	pdf.MultiCell(width, 4.5, sale.Product.Name, "", "L", false)
+	unitPrice := 0.0
+	if sale.Quantity != 0 {
+		unitPrice = sale.TotalPrice / float64(sale.Quantity)
+	}
+	line := fmt.Sprintf("%d x %s", sale.Quantity, formatNumber(unitPrice, 2))
+	pdf.CellFormat(width/2, 4.5, line, "", 0, "L", false, 0, "")
+	pdf.CellFormat(width/2, 4.5, "="+formatNumber(sale.TotalPrice, 2), "", 1, "R", false, 0, "")
+	receiptRule(pdf, width)
+
+	pdf.SetFont(fontFamily, "B", 12)
+	pdf.CellFormat(width/2, 6, "ИТОГО", "", 0, "L", false, 0, "")
+	pdf.CellFormat(width/2, 6, formatMoney(sale.TotalPrice), "", 1, "R", false, 0, "")
+
+	pdf.SetFont(fontFamily, "", 9)
+	for _, payment := range sale.Payments {
+		switch payment.Method {
+		case models.PaymentCash:
+			receiptLine(pdf, width, "Наличными", payment.Amount)
+			if payment.ChangeDue > 0 {
+				receiptLine(pdf, width, "Получено", payment.Tendered)
+				receiptLine(pdf, width, "Сдача", payment.ChangeDue)
+			}
+		case models.PaymentCard:
+			receiptLine(pdf, width, "Картой "+payment.CardMask, payment.Amount)
+		case models.PaymentPoints:
+			receiptLine(pdf, width, "Баллами", payment.Amount)
+		}
+	}
+	if sale.PointsEarned > 0 {
+		pdf.CellFormat(width, 4.5, fmt.Sprintf("Начислено баллов: %d", sale.PointsEarned), "", 1, "L", false, 0, "")
+	}
+
+	pdf.Ln(4)
+	pdf.SetFont(fontFamily, "", 9)
+	pdf.CellFormat(width, 4.5, "Спасибо за покупку!", "", 1, "C", false, 0, "")
+
+	return pdf.Output(w)
+}
+
+// receiptLine печатает строку «название — сумма».
+func receiptLine(pdf *fpdf.Fpdf, width float64, label string, amount float64) {
+	pdf.CellFormat(width/2, 4.5, label, "", 0, "L", false, 0, "")
+	pdf.CellFormat(width/2, 4.5, formatNumber(amount, 2), "", 1, "R", false, 0, "")
+}
+
+// receiptRule рисует пунктирный разделитель во всю ширину чека.
+func receiptRule(pdf *fpdf.Fpdf, width float64) {
+	pdf.Ln(1)
+	y := pdf.GetY()
+	pdf.SetDashPattern([]float64{1, 1}, 0)
+	pdf.Line(receiptMargin, y, receiptMargin+width, y)
+	pdf.SetDashPattern([]float64{}, 0)
+	pdf.Ln(2)
+}
@@ -0,0 +1,152 @@
+package documents
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/go-pdf/fpdf"
+
+	"grocery-store-api/models"
+)
+
+const waybillMargin = 15.0
+
+var unitLabels = map[string]string{
+	models.UnitPiece:    "шт",
+	models.UnitKilogram: "кг",
+	models.UnitLitre:    "л",
+}
+
+func unitLabel(unit string) string {
+	if label, ok := unitLabels[unit]; ok {
+		return label
+	}
+	return unitLabels[models.UnitPiece]
+}
+
+type waybillColumn struct {
+	title string
+	width float64
+	align string
+}
+
+var waybillColumns = []waybillColumn{
+	{"№", 10, "C"},
+	{"Товар", 78, "L"},
+	{"Ед.", 14, "C"},
+	{"Кол-во", 20, "R"},
+	{"Цена", 28, "R"},
+	{"Сумма", 30, "R"},
+}
+
+// Waybill формирует приходную накладную поставки. В supply должны быть
+// загружены поставщик, принявший поставку пользователь и позиции с товарами.
+// Итог считается по позициям.
+func Waybill(w io.Writer, store string, supply *models.Supply) error {
+	pdf := newPDF(&fpdf.InitType{UnitStr: "mm", SizeStr: "A4", OrientationStr: "P"})
+	pdf.SetMargins(waybillMargin, waybillMargin, waybillMargin)
+	pdf.SetAutoPageBreak(false, waybillMargin)
+	pdf.AddPage()
+
+	pdf.SetFont(fontFamily, "B", 14)
+	title := fmt.Sprintf("Приходная накладная № %d от %s", supply.ID, supply.SupplyDate.Format("02.01.2006"))
+	pdf.CellFormat(0, 8, title, "", 1, "C", false, 0, "")
+	pdf.Ln(3)
+
+	pdf.SetFont(fontFamily, "", 10)
+	supplier := supply.Supplier.Name
+	if supply.Supplier.Phone != "" {
+		supplier += ", тел. " + supply.Supplier.Phone
+	}
+	waybillField(pdf, "Поставщик:", supplier)
+	waybillField(pdf, "Получатель:", store)
+	waybillField(pdf, "Принял:", supply.Approver.Username)
+	pdf.Ln(3)
+
+	waybillHeader(pdf)
+	total := 0.0
+	_, pageHeight := pdf.GetPageSize()
+	for i, item := range supply.Items {
+		sum := float64(item.Quantity) * item.UnitPrice
+		total += sum
+
+		lines := pdf.SplitText(item.Product.Name, waybillColumns[1].width)
+		height := 6.0
+		if len(lines) > 1 {
+			height = 5 * float64(len(lines))
+		}
+		if pdf.GetY()+height > pageHeight-waybillMargin {
+			pdf.AddPage()
+			waybillHeader(pdf)
+		}
+
+		cells := []string{
+			strconv.Itoa(i + 1),
+			"",
+			unitLabel(item.Product.Unit),
+			strconv.Itoa(item.Quantity),
+			formatNumber(item.UnitPrice, 2),
+			formatNumber(sum, 2),
+		}
+		x, y := pdf.GetXY()
+		for j, column := range waybillColumns {
+			if j == 1 {
+				// Длинное название переносится по строкам внутри ячейки
+				pdf.Rect(x, y, column.width, height, "D")
+				lineHeight := height / float64(max(len(lines), 1))
+				for k, line := range lines {
+					pdf.SetXY(x, y+float64(k)*lineHeight)
+					pdf.CellFormat(column.width, lineHeight, line, "", 0, "L", false, 0, "")
+				}
+			} else {
+				pdf.SetXY(x, y)
+				pdf.CellFormat(column.width, height, cells[j], "1", 0, column.align, false, 0, "")
+			}
+			x += column.width
+		}
+		pdf.SetXY(waybillMargin, y+height)
+	}
+
+	if pdf.GetY()+40 > pageHeight-waybillMargin {
+		pdf.AddPage()
+	}
+
+	pdf.SetFont(fontFamily, "B", 10)
+	tableWidth := 0.0
+	for _, column := range waybillColumns {
+		tableWidth += column.width
+	}
+	last := waybillColumns[len(waybillColumns)-1].width
+	pdf.CellFormat(tableWidth-last, 7, "Итого:", "", 0, "R", false, 0, "")
+	pdf.CellFormat(last, 7, formatNumber(total, 2), "1", 1, "R", false, 0, "")
+	pdf.Ln(2)
+
+	pdf.SetFont(fontFamily, "", 10)
+	summary := fmt.Sprintf("Всего наименований %d на сумму %s", len(supply.Items), formatMoney(total))
+	pdf.CellFormat(0, 6, summary, "", 1, "L", false, 0, "")
+	pdf.Ln(12)
+
+	half := tableWidth / 2
+	pdf.CellFormat(half, 6, "Сдал ____________________", "", 0, "L", false, 0, "")
+	pdf.CellFormat(half, 6, "Принял ____________________ / "+supply.Approver.Username+" /", "", 1, "L", false, 0, "")
+
+	return pdf.Output(w)
+}
+
+func waybillField(pdf *fpdf.Fpdf, label, value string) {
+	pdf.SetFont(fontFamily, "B", 10)
+	pdf.CellFormat(28, 6, label, "", 0, "L", false, 0, "")
+	pdf.SetFont(fontFamily, "", 10)
+	pdf.MultiCell(0, 6, value, "", "L", false)
+}
+
+func waybillHeader(pdf *fpdf.Fpdf) {
+	pdf.SetFont(fontFamily, "B", 9)
+	pdf.SetFillColor(230, 230, 230)
+	for _, column := range waybillColumns {
+		pdf.CellFormat(column.width, 7, column.title, "1", 0, "C", true, 0, "")
+	}
+	pdf.Ln(-1)
+	pdf.SetFont(fontFamily, "", 9)
+}
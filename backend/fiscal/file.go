@@ -0,0 +1,114 @@
+// Package fiscal содержит драйверы фискальных регистраторов, реализующие
+// services.FiscalDriver.
+package fiscal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"grocery-store-api/services"
+)
+
+// ErrOffline возвращается FileDriver, пока включена имитация
+// недоступного регистратора.
+var ErrOffline = errors.New("фискальный регистратор недоступен")
+
+// offlineFlag — файл в каталоге FileDriver, наличие которого имитирует
+// недоступность регистратора.
+const offlineFlag = "OFFLINE"
+
+// FileDriver — драйвер для разработки и тестов. Вместо регистратора он
+// сохраняет каждый чек в JSON-файл каталога и выдает номера документов по
+// порядку. Пока в каталоге есть файл OFFLINE, регистрация завершается
+// ошибкой ErrOffline, что позволяет проверить очередь повторов.
+type FileDriver struct {
+	dir string
+	mu  sync.Mutex
+}
+
+var _ services.FiscalDriver = (*FileDriver)(nil)
+
+type fileRecord struct {
+	Receipt  services.FiscalReceipt  `json:"receipt"`
+	Document services.FiscalDocument `json:"document"`
+}
+
+func NewFileDriver(dir string) (*FileDriver, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileDriver{dir: dir}, nil
+}
+
+func (d *FileDriver) Register(ctx context.Context, receipt services.FiscalReceipt) (*services.FiscalDocument, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(filepath.Join(d.dir, offlineFlag)); err == nil {
+		return nil, ErrOffline
+	}
+
+	// Чек продажи или возврата уже пробит: возвращается выданный ранее
+	// документ
+	name := fmt.Sprintf("sale-%d.json", receipt.SaleID)
+	if receipt.ReturnID != 0 {
+		name = fmt.Sprintf("return-%d.json", receipt.ReturnID)
+	}
+	path := filepath.Join(d.dir, name)
+	if data, err := os.ReadFile(path); err == nil {
+		var record fileRecord
+		if err := json.Unmarshal(data, &record); err != nil {
+			return nil, err
+		}
+		return &record.Document, nil
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	issued, err := filepath.Glob(filepath.Join(d.dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	record := fileRecord{
+		Receipt: receipt,
+		Document: services.FiscalDocument{
+			Number:       fmt.Sprint(len(issued) + 1),
+			FiscalSign:   fiscalSign(receipt),
+			RegisteredAt: time.Now(),
+		},
+	}
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	// Файл пишется через временный, чтобы после сбоя не остался
+	// недописанный чек
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return nil, err
+	}
+	return &record.Document, nil
+}
+
+// fiscalSign имитирует фискальный признак документа — 10-значное число.
+func fiscalSign(receipt services.FiscalReceipt) string {
+	hash := fnv.New32a()
+	fmt.Fprintf(hash, "%s|%d|%d|%s|%.2f", receipt.Type, receipt.SaleID, receipt.ReturnID, receipt.Date.Format(time.RFC3339Nano), receipt.Total)
+	return fmt.Sprintf("%010d", hash.Sum32())
+}
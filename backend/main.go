@@ -1,7 +1,12 @@
 package main
 
 import (
+	"context"
 	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -12,10 +17,12 @@ import (
 
 	"grocery-store-api/controllers"
 	_ "grocery-store-api/docs"
+	"grocery-store-api/fiscal"
 	"grocery-store-api/middlewares"
 	"grocery-store-api/models"
 	"grocery-store-api/repositories"
 	"grocery-store-api/services"
+	"grocery-store-api/terminal"
 )
 
 // @title           Grocery Store API
@@ -38,120 +45,592 @@ import (
 // @name Authorization
 // @description Токен аутентификации в формате "Bearer {token}"
 
+const databasePath = "grocery_store.db"
+
 func main() {
-	// Инициализация базы данных
-	db, err := gorm.Open(sqlite.Open("grocery_store.db"), &gorm.Config{})
+	// Миграция схемы базы данных
+	if err := migrate(databasePath); err != nil {
+		log.Fatal("Ошибка миграции базы данных:", err)
+	}
+
+	// Инициализация базы данных. Внешние ключи в SQLite включаются для
+	// каждого соединения отдельно, поэтому задаются в строке подключения.
+	db, err := gorm.Open(sqlite.Open(databasePath+"?_foreign_keys=on"), &gorm.Config{TranslateError: true})
 	if err != nil {
 		log.Fatal("Ошибка подключения к базе данных:", err)
 	}
 
-	// Миграция схемы базы данных
-	db.AutoMigrate(
-		&models.User{},
-		&models.Department{},
-		&models.Supplier{},
-		&models.Product{},
-		&models.Sale{},
-		&models.Supply{},
-		&models.SupplyItem{},
-	)
-
 	// Инициализация репозиториев
 	userRepo := repositories.UserRepository{DB: db}
+	rolePermissionRepo := repositories.RolePermissionRepository{DB: db}
+	auditRepo := repositories.AuditRepository{DB: db}
 	productRepo := repositories.ProductRepository{DB: db}
 	departmentRepo := repositories.DepartmentRepository{DB: db}
 	supplierRepo := repositories.SupplierRepository{DB: db}
+	productSupplierRepo := repositories.ProductSupplierRepository{DB: db}
+	supplierPriceRepo := repositories.SupplierPriceRepository{DB: db}
 	saleRepo := repositories.SaleRepository{DB: db}
 	supplyRepo := repositories.SupplyRepository{DB: db}
 	supplyItemRepo := repositories.SupplyItemRepository{DB: db}
+	supplierInvoiceRepo := repositories.SupplierInvoiceRepository{DB: db}
+	writeOffRepo := repositories.WriteOffRepository{DB: db}
+	fiscalOutboxRepo := repositories.FiscalOutboxRepository{DB: db}
+	shiftRepo := repositories.ShiftRepository{DB: db}
+	cashMovementRepo := repositories.CashMovementRepository{DB: db}
+	paymentRepo := repositories.PaymentRepository{DB: db}
+	saleReturnRepo := repositories.SaleReturnRepository{DB: db}
+	customerRepo := repositories.CustomerRepository{DB: db}
+	loyaltyTransactionRepo := repositories.LoyaltyTransactionRepository{DB: db}
 
 	// Инициализация сервисов
-	userService := services.UserService{Repo: userRepo}
-	productService := services.ProductService{Repo: productRepo}
-	departmentService := services.DepartmentService{Repo: departmentRepo}
-	supplierService := services.SupplierService{Repo: supplierRepo}
+	passwordPolicy := services.DefaultPasswordPolicy
+	passwordPolicy.MinLength = envInt("PASSWORD_MIN_LENGTH", passwordPolicy.MinLength)
+	passwordPolicy.RequireUpper = envBool("PASSWORD_REQUIRE_UPPER", passwordPolicy.RequireUpper)
+	passwordPolicy.RequireSpecial = envBool("PASSWORD_REQUIRE_SPECIAL", passwordPolicy.RequireSpecial)
+
+	loginPolicy := services.DefaultLoginPolicy
+	loginPolicy.MaxUserFailures = envInt("LOGIN_MAX_USER_FAILURES", loginPolicy.MaxUserFailures)
+	loginPolicy.MaxIPFailures = envInt("LOGIN_MAX_IP_FAILURES", loginPolicy.MaxIPFailures)
+	loginPolicy.LockoutDuration = time.Duration(envInt("LOGIN_LOCKOUT_MINUTES", int(loginPolicy.LockoutDuration/time.Minute))) * time.Minute
+
+	auditService := services.AuditService{Repo: auditRepo}
+	userService := services.UserService{
+		Repo:           userRepo,
+		DepartmentRepo: departmentRepo,
+		PasswordPolicy: passwordPolicy,
+		Throttle:       services.NewLoginThrottle(loginPolicy),
+		Audit:          auditService,
+	}
+	permissionService := services.PermissionService{Repo: rolePermissionRepo, Audit: auditService}
+	departmentScope := services.DepartmentScope{DepartmentRepo: departmentRepo}
+	productService := services.ProductService{
+		Repo:           productRepo,
+		DepartmentRepo: departmentRepo,
+		SupplierRepo:   supplierRepo,
+		Scope:          departmentScope,
+		Audit:          auditService,
+	}
+	departmentService := services.DepartmentService{
+		Repo:        departmentRepo,
+		ProductRepo: productRepo,
+		Scope:       departmentScope,
+		Audit:       auditService,
+	}
+	supplierService := services.SupplierService{Repo: supplierRepo, ProductRepo: productRepo, Audit: auditService}
+	catalogService := services.CatalogService{
+		LinkRepo:     productSupplierRepo,
+		PriceRepo:    supplierPriceRepo,
+		ProductRepo:  productRepo,
+		SupplierRepo: supplierRepo,
+		Scope:        departmentScope,
+		Audit:        auditService,
+	}
+	fiscalDriver, err := fiscal.NewFileDriver(envString("FISCAL_DIR", "fiscal_receipts"))
+	if err != nil {
+		log.Fatal("Ошибка инициализации фискального драйвера:", err)
+	}
+	fiscalPolicy := services.DefaultFiscalPolicy
+	fiscalPolicy.RetryDelay = time.Duration(envInt("FISCAL_RETRY_SECONDS", int(fiscalPolicy.RetryDelay/time.Second))) * time.Second
+	fiscalPolicy.PollInterval = time.Duration(envInt("FISCAL_POLL_SECONDS", int(fiscalPolicy.PollInterval/time.Second))) * time.Second
+	fiscalService := services.FiscalService{
+		Driver:     fiscalDriver,
+		SaleRepo:   saleRepo,
+		ReturnRepo: saleReturnRepo,
+		OutboxRepo: fiscalOutboxRepo,
+		Policy:     fiscalPolicy,
+	}
+
+	customerService := services.CustomerService{
+		Repo:            customerRepo,
+		TransactionRepo: loyaltyTransactionRepo,
+		Audit:           auditService,
+	}
+
+	// Вместо банковского терминала пока используется имитация
+	paymentService := services.PaymentService{
+		Terminal: terminal.NewSimulator(float64(envInt("PAYMENT_DECLINE_ABOVE", 0))),
+		Timeout:  time.Duration(envInt("PAYMENT_TIMEOUT_SECONDS", int(services.DefaultTerminalTimeout/time.Second))) * time.Second,
+	}
+
 	saleService := services.SaleService{
 		Repo:        saleRepo,
 		ProductRepo: productRepo,
+		ShiftRepo:   shiftRepo,
+		Scope:       departmentScope,
+		Audit:       auditService,
+		Fiscal:      fiscalService,
+		Payments:    paymentService,
+		Customers:   customerService,
+	}
+	shiftService := services.ShiftService{
+		Repo:         shiftRepo,
+		MovementRepo: cashMovementRepo,
+		SaleRepo:     saleRepo,
+		PaymentRepo:  paymentRepo,
+		ReturnRepo:   saleReturnRepo,
+		Permissions:  permissionService,
+		Audit:        auditService,
+	}
+	returnService := services.ReturnService{
+		Repo:      saleReturnRepo,
+		SaleRepo:  saleRepo,
+		ShiftRepo: shiftRepo,
+		Shifts:    shiftService,
+		Payments:  paymentService,
+		Fiscal:    fiscalService,
+		Audit:     auditService,
 	}
 	supplyService := services.SupplyService{
-		Repo:        supplyRepo,
-		ItemRepo:    supplyItemRepo,
+		Repo:         supplyRepo,
+		ItemRepo:     supplyItemRepo,
+		ProductRepo:  productRepo,
+		SupplierRepo: supplierRepo,
+		PriceRepo:    supplierPriceRepo,
+		Scope:        departmentScope,
+		Audit:        auditService,
+	}
+	payablesService := services.PayablesService{
+		Repo:          supplierInvoiceRepo,
+		SupplyRepo:    supplyRepo,
+		Audit:         auditService,
+		CheckInterval: time.Duration(envInt("PAYABLES_CHECK_MINUTES", int(services.DefaultOverdueCheckInterval/time.Minute))) * time.Minute,
+	}
+
+	classificationPolicy := services.DefaultABCXYZPolicy
+	classificationPolicy.Weeks = envInt("ABC_XYZ_WEEKS", classificationPolicy.Weeks)
+	classificationPolicy.AShare = envPercent("ABC_A_PERCENT", classificationPolicy.AShare)
+	classificationPolicy.BShare = envPercent("ABC_B_PERCENT", classificationPolicy.BShare)
+	classificationPolicy.XMaxVariation = envPercent("XYZ_X_PERCENT", classificationPolicy.XMaxVariation)
+	classificationPolicy.YMaxVariation = envPercent("XYZ_Y_PERCENT", classificationPolicy.YMaxVariation)
+	classificationService := services.ClassificationService{
+		SaleRepo:      saleRepo,
+		ProductRepo:   productRepo,
+		Scope:         departmentScope,
+		Audit:         auditService,
+		Policy:        classificationPolicy,
+		ApplyInterval: time.Duration(envInt("ABC_XYZ_APPLY_HOURS", 0)) * time.Hour,
+	}
+
+	forecastService := services.ForecastService{
+		SaleRepo:    saleRepo,
 		ProductRepo: productRepo,
+		Scope:       departmentScope,
+	}
+
+	inventoryService := services.InventoryService{
+		ProductRepo:    productRepo,
+		SaleRepo:       saleRepo,
+		ReturnRepo:     saleReturnRepo,
+		SupplyItemRepo: supplyItemRepo,
+		WriteOffRepo:   writeOffRepo,
+		Scope:          departmentScope,
+	}
+
+	writeOffService := services.WriteOffService{
+		Repo:           writeOffRepo,
+		ProductRepo:    productRepo,
+		DepartmentRepo: departmentRepo,
+		SupplyItemRepo: supplyItemRepo,
+		Scope:          departmentScope,
+		Audit:          auditService,
+	}
+
+	importService := services.ImportService{
+		ProductRepo:    productRepo,
+		DepartmentRepo: departmentRepo,
+		SupplierRepo:   supplierRepo,
+		Scope:          departmentScope,
+		Audit:          auditService,
+	}
+
+	exportService := services.ExportService{
+		SaleRepo:       saleRepo,
+		SupplyItemRepo: supplyItemRepo,
+		ProductRepo:    productRepo,
+		Scope:          departmentScope,
+	}
+
+	// Выдача ролям по умолчанию новых разрешений
+	if err := permissionService.SyncPermissions(); err != nil {
+		log.Fatal("Ошибка инициализации разрешений:", err)
 	}
 
+	// Создание первого администратора при пустой базе пользователей
+	bootstrapUsername := os.Getenv("BOOTSTRAP_ADMIN_USERNAME")
+	if bootstrapUsername == "" {
+		bootstrapUsername = models.RoleAdmin
+	}
+	admin, password, err := userService.EnsureBootstrapAdmin(bootstrapUsername, os.Getenv("BOOTSTRAP_ADMIN_PASSWORD"))
+	if err != nil {
+		log.Fatal("Ошибка создания первого администратора:", err)
+	}
+	switch {
+	case admin != nil && password != "":
+		log.Printf("Создан первый администратор %q с паролем %q — смените пароль после входа", admin.Username, password)
+	case admin != nil:
+		log.Printf("Создан первый администратор %q с паролем из BOOTSTRAP_ADMIN_PASSWORD", admin.Username)
+	}
+
+	// Фоновые повторы фискализации продаж, не зарегистрированных сразу
+	go fiscalService.Run(context.Background())
+
+	// Фоновая проверка просроченных счетов поставщиков
+	go payablesService.Run(context.Background())
+
+	// Периодическая запись классов ABC в сорт товаров, если включена
+	go classificationService.Run(context.Background())
+
 	// Инициализация обработчиков
 	userHandler := controllers.UserHandler{Service: userService}
+	roleHandler := controllers.RoleHandler{Service: permissionService}
+	auditHandler := controllers.AuditHandler{Service: auditService}
 	productHandler := controllers.ProductHandler{Service: productService}
 	departmentHandler := controllers.DepartmentHandler{Service: departmentService}
 	supplierHandler := controllers.SupplierHandler{Service: supplierService}
+	catalogHandler := controllers.CatalogHandler{Service: catalogService}
 	saleHandler := controllers.SaleHandler{Service: saleService}
 	supplyHandler := controllers.SupplyHandler{Service: supplyService}
+	payablesHandler := controllers.PayablesHandler{Service: payablesService}
+	writeOffHandler := controllers.WriteOffHandler{Service: writeOffService}
+	returnHandler := controllers.ReturnHandler{Service: returnService}
+	shiftHandler := controllers.ShiftHandler{Service: shiftService}
+	customerHandler := controllers.CustomerHandler{Service: customerService}
+	importHandler := controllers.ImportHandler{Service: importService}
+	exportHandler := controllers.ExportHandler{Service: exportService}
+	documentHandler := controllers.DocumentHandler{
+		SaleService:    saleService,
+		SupplyService:  supplyService,
+		ProductService: productService,
+		StoreName:      envString("STORE_NAME", "Продуктовый магазин"),
+	}
 	analyticsHandler := controllers.AnalyticsHandler{
 		SaleService:    saleService,
 		ProductService: productService,
+		SupplyService:  supplyService,
+
+		ClassificationService: classificationService,
+		ForecastService:       forecastService,
+		InventoryService:      inventoryService,
+		WriteOffService:       writeOffService,
+	}
+
+	// Инициализация проверки разрешений
+	authz := middlewares.PermissionMiddleware{Service: permissionService}
+
+	// Правила валидации запросов
+	if err := controllers.RegisterValidators(); err != nil {
+		log.Fatal("Ошибка настройки валидации:", err)
 	}
 
 	// Инициализация роутера Gin
 	r := gin.Default()
 
+	// Заголовкам X-Forwarded-For и X-Real-IP доверяем только от указанных
+	// прокси, иначе адрес клиента для ограничения попыток входа и журнала
+	// изменений подделывается одним заголовком. По умолчанию прокси нет.
+	if err := r.SetTrustedProxies(envList("TRUSTED_PROXIES")); err != nil {
+		log.Fatal("Ошибка настройки доверенных прокси:", err)
+	}
+
+	// Идентификатор запроса для журнала изменений
+	r.Use(middlewares.RequestID())
+
 	// Настройка CORS
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"*"},
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "If-Match", middlewares.RequestIDHeader},
+		ExposeHeaders:    []string{"Content-Length", "ETag", middlewares.RequestIDHeader},
 		AllowCredentials: true,
 	}))
 
+	// Единый формат ответов с ошибками
+	r.Use(controllers.ErrorHandler())
+
 	// Swagger UI
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
 	// Публичные маршруты
-	r.POST("/api/register", userHandler.Register)
 	r.POST("/api/login", userHandler.Login)
 
 	// Защищенные маршруты
 	api := r.Group("/api")
-	api.Use(middlewares.JWTAuth())
+	auth := middlewares.AuthMiddleware{Users: userService}
+	api.Use(auth.JWTAuth())
+
+	// Регистрация и управление пользователями
+	api.POST("/register", authz.RequirePermission(models.PermUserManage), userHandler.Register)
+
+	users := api.Group("/users")
+	users.Use(authz.RequirePermission(models.PermUserManage))
+	users.GET("", userHandler.GetAll)
+	users.GET("/:id", userHandler.GetByID)
+	users.POST("", userHandler.Register)
+	users.PUT("/:id/role", userHandler.ChangeRole)
+	users.PUT("/:id/password", userHandler.ResetPassword)
+	users.PUT("/:id/department", userHandler.SetDepartment)
+	users.POST("/:id/disable", userHandler.Disable)
+	users.POST("/:id/enable", userHandler.Enable)
+
+	// Управление разрешениями ролей
+	roles := api.Group("/roles")
+	roles.Use(authz.RequirePermission(models.PermRoleManage))
+	roles.GET("", roleHandler.GetAll)
+	roles.GET("/permissions", roleHandler.GetPermissions)
+	roles.PUT("/:role/permissions", roleHandler.UpdatePermissions)
+
+	// Журнал изменений
+	api.GET("/audit", authz.RequirePermission(models.PermAuditView), auditHandler.GetAll)
 
 	// Маршруты для товаров
 	api.GET("/products", productHandler.GetAll)
 	api.GET("/products/:id", productHandler.GetByID)
-	api.POST("/products", middlewares.ManagerAuth(), productHandler.Create)
-	api.PUT("/products/:id", middlewares.ManagerAuth(), productHandler.Update)
-	api.DELETE("/products/:id", middlewares.AdminAuth(), productHandler.Delete)
+	api.POST("/products", authz.RequirePermission(models.PermProductWrite), productHandler.Create)
+	api.PUT("/products/:id", authz.RequirePermission(models.PermProductWrite), productHandler.Update)
+	api.PATCH("/products/:id", authz.RequirePermission(models.PermProductWrite), productHandler.Patch)
+	api.POST("/products/import", authz.RequirePermission(models.PermProductWrite), importHandler.ImportProducts)
+	api.POST("/products/labels", documentHandler.ProductLabels)
+	api.DELETE("/products/:id", authz.RequirePermission(models.PermProductDelete), productHandler.Delete)
+	api.GET("/products/archived", authz.RequirePermission(models.PermProductDelete), productHandler.GetArchived)
+	api.POST("/products/:id/restore", authz.RequirePermission(models.PermProductDelete), productHandler.Restore)
+	api.GET("/products/:id/suppliers", authz.RequirePermission(models.PermSupplyView), catalogHandler.GetProductSuppliers)
+	api.PUT("/products/:id/suppliers/:supplier_id", authz.RequirePermission(models.PermSupplierWrite), catalogHandler.SaveProductSupplier)
+	api.DELETE("/products/:id/suppliers/:supplier_id", authz.RequirePermission(models.PermSupplierWrite), catalogHandler.DeleteProductSupplier)
 
 	// Маршруты для отделов
 	api.GET("/departments", departmentHandler.GetAll)
 	api.GET("/departments/:id", departmentHandler.GetByID)
-	api.POST("/departments", middlewares.ManagerAuth(), departmentHandler.Create)
-	api.PUT("/departments/:id", middlewares.ManagerAuth(), departmentHandler.Update)
-	api.DELETE("/departments/:id", middlewares.AdminAuth(), departmentHandler.Delete)
+	api.POST("/departments", authz.RequirePermission(models.PermDepartmentWrite), departmentHandler.Create)
+	api.PUT("/departments/:id", authz.RequirePermission(models.PermDepartmentWrite), departmentHandler.Update)
+	api.PATCH("/departments/:id", authz.RequirePermission(models.PermDepartmentWrite), departmentHandler.Patch)
+	api.DELETE("/departments/:id", authz.RequirePermission(models.PermDepartmentDelete), departmentHandler.Delete)
+	api.GET("/departments/archived", authz.RequirePermission(models.PermDepartmentDelete), departmentHandler.GetArchived)
+	api.POST("/departments/:id/restore", authz.RequirePermission(models.PermDepartmentDelete), departmentHandler.Restore)
 
 	// Маршруты для поставщиков
 	api.GET("/suppliers", supplierHandler.GetAll)
 	api.GET("/suppliers/:id", supplierHandler.GetByID)
-	api.POST("/suppliers", middlewares.ManagerAuth(), supplierHandler.Create)
-	api.PUT("/suppliers/:id", middlewares.ManagerAuth(), supplierHandler.Update)
-	api.DELETE("/suppliers/:id", middlewares.AdminAuth(), supplierHandler.Delete)
+	api.POST("/suppliers", authz.RequirePermission(models.PermSupplierWrite), supplierHandler.Create)
+	api.PUT("/suppliers/:id", authz.RequirePermission(models.PermSupplierWrite), supplierHandler.Update)
+	api.PATCH("/suppliers/:id", authz.RequirePermission(models.PermSupplierWrite), supplierHandler.Patch)
+	api.POST("/suppliers/import", authz.RequirePermission(models.PermSupplierWrite), importHandler.ImportSuppliers)
+	api.DELETE("/suppliers/:id", authz.RequirePermission(models.PermSupplierDelete), supplierHandler.Delete)
+	api.GET("/suppliers/archived", authz.RequirePermission(models.PermSupplierDelete), supplierHandler.GetArchived)
+	api.POST("/suppliers/:id/restore", authz.RequirePermission(models.PermSupplierDelete), supplierHandler.Restore)
+	api.GET("/suppliers/:id/prices", authz.RequirePermission(models.PermSupplyView), catalogHandler.GetPriceList)
+	api.POST("/suppliers/:id/prices", authz.RequirePermission(models.PermSupplierWrite), catalogHandler.UploadPriceList)
+	api.GET("/suppliers/:id/prices/:product_id", authz.RequirePermission(models.PermSupplyView), catalogHandler.GetPriceHistory)
 
 	// Маршруты для продаж
-	api.GET("/sales", middlewares.ManagerAuth(), saleHandler.GetAll)
-	api.GET("/sales/:id", middlewares.CashierAuth(), saleHandler.GetByID)
-	api.POST("/sales", middlewares.CashierAuth(), saleHandler.Create)
+	api.GET("/sales", authz.RequirePermission(models.PermSaleList), saleHandler.GetAll)
+	api.GET("/sales/:id", authz.RequirePermission(models.PermSaleView), saleHandler.GetByID)
+	api.GET("/sales/:id/receipt", authz.RequirePermission(models.PermSaleView), documentHandler.SaleReceipt)
+	api.POST("/sales", authz.RequirePermission(models.PermSaleCreate), saleHandler.Create)
+	api.GET("/sales/:id/returns", authz.RequirePermission(models.PermSaleView), returnHandler.GetBySale)
+	api.POST("/sales/:id/returns", authz.RequirePermission(models.PermSaleReturn), returnHandler.Create)
+
+	// Покупатели и бонусные счета
+	customers := api.Group("/customers")
+	customers.GET("", authz.RequirePermission(models.PermCustomerView), customerHandler.GetAll)
+	customers.GET("/:id", authz.RequirePermission(models.PermCustomerView), customerHandler.GetByID)
+	customers.POST("", authz.RequirePermission(models.PermCustomerWrite), customerHandler.Create)
+	customers.PUT("/:id", authz.RequirePermission(models.PermCustomerWrite), customerHandler.Update)
+	customers.PATCH("/:id", authz.RequirePermission(models.PermCustomerWrite), customerHandler.Patch)
+	customers.GET("/:id/points", authz.RequirePermission(models.PermCustomerView), customerHandler.GetPoints)
+	customers.GET("/:id/points/history", authz.RequirePermission(models.PermCustomerView), customerHandler.GetPointsHistory)
+	customers.POST("/:id/points", authz.RequirePermission(models.PermLoyaltyAdjust), customerHandler.AdjustPoints)
+
+	// Кассовые смены
+	shifts := api.Group("/shifts")
+	shifts.POST("", authz.RequirePermission(models.PermShiftOperate), shiftHandler.Open)
+	shifts.GET("", authz.RequirePermission(models.PermShiftManage), shiftHandler.GetAll)
+	shifts.GET("/current", authz.RequirePermission(models.PermShiftOperate), shiftHandler.GetCurrent)
+	shifts.GET("/:id", authz.RequirePermission(models.PermShiftOperate), shiftHandler.GetByID)
+	shifts.POST("/:id/cash", authz.RequirePermission(models.PermShiftOperate), shiftHandler.AddCashMovement)
+	shifts.GET("/:id/report", authz.RequirePermission(models.PermShiftOperate), shiftHandler.GetReport)
+	shifts.POST("/:id/close", authz.RequirePermission(models.PermShiftOperate), shiftHandler.Close)
 
 	// Маршруты для поставок
-	api.GET("/supplies", middlewares.ManagerAuth(), supplyHandler.GetAll)
-	api.GET("/supplies/:id", middlewares.ManagerAuth(), supplyHandler.GetByID)
-	api.POST("/supplies", middlewares.ManagerAuth(), supplyHandler.Create)
+	api.GET("/supplies", authz.RequirePermission(models.PermSupplyView), supplyHandler.GetAll)
+	api.GET("/supplies/price-comparison", authz.RequirePermission(models.PermSupplyView), catalogHandler.ComparePrices)
+	api.GET("/supplies/:id", authz.RequirePermission(models.PermSupplyView), supplyHandler.GetByID)
+	api.GET("/supplies/:id/waybill", authz.RequirePermission(models.PermSupplyView), documentHandler.SupplyWaybill)
+	api.POST("/supplies", authz.RequirePermission(models.PermSupplyApprove), supplyHandler.Create)
+	api.POST("/supplies/:id/invoice", authz.RequirePermission(models.PermPayablesManage), payablesHandler.RegisterInvoice)
+
+	// Маршруты для расчетов с поставщиками
+	invoices := api.Group("/invoices")
+	invoices.GET("", authz.RequirePermission(models.PermPayablesView), payablesHandler.GetAll)
+	invoices.GET("/:id", authz.RequirePermission(models.PermPayablesView), payablesHandler.GetByID)
+	invoices.POST("/:id/payments", authz.RequirePermission(models.PermPayablesManage), payablesHandler.Pay)
+	invoices.POST("/:id/acknowledge", authz.RequirePermission(models.PermPayablesManage), payablesHandler.Acknowledge)
+	api.GET("/payables/aging", authz.RequirePermission(models.PermPayablesView), payablesHandler.GetAging)
+	api.GET("/payables/overdue", authz.RequirePermission(models.PermPayablesView), payablesHandler.GetOverdue)
+	api.GET("/payables/alerts", authz.RequirePermission(models.PermPayablesView), payablesHandler.GetAlerts)
+
+	// Маршруты для списания товаров
+	writeOffs := api.Group("/write-offs")
+	writeOffs.POST("", authz.RequirePermission(models.PermWriteOffCreate), writeOffHandler.Create)
+	writeOffs.GET("", authz.RequirePermission(models.PermWriteOffCreate), writeOffHandler.GetAll)
+	writeOffs.GET("/:id", authz.RequirePermission(models.PermWriteOffCreate), writeOffHandler.GetByID)
+	writeOffs.POST("/:id/approve", authz.RequirePermission(models.PermWriteOffApprove), writeOffHandler.Approve)
+	writeOffs.POST("/:id/reject", authz.RequirePermission(models.PermWriteOffApprove), writeOffHandler.Reject)
 
 	// Маршруты для аналитики
 	analytics := api.Group("/analytics")
-	analytics.Use(middlewares.ManagerAuth())
+	analytics.Use(authz.RequirePermission(models.PermAnalyticsView))
 	analytics.GET("/low-stock", analyticsHandler.GetLowStockProducts)
 	analytics.GET("/sales", analyticsHandler.GetSalesByPeriod)
+	analytics.GET("/customers", analyticsHandler.GetCustomers)
+	analytics.GET("/suppliers/:id", analyticsHandler.GetSupplierScorecard)
+	analytics.GET("/abc-xyz", analyticsHandler.GetABCXYZ)
+	analytics.GET("/forecast", analyticsHandler.GetForecast)
+	analytics.GET("/inventory/valuation", analyticsHandler.GetStockValuation)
+	analytics.GET("/inventory/turnover", analyticsHandler.GetInventoryTurnover)
+	analytics.GET("/inventory/dead-stock", analyticsHandler.GetDeadStock)
+	analytics.GET("/shrinkage", analyticsHandler.GetShrinkage)
+	analytics.POST("/abc-xyz/apply", authz.RequirePermission(models.PermProductWrite), analyticsHandler.ApplyABCXYZ)
+
+	// Выгрузки в CSV и XLSX
+	export := api.Group("/export")
+	export.GET("/sales", authz.RequirePermission(models.PermSaleList), exportHandler.ExportSales)
+	export.GET("/supplies", authz.RequirePermission(models.PermSupplyView), exportHandler.ExportSupplies)
+	export.GET("/stock", authz.RequirePermission(models.PermAnalyticsView), exportHandler.ExportStock)
+	export.GET("/low-stock", authz.RequirePermission(models.PermAnalyticsView), exportHandler.ExportLowStock)
 
 	// Запуск сервера на порту 8000
 	r.Run(":8000")
 }
+
+// envInt читает целое число из переменной окружения или возвращает значение по умолчанию.
+func envInt(name string, def int) int {
+	value, err := strconv.Atoi(os.Getenv(name))
+	if err != nil {
+		return def
+	}
+	return value
+}
+
+// envPercent читает долю, заданную в процентах, из переменной окружения
+// или возвращает значение по умолчанию.
+func envPercent(name string, def float64) float64 {
+	value, err := strconv.ParseFloat(os.Getenv(name), 64)
+	if err != nil {
+		return def
+	}
+	return value / 100
+}
+
+func envString(name, def string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return def
+}
+
+// envList читает список значений через запятую из переменной окружения.
+// Пустая переменная дает nil.
+func envList(name string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(name), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func envBool(name string, def bool) bool {
+	value, err := strconv.ParseBool(os.Getenv(name))
+	if err != nil {
+		return def
+	}
+	return value
+}
+
+// migrate обновляет схему в отдельном соединении без проверки внешних ключей:
+// при добавлении недостающих ограничений SQLite пересоздает таблицу, и старые
+// записи с битыми ссылками иначе остановили бы миграцию. Такие записи только
+// выводятся в лог, новые изменения проверяются уже на уровне базы.
+func migrate(path string) error {
+	db, err := gorm.Open(sqlite.Open(path), &gorm.Config{})
+	if err != nil {
+		return err
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	defer sqlDB.Close()
+
+	err = db.AutoMigrate(
+		&models.User{},
+		&models.Permission{},
+		&models.RolePermission{},
+		&models.AuditLog{},
+		&models.Department{},
+		&models.Supplier{},
+		&models.Product{},
+		&models.ProductSupplier{},
+		&models.SupplierPrice{},
+		&models.Customer{},
+		&models.Shift{},
+		&models.CashMovement{},
+		&models.Sale{},
+		&models.Payment{},
+		&models.SaleReturn{},
+		&models.Refund{},
+		&models.LoyaltyTransaction{},
+		&models.FiscalOutboxEntry{},
+		&models.Supply{},
+		&models.SupplyItem{},
+		&models.SupplierInvoice{},
+		&models.InvoicePayment{},
+		&models.WriteOff{},
+		&models.WriteOffItem{},
+	)
+	if err != nil {
+		return err
+	}
+
+	// Очередь фискализации стала хранить и чеки возвратов, поэтому у продажи
+	// может быть несколько записей: уникальный индекс по продаже заменен
+	// индексом по продаже и возврату
+	if err := db.Exec("DROP INDEX IF EXISTS idx_fiscal_outbox_entries_sale_id").Error; err != nil {
+		return err
+	}
+
+	// Продажи, созданные до учета способов оплаты, считаются оплаченными
+	// наличными без сдачи
+	err = db.Exec(`INSERT INTO payments (sale_id, method, amount, tendered, change_due, created_at)
+		SELECT id, ?, total_price, total_price, 0, sale_date FROM sales
+		WHERE NOT EXISTS (SELECT 1 FROM payments WHERE payments.sale_id = sales.id)`, models.PaymentCash).Error
+	if err != nil {
+		return err
+	}
+
+	// Каталог поставщиков появился позже товаров: поставщик каждого товара
+	// становится основным в его каталоге
+	err = db.Exec(`INSERT INTO product_suppliers (product_id, supplier_id, supplier_sku, preferred)
+		SELECT id, supplier_id, '', true FROM products
+		WHERE supplier_id <> 0 AND NOT EXISTS (SELECT 1 FROM product_suppliers
+			WHERE product_suppliers.product_id = products.id AND product_suppliers.supplier_id = products.supplier_id)`).Error
+	if err != nil {
+		return err
+	}
+
+	var violations []struct {
+		Table  string
+		RowID  int64
+		Parent string
+	}
+	if err := db.Raw("SELECT \"table\", rowid AS row_id, parent FROM pragma_foreign_key_check").Scan(&violations).Error; err != nil {
+		return err
+	}
+	for _, v := range violations {
+		log.Printf("Нарушение внешнего ключа: %s, строка %d ссылается на несуществующую запись в %s", v.Table, v.RowID, v.Parent)
+	}
+
+	return nil
+}
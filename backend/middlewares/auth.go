@@ -3,7 +3,8 @@ package middlewares
 import (
 	"errors"
 	"grocery-store-api/models"
-	"net/http"
+	"grocery-store-api/services"
+	"grocery-store-api/services/errs"
 	"strings"
 	"time"
 
@@ -17,6 +18,7 @@ type Claims struct {
 	UserID   uint   `json:"user_id"`
 	Username string `json:"username"`
 	Role     string `json:"role"`
+	Version  uint   `json:"ver"`
 	jwt.StandardClaims
 }
 
@@ -26,6 +28,7 @@ func GenerateToken(user *models.User) (string, error) {
 		UserID:   user.ID,
 		Username: user.Username,
 		Role:     user.Role,
+		Version:  user.TokenVersion,
 		StandardClaims: jwt.StandardClaims{
 			ExpiresAt: expireTime.Unix(),
 			Issuer:    "grocery-store-api",
@@ -52,85 +55,76 @@ func ParseToken(tokenString string) (*Claims, error) {
 	return nil, errors.New("invalid token")
 }
 
-func JWTAuth() gin.HandlerFunc {
+// AuthMiddleware проверяет токен и при каждом запросе загружает
+// пользователя из базы: отключение, смена роли и сброс пароля действуют
+// сразу, не дожидаясь истечения токена.
+type AuthMiddleware struct {
+	Users services.UserService
+}
+
+func (m *AuthMiddleware) JWTAuth() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is required"})
+			c.Error(errs.NewUnauthorized("missing_token", "Authorization header is required"))
 			c.Abort()
 			return
 		}
 
 		parts := strings.SplitN(authHeader, " ", 2)
 		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header format must be Bearer {token}"})
+			c.Error(errs.NewUnauthorized("invalid_token", "Authorization header format must be Bearer {token}"))
 			c.Abort()
 			return
 		}
 
 		claims, err := ParseToken(parts[1])
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
-			c.Abort()
-			return
-		}
-
-		c.Set("userID", claims.UserID)
-		c.Set("username", claims.Username)
-		c.Set("role", claims.Role)
-		c.Next()
-	}
-}
-
-func AdminAuth() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		role, exists := c.Get("role")
-		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+			c.Error(errs.NewUnauthorized("invalid_token", "Invalid or expired token"))
 			c.Abort()
 			return
 		}
 
-		if role != "admin" {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+		user, err := m.Users.Authenticate(claims.UserID, claims.Version)
+		if err != nil {
+			c.Error(err)
 			c.Abort()
 			return
 		}
 
+		c.Set("userID", user.ID)
+		c.Set("username", user.Username)
+		c.Set("role", user.Role)
+		c.Set("departmentID", user.DepartmentID)
 		c.Next()
 	}
 }
 
-func ManagerAuth() gin.HandlerFunc {
+// PermissionMiddleware проверяет разрешения роли текущего пользователя.
+// Сопоставление ролей и разрешений хранится в базе, поэтому изменения
+// вступают в силу без перезапуска и повторного входа.
+type PermissionMiddleware struct {
+	Service services.PermissionService
+}
+
+func (m *PermissionMiddleware) RequirePermission(permissions ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		role, exists := c.Get("role")
 		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+			c.Error(errs.NewUnauthorized("not_authenticated", "Not authenticated"))
 			c.Abort()
 			return
 		}
 
-		if role != "admin" && role != "manager" {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Manager access required"})
-			c.Abort()
-			return
-		}
-
-		c.Next()
-	}
-}
-
-func CashierAuth() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		role, exists := c.Get("role")
-		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		allowed, err := m.Service.HasPermissions(role.(string), permissions...)
+		if err != nil {
+			c.Error(err)
 			c.Abort()
 			return
 		}
 
-		if role != "admin" && role != "manager" && role != "cashier" {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Cashier access required"})
+		if !allowed {
+			c.Error(errs.NewForbidden("permission_required", "Permission required: "+strings.Join(permissions, ", ")))
 			c.Abort()
 			return
 		}
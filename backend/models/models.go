@@ -2,28 +2,195 @@ package models
 
 import (
 	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	RoleAdmin   = "admin"
+	RoleManager = "manager"
+	RoleCashier = "cashier"
+)
+
+// Roles — фиксированный набор ролей, которые можно назначить пользователю.
+var Roles = []string{RoleAdmin, RoleManager, RoleCashier}
+
+func IsValidRole(role string) bool {
+	return contains(Roles, role)
+}
+
+const (
+	PermProductWrite     = "product.write"
+	PermProductDelete    = "product.delete"
+	PermDepartmentWrite  = "department.write"
+	PermDepartmentDelete = "department.delete"
+	PermSupplierWrite    = "supplier.write"
+	PermSupplierDelete   = "supplier.delete"
+	PermSaleCreate       = "sale.create"
+	PermSaleView         = "sale.view"
+	PermSaleList         = "sale.list"
+	PermSaleReturn       = "sale.return"
+	PermSupplyView       = "supply.view"
+	PermSupplyApprove    = "supply.approve"
+	PermAnalyticsView    = "analytics.view"
+	PermUserManage       = "user.manage"
+	PermRoleManage       = "role.manage"
+	PermAuditView        = "audit.view"
+	PermShiftOperate     = "shift.operate"
+	PermShiftManage      = "shift.manage"
+	PermCustomerView     = "customer.view"
+	PermCustomerWrite    = "customer.write"
+	PermLoyaltyAdjust    = "loyalty.adjust"
+	PermPayablesView     = "payables.view"
+	PermPayablesManage   = "payables.manage"
+	PermWriteOffCreate   = "writeoff.create"
+	PermWriteOffApprove  = "writeoff.approve"
 )
 
+// Permissions — полный список разрешений, которые можно назначить роли.
+var Permissions = []string{
+	PermProductWrite, PermProductDelete,
+	PermDepartmentWrite, PermDepartmentDelete,
+	PermSupplierWrite, PermSupplierDelete,
+	PermSaleCreate, PermSaleView, PermSaleList, PermSaleReturn,
+	PermSupplyView, PermSupplyApprove,
+	PermAnalyticsView,
+	PermUserManage, PermRoleManage,
+	PermAuditView,
+	PermShiftOperate, PermShiftManage,
+	PermCustomerView, PermCustomerWrite, PermLoyaltyAdjust,
+	PermPayablesView, PermPayablesManage,
+	PermWriteOffCreate, PermWriteOffApprove,
+}
+
+func IsValidPermission(permission string) bool {
+	return contains(Permissions, permission)
+}
+
+// DefaultRolePermissions — разрешения, которые назначаются ролям при первом
+// запуске. Дальше набор редактируется через API ролей.
+var DefaultRolePermissions = map[string][]string{
+	RoleAdmin: Permissions,
+	RoleManager: {
+		PermProductWrite, PermDepartmentWrite, PermSupplierWrite,
+		PermSaleCreate, PermSaleView, PermSaleList, PermSaleReturn,
+		PermSupplyView, PermSupplyApprove,
+		PermAnalyticsView,
+		PermShiftOperate, PermShiftManage,
+		PermCustomerView, PermCustomerWrite, PermLoyaltyAdjust,
+		PermPayablesView, PermPayablesManage,
+		PermWriteOffCreate, PermWriteOffApprove,
+	},
+	RoleCashier: {
+		PermSaleCreate, PermSaleView,
+		PermShiftOperate,
+		PermCustomerView, PermCustomerWrite,
+		PermWriteOffCreate,
+	},
+}
+
+// Permission — разрешение, уже известное базе. Новые разрешения из
+// Permissions при запуске выдаются ролям по умолчанию один раз.
+type Permission struct {
+	Name string `json:"name" gorm:"primaryKey;varchar(50)"`
+}
+
+type RolePermission struct {
+	ID         uint   `json:"id" gorm:"primaryKey"`
+	Role       string `json:"role" gorm:"varchar(20);uniqueIndex:idx_role_permission"`
+	Permission string `json:"permission" gorm:"varchar(50);uniqueIndex:idx_role_permission"`
+}
+
 type User struct {
 	ID        uint      `json:"id" gorm:"primaryKey"`
-	Username  string    `json:"username" gorm:"varchar(50)"`
-	Password  string    `json:"password" gorm:"varchar(255)"`
+	Username  string    `json:"username" gorm:"varchar(50);uniqueIndex"`
+	Password  string    `json:"-" gorm:"varchar(255)"`
 	Role      string    `json:"role" gorm:"varchar(20)"`
+	Disabled  bool      `json:"disabled" gorm:"default:false"`
 	CreatedAt time.Time `json:"created_at" gorm:"timestamp"`
+
+	LastLoginAt         *time.Time `json:"last_login_at" gorm:"timestamp"`
+	FailedLoginAttempts int        `json:"failed_login_attempts" gorm:"int"`
+	LockedUntil         *time.Time `json:"locked_until" gorm:"timestamp"`
+
+	// TokenVersion записывается в выданные токены; увеличение версии
+	// отзывает все ранее выданные токены пользователя.
+	TokenVersion uint `json:"-" gorm:"not null;default:0"`
+
+	// DepartmentID — отдел, в котором работает сотрудник. Дает доступ к
+	// отделу наравне с отделами, где пользователь указан руководителем.
+	DepartmentID *uint `json:"department_id" gorm:"index"`
 }
 
+// Department — отдел магазина. LoyaltyAccrualPercent — сколько процентов
+// от оплаченной деньгами суммы покупки начисляется баллами,
+// LoyaltyRedeemPercent — какую часть покупки можно оплатить баллами.
+// Нулевые значения отключают начисление или списание в отделе.
 type Department struct {
 	ID          uint   `json:"id" gorm:"primaryKey"`
 	Name        string `json:"name" gorm:"varchar(100)"`
 	Description string `json:"description" gorm:"text"`
 	ManagerID   uint   `json:"manager_id" gorm:"bigint"`
+
+	LoyaltyAccrualPercent float64 `json:"loyalty_accrual_percent" gorm:"decimal(5,2);not null;default:0"`
+	LoyaltyRedeemPercent  float64 `json:"loyalty_redeem_percent" gorm:"decimal(5,2);not null;default:0"`
+
+	Version   uint           `json:"version" gorm:"not null;default:1"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"index"`
 }
 
+// Supplier — поставщик. PaymentTermDays — отсрочка оплаты счетов в днях
+// (0 — оплата в день выставления счета).
 type Supplier struct {
-	ID            uint   `json:"id" gorm:"primaryKey"`
-	Name          string `json:"name" gorm:"varchar(100)"`
-	Phone         string `json:"phone" gorm:"varchar(30)"`
-	ContactPerson string `json:"contact_person" gorm:"text"`
+	ID              uint   `json:"id" gorm:"primaryKey"`
+	Name            string `json:"name" gorm:"varchar(100)"`
+	Phone           string `json:"phone" gorm:"varchar(30)"`
+	ContactPerson   string `json:"contact_person" gorm:"text"`
+	PaymentTermDays int    `json:"payment_term_days" gorm:"not null;default:0"`
+
+	Version   uint           `json:"version" gorm:"not null;default:1"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+}
+
+// Сорт товара: A — высший, B — первый, C — второй.
+const (
+	GradeA = "A"
+	GradeB = "B"
+	GradeC = "C"
+)
+
+var Grades = []string{GradeA, GradeB, GradeC}
+
+func IsValidGrade(grade string) bool {
+	return contains(Grades, grade)
+}
+
+// Условия хранения товара.
+const (
+	StorageAmbient = "ambient" // комнатная температура
+	StorageChilled = "chilled" // холодильник, +2…+6 °C
+	StorageFrozen  = "frozen"  // морозильник, ниже -18 °C
+	StorageDry     = "dry"     // сухое темное место
+)
+
+var StorageConditions = []string{StorageAmbient, StorageChilled, StorageFrozen, StorageDry}
+
+func IsValidStorageCond(cond string) bool {
+	return contains(StorageConditions, cond)
+}
+
+// Единица измерения товара. Цена товара относится к упаковке, в которой
+// NetQuantity таких единиц; по ним считается цена за единицу на ценнике.
+const (
+	UnitPiece    = "pcs" // штука
+	UnitKilogram = "kg"  // килограмм
+	UnitLitre    = "l"   // литр
+)
+
+var Units = []string{UnitPiece, UnitKilogram, UnitLitre}
+
+func IsValidUnit(unit string) bool {
+	return contains(Units, unit)
 }
 
 type Product struct {
@@ -37,11 +204,70 @@ type Product struct {
 	MinThreshold int       `json:"min_threshold" gorm:"int"`
 	ExpiryDate   time.Time `json:"expiry_date" gorm:"date"`
 	StorageCond  string    `json:"storage_cond" gorm:"varchar(20)"`
+	Unit         string    `json:"unit" gorm:"varchar(10);not null;default:'pcs'"`
+	NetQuantity  float64   `json:"net_quantity" gorm:"decimal(10,3);not null;default:1"`
+
+	Version   uint           `json:"version" gorm:"not null;default:1"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"index"`
 
-	Department Department `json:"department" gorm:"foreignKey:DepartmentID"`
-	Supplier   Supplier   `json:"supplier" gorm:"foreignKey:SupplierID"`
+	Department Department `json:"department" gorm:"foreignKey:DepartmentID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	Supplier   Supplier   `json:"supplier" gorm:"foreignKey:SupplierID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
 }
 
+// UnitPrice возвращает цену за одну единицу измерения товара.
+func (p *Product) UnitPrice() float64 {
+	if p.NetQuantity <= 0 {
+		return p.Price
+	}
+	return p.Price / p.NetQuantity
+}
+
+// ProductSupplier — товар в каталоге поставщика. Товар можно заказывать у
+// нескольких поставщиков, один из них основной (Preferred); его ID
+// продублирован в Product.SupplierID.
+type ProductSupplier struct {
+	ProductID   uint   `json:"product_id" gorm:"primaryKey;autoIncrement:false"`
+	SupplierID  uint   `json:"supplier_id" gorm:"primaryKey;autoIncrement:false;index"`
+	SupplierSKU string `json:"supplier_sku" gorm:"varchar(50)"`
+	Preferred   bool   `json:"preferred" gorm:"not null;default:false"`
+
+	Product  Product  `json:"-" gorm:"foreignKey:ProductID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Supplier Supplier `json:"-" gorm:"foreignKey:SupplierID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// SupplierPrice — строка прайс-листа: цена упаковки из PackSize единиц
+// товара у поставщика. Цена действует с ValidFrom до следующей строки того
+// же товара; строки не изменяются, а заменяются новыми.
+type SupplierPrice struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	SupplierID uint      `json:"supplier_id" gorm:"bigint;uniqueIndex:idx_supplier_price"`
+	ProductID  uint      `json:"product_id" gorm:"bigint;uniqueIndex:idx_supplier_price;index"`
+	ValidFrom  time.Time `json:"valid_from" gorm:"date;uniqueIndex:idx_supplier_price"`
+	PackSize   int       `json:"pack_size" gorm:"not null;default:1"`
+	Cost       float64   `json:"cost" gorm:"decimal(10,2)"`
+	CreatedBy  uint      `json:"created_by" gorm:"bigint"`
+	CreatedAt  time.Time `json:"created_at"`
+
+	Supplier Supplier `json:"-" gorm:"foreignKey:SupplierID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	Product  Product  `json:"-" gorm:"foreignKey:ProductID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+}
+
+// UnitCost возвращает закупочную цену одной единицы товара.
+func (p *SupplierPrice) UnitCost() float64 {
+	if p.PackSize <= 0 {
+		return p.Cost
+	}
+	return p.Cost / float64(p.PackSize)
+}
+
+// Статус регистрации продажи в фискальном регистраторе (54-ФЗ).
+const (
+	FiscalNone       = "none"       // продажа сделана до подключения фискализации
+	FiscalPending    = "pending"    // ожидает регистрации
+	FiscalRegistered = "registered" // чек зарегистрирован
+	FiscalFailed     = "failed"     // последняя попытка не удалась, будет повтор
+)
+
 type Sale struct {
 	ID         uint      `json:"id" gorm:"primaryKey"`
 	ProductID  uint      `json:"product_id" gorm:"bigint"`
@@ -49,9 +275,215 @@ type Sale struct {
 	TotalPrice float64   `json:"total_price" gorm:"decimal(10,2)"`
 	SaleDate   time.Time `json:"sale_date" gorm:"timestamp"`
 	CashierID  uint      `json:"cashier_id" gorm:"bigint"`
+	ShiftID    *uint     `json:"shift_id" gorm:"bigint;index"`
+	CustomerID *uint     `json:"customer_id" gorm:"bigint;index"`
+
+	// Баллы лояльности: списанные в оплату продажи и начисленные за нее
+	PointsRedeemed int `json:"points_redeemed" gorm:"int;not null;default:0"`
+	PointsEarned   int `json:"points_earned" gorm:"int;not null;default:0"`
+
+	// ReturnedQuantity — сколько единиц товара уже возвращено покупателем
+	ReturnedQuantity int `json:"returned_quantity" gorm:"int;not null;default:0"`
+
+	FiscalStatus         string     `json:"fiscal_status" gorm:"varchar(20);not null;default:'none';index"`
+	FiscalDocumentNumber string     `json:"fiscal_document_number" gorm:"varchar(32)"`
+	FiscalSign           string     `json:"fiscal_sign" gorm:"varchar(32)"`
+	FiscalizedAt         *time.Time `json:"fiscalized_at" gorm:"timestamp"`
+
+	Product  Product   `json:"product" gorm:"foreignKey:ProductID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	Cashier  User      `json:"cashier" gorm:"foreignKey:CashierID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	Shift    *Shift    `json:"-" gorm:"foreignKey:ShiftID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	Customer *Customer `json:"-" gorm:"foreignKey:CustomerID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+
+	Payments []Payment `json:"payments" gorm:"foreignKey:SaleID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+}
+
+// Shift — кассовая смена кассира на кассе. У кассира и у кассы может быть
+// только одна открытая смена. Продажи привязываются к открытой смене
+// кассира; при закрытии фиксируются ожидаемая и пересчитанная сумма
+// наличных в кассе.
+type Shift struct {
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	CashierID    uint       `json:"cashier_id" gorm:"bigint;index:idx_shifts_open_cashier,unique,where:closed_at IS NULL"`
+	Register     string     `json:"register" gorm:"varchar(20);index:idx_shifts_open_register,unique,where:closed_at IS NULL"`
+	OpeningFloat float64    `json:"opening_float" gorm:"decimal(10,2)"`
+	OpenedAt     time.Time  `json:"opened_at" gorm:"timestamp;index"`
+	ClosedAt     *time.Time `json:"closed_at" gorm:"timestamp"`
+	ExpectedCash *float64   `json:"expected_cash" gorm:"decimal(10,2)"`
+	CountedCash  *float64   `json:"counted_cash" gorm:"decimal(10,2)"`
 
-	Product Product `json:"product" gorm:"foreignKey:ProductID"`
-	Cashier User    `json:"cashier" gorm:"foreignKey:CashierID"`
+	Cashier User `json:"cashier" gorm:"foreignKey:CashierID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+}
+
+// Виды движения наличных в кассе вне продаж.
+const (
+	CashIn  = "in"  // внесение (размен, подкрепление кассы)
+	CashOut = "out" // изъятие (инкассация)
+)
+
+var CashMovementTypes = []string{CashIn, CashOut}
+
+func IsValidCashMovementType(movementType string) bool {
+	return contains(CashMovementTypes, movementType)
+}
+
+type CashMovement struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	ShiftID   uint      `json:"shift_id" gorm:"bigint;index"`
+	Type      string    `json:"type" gorm:"varchar(10)"`
+	Amount    float64   `json:"amount" gorm:"decimal(10,2)"`
+	Reason    string    `json:"reason" gorm:"text"`
+	CreatedBy uint      `json:"created_by" gorm:"bigint"`
+	CreatedAt time.Time `json:"created_at"`
+
+	Shift   Shift `json:"-" gorm:"foreignKey:ShiftID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	Creator User  `json:"-" gorm:"foreignKey:CreatedBy;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+}
+
+// Способы оплаты продажи. Баллами (PaymentPoints) оплата создается
+// сервером по запрошенному списанию, клиент передает только наличные и
+// карту.
+const (
+	PaymentCash   = "cash"
+	PaymentCard   = "card"
+	PaymentPoints = "points"
+)
+
+var PaymentMethods = []string{PaymentCash, PaymentCard, PaymentPoints}
+
+// TenderMethods — способы оплаты, которые принимает касса.
+var TenderMethods = []string{PaymentCash, PaymentCard}
+
+func IsValidTenderMethod(method string) bool {
+	return contains(TenderMethods, method)
+}
+
+// Payment — часть оплаты продажи одним способом. Продажа может быть
+// оплачена несколькими платежами (например, частью картой, частью
+// наличными); сумма Amount всех платежей равна стоимости продажи. Для
+// наличных Tendered — полученная от покупателя сумма, ChangeDue — сдача.
+// Для карты сохраняются реквизиты операции платежного терминала.
+type Payment struct {
+	ID        uint    `json:"id" gorm:"primaryKey"`
+	SaleID    uint    `json:"sale_id" gorm:"bigint;index"`
+	Method    string  `json:"method" gorm:"varchar(10);index"`
+	Amount    float64 `json:"amount" gorm:"decimal(10,2)"`
+	Tendered  float64 `json:"tendered" gorm:"decimal(10,2)"`
+	ChangeDue float64 `json:"change_due" gorm:"decimal(10,2)"`
+
+	TerminalReference string `json:"terminal_reference" gorm:"varchar(64)"`
+	TransactionID     string `json:"transaction_id" gorm:"varchar(64)"`
+	AuthCode          string `json:"auth_code" gorm:"varchar(20)"`
+	CardMask          string `json:"card_mask" gorm:"varchar(20)"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SaleReturn — возврат покупателем части товара продажи. Возврат
+// оформляется в открытой смене того, кто его проводит: наличные выдаются из
+// кассы этой смены и уменьшают ожидаемую сумму наличных в ее отчете.
+// Возвращенный товар снова поступает на склад. PointsReversed — баллы,
+// начисленные за возвращенный товар и списанные со счета покупателя.
+// Выплата оформляется чеком возврата прихода, который регистрируется так
+// же, как чек продажи.
+type SaleReturn struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	SaleID         uint      `json:"sale_id" gorm:"bigint;index"`
+	ShiftID        uint      `json:"shift_id" gorm:"bigint;index"`
+	Quantity       int       `json:"quantity" gorm:"int"`
+	Amount         float64   `json:"amount" gorm:"decimal(10,2)"`
+	PointsReversed int       `json:"points_reversed" gorm:"int;not null;default:0"`
+	Reason         string    `json:"reason" gorm:"text"`
+	CreatedBy      uint      `json:"created_by" gorm:"bigint"`
+	CreatedAt      time.Time `json:"created_at" gorm:"index"`
+
+	FiscalStatus         string     `json:"fiscal_status" gorm:"varchar(20);not null;default:'none';index"`
+	FiscalDocumentNumber string     `json:"fiscal_document_number" gorm:"varchar(32)"`
+	FiscalSign           string     `json:"fiscal_sign" gorm:"varchar(32)"`
+	FiscalizedAt         *time.Time `json:"fiscalized_at" gorm:"timestamp"`
+
+	Sale    Sale     `json:"-" gorm:"foreignKey:SaleID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	Shift   Shift    `json:"-" gorm:"foreignKey:ShiftID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	Creator User     `json:"-" gorm:"foreignKey:CreatedBy;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	Refunds []Refund `json:"refunds" gorm:"foreignKey:ReturnID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+}
+
+// Refund — выплата по возврату одним способом оплаты продажи; сумма выплат
+// равна Amount возврата. Возврат на карту проводится через платежный
+// терминал по операции исходного платежа (OriginalTransactionID), баллы
+// возвращаются на бонусный счет покупателя.
+type Refund struct {
+	ID       uint    `json:"id" gorm:"primaryKey"`
+	ReturnID uint    `json:"return_id" gorm:"bigint;index"`
+	Method   string  `json:"method" gorm:"varchar(10);index"`
+	Amount   float64 `json:"amount" gorm:"decimal(10,2)"`
+
+	OriginalTransactionID string `json:"original_transaction_id" gorm:"varchar(64)"`
+	TerminalReference     string `json:"terminal_reference" gorm:"varchar(64)"`
+	TransactionID         string `json:"transaction_id" gorm:"varchar(64)"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Customer — покупатель программы лояльности. Телефон хранится цифрами
+// в международном формате (79991234567). PointsBalance меняется только
+// вместе с записью в LoyaltyTransaction.
+type Customer struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	Name          string    `json:"name" gorm:"varchar(100)"`
+	Phone         string    `json:"phone" gorm:"varchar(20);uniqueIndex"`
+	CardNumber    string    `json:"card_number" gorm:"varchar(20);uniqueIndex"`
+	PointsBalance int       `json:"points_balance" gorm:"int;not null;default:0"`
+	CreatedAt     time.Time `json:"created_at"`
+
+	Version uint `json:"version" gorm:"not null;default:1"`
+}
+
+// Виды операций с баллами лояльности.
+const (
+	LoyaltyAccrual    = "accrual"    // начисление за покупку
+	LoyaltyRedemption = "redemption" // списание в оплату покупки
+	LoyaltyAdjustment = "adjustment" // ручная корректировка
+	LoyaltyReturn     = "return"     // возврат товара: баллы оплаты и начисления
+)
+
+// LoyaltyTransaction — операция по бонусному счету покупателя. Points
+// положительно при начислении и отрицательно при списании, Balance —
+// остаток после операции.
+type LoyaltyTransaction struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	CustomerID uint      `json:"customer_id" gorm:"bigint;index"`
+	SaleID     *uint     `json:"sale_id" gorm:"bigint;index"`
+	Type       string    `json:"type" gorm:"varchar(20)"`
+	Points     int       `json:"points" gorm:"int"`
+	Balance    int       `json:"balance" gorm:"int"`
+	Comment    string    `json:"comment" gorm:"text"`
+	CreatedBy  uint      `json:"created_by" gorm:"bigint"`
+	CreatedAt  time.Time `json:"created_at" gorm:"index"`
+
+	Customer Customer `json:"-" gorm:"foreignKey:CustomerID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	Sale     *Sale    `json:"-" gorm:"foreignKey:SaleID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	Creator  User     `json:"-" gorm:"foreignKey:CreatedBy;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+}
+
+// FiscalOutboxEntry — чек, который еще нужно зарегистрировать в
+// фискальном регистраторе: чек прихода продажи SaleID или, если указан
+// ReturnID, чек возврата прихода по возврату. Запись создается в одной
+// транзакции с продажей или возвратом и удаляется после успешной
+// регистрации, поэтому сбой регистратора или перезапуск сервера не теряют
+// чеки.
+type FiscalOutboxEntry struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`
+	SaleID        uint       `json:"sale_id" gorm:"bigint;uniqueIndex:idx_fiscal_outbox_receipt"`
+	ReturnID      *uint      `json:"return_id" gorm:"bigint;uniqueIndex:idx_fiscal_outbox_receipt"`
+	Attempts      int        `json:"attempts" gorm:"int;not null;default:0"`
+	LastError     string     `json:"last_error" gorm:"text"`
+	NextAttemptAt time.Time  `json:"next_attempt_at" gorm:"timestamp;index"`
+	LockedUntil   *time.Time `json:"locked_until" gorm:"timestamp"`
+	CreatedAt     time.Time  `json:"created_at"`
+
+	Sale   Sale        `json:"-" gorm:"foreignKey:SaleID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Return *SaleReturn `json:"-" gorm:"foreignKey:ReturnID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
 }
 
 type Supply struct {
@@ -61,29 +493,264 @@ type Supply struct {
 	TotalCost  float64   `json:"total_cost" gorm:"decimal(10,2)"`
 	ApprovedBy uint      `json:"approved_by" gorm:"bigint"`
 
-	Supplier Supplier     `json:"supplier" gorm:"foreignKey:SupplierID"`
-	Approver User         `json:"approver" gorm:"foreignKey:ApprovedBy"`
+	Supplier Supplier     `json:"supplier" gorm:"foreignKey:SupplierID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	Approver User         `json:"approver" gorm:"foreignKey:ApprovedBy;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
 	Items    []SupplyItem `json:"items" gorm:"-"`
 }
 
+// SupplyItem — позиция поставки. ExpiryDate — срок годности поступившей
+// партии, если его указали при приемке.
 type SupplyItem struct {
-	ID        uint    `json:"id" gorm:"primaryKey"`
-	SupplyID  uint    `json:"supply_id" gorm:"bigint"`
-	ProductID uint    `json:"product_id" gorm:"bigint"`
-	Quantity  int     `json:"quantity" gorm:"int"`
-	UnitPrice float64 `json:"unit_price" gorm:"decimal(10,2)"`
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	SupplyID   uint       `json:"supply_id" gorm:"bigint"`
+	ProductID  uint       `json:"product_id" gorm:"bigint"`
+	Quantity   int        `json:"quantity" gorm:"int"`
+	UnitPrice  float64    `json:"unit_price" gorm:"decimal(10,2)"`
+	ExpiryDate *time.Time `json:"expiry_date" gorm:"date"`
+
+	Supply  Supply  `json:"supply" gorm:"foreignKey:SupplyID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	Product Product `json:"product" gorm:"foreignKey:ProductID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+}
+
+// Статус счета поставщика.
+const (
+	InvoiceOpen    = "open"    // не оплачен
+	InvoicePartial = "partial" // оплачен частично
+	InvoicePaid    = "paid"    // оплачен полностью
+)
+
+var InvoiceStatuses = []string{InvoiceOpen, InvoicePartial, InvoicePaid}
+
+func IsValidInvoiceStatus(status string) bool {
+	return contains(InvoiceStatuses, status)
+}
+
+// SupplierInvoice — счет поставщика за поставку. Срок оплаты DueDate
+// считается от даты счета по отсрочке поставщика. OverdueNotifiedAt —
+// когда о просрочке счета было отправлено оповещение,
+// OverdueAcknowledgedAt и OverdueAcknowledgedBy — когда и кто его
+// подтвердил.
+type SupplierInvoice struct {
+	ID                uint       `json:"id" gorm:"primaryKey"`
+	SupplierID        uint       `json:"supplier_id" gorm:"bigint;uniqueIndex:idx_supplier_invoice_number"`
+	SupplyID          uint       `json:"supply_id" gorm:"bigint;uniqueIndex"`
+	Number            string     `json:"number" gorm:"varchar(50);uniqueIndex:idx_supplier_invoice_number"`
+	InvoiceDate       time.Time  `json:"invoice_date" gorm:"date"`
+	DueDate           time.Time  `json:"due_date" gorm:"date;index"`
+	Amount            float64    `json:"amount" gorm:"decimal(10,2)"`
+	PaidAmount        float64    `json:"paid_amount" gorm:"decimal(10,2);not null;default:0"`
+	Status            string     `json:"status" gorm:"varchar(10);not null;default:'open';index"`
+	OverdueNotifiedAt *time.Time `json:"overdue_notified_at" gorm:"timestamp"`
+	CreatedBy         uint       `json:"created_by" gorm:"bigint"`
+	CreatedAt         time.Time  `json:"created_at"`
+
+	OverdueAcknowledgedAt *time.Time `json:"overdue_acknowledged_at" gorm:"timestamp"`
+	OverdueAcknowledgedBy *uint      `json:"overdue_acknowledged_by" gorm:"bigint"`
+
+	Supplier Supplier         `json:"supplier" gorm:"foreignKey:SupplierID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	Supply   Supply           `json:"-" gorm:"foreignKey:SupplyID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	Payments []InvoicePayment `json:"payments" gorm:"foreignKey:InvoiceID"`
+}
+
+// Outstanding возвращает неоплаченный остаток счета.
+func (i *SupplierInvoice) Outstanding() float64 {
+	return i.Amount - i.PaidAmount
+}
+
+// Способ оплаты счета поставщика.
+const (
+	InvoicePaymentTransfer = "bank_transfer" // банковский перевод
+	InvoicePaymentCash     = "cash"          // наличными из кассы
+)
+
+var InvoicePaymentMethods = []string{InvoicePaymentTransfer, InvoicePaymentCash}
+
+func IsValidInvoicePaymentMethod(method string) bool {
+	return contains(InvoicePaymentMethods, method)
+}
+
+// InvoicePayment — оплата счета поставщика, полная или частичная.
+type InvoicePayment struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	InvoiceID uint      `json:"invoice_id" gorm:"bigint;index"`
+	Amount    float64   `json:"amount" gorm:"decimal(10,2)"`
+	PaidAt    time.Time `json:"paid_at" gorm:"date"`
+	Method    string    `json:"method" gorm:"varchar(20)"`
+	Reference string    `json:"reference" gorm:"varchar(100)"`
+	CreatedBy uint      `json:"created_by" gorm:"bigint"`
+	CreatedAt time.Time `json:"created_at"`
+
+	Invoice SupplierInvoice `json:"-" gorm:"foreignKey:InvoiceID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+}
+
+// Причина списания товара.
+const (
+	WriteOffBreakage = "breakage" // бой, повреждение упаковки
+	WriteOffSpoilage = "spoilage" // порча, истек срок годности
+	WriteOffTheft    = "theft"    // кража, недостача
+	WriteOffSample   = "sample"   // дегустация, образцы
+	WriteOffOther    = "other"    // прочее, причина указывается в комментарии
+)
+
+var WriteOffReasons = []string{WriteOffBreakage, WriteOffSpoilage, WriteOffTheft, WriteOffSample, WriteOffOther}
+
+func IsValidWriteOffReason(reason string) bool {
+	return contains(WriteOffReasons, reason)
+}
+
+// Статус акта списания.
+const (
+	WriteOffPending  = "pending"  // ждет утверждения
+	WriteOffApproved = "approved" // утвержден, остаток уменьшен
+	WriteOffRejected = "rejected" // отклонен
+)
+
+var WriteOffStatuses = []string{WriteOffPending, WriteOffApproved, WriteOffRejected}
+
+func IsValidWriteOffStatus(status string) bool {
+	return contains(WriteOffStatuses, status)
+}
+
+// WriteOff — акт списания товаров отдела. Остатки уменьшаются, когда акт
+// утверждают; ReviewedBy и ReviewedAt — кто и когда утвердил или отклонил
+// акт. TotalCost — себестоимость списанного на момент утверждения.
+type WriteOff struct {
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	DepartmentID uint       `json:"department_id" gorm:"bigint;index"`
+	Reason       string     `json:"reason" gorm:"varchar(20);index"`
+	Note         string     `json:"note" gorm:"text"`
+	PhotoURL     string     `json:"photo_url" gorm:"varchar(500)"`
+	Status       string     `json:"status" gorm:"varchar(10);not null;default:'pending';index"`
+	TotalCost    float64    `json:"total_cost" gorm:"decimal(10,2);not null;default:0"`
+	CreatedBy    uint       `json:"created_by" gorm:"bigint"`
+	CreatedAt    time.Time  `json:"created_at"`
+	ReviewedBy   *uint      `json:"reviewed_by" gorm:"bigint"`
+	ReviewedAt   *time.Time `json:"reviewed_at" gorm:"timestamp;index"`
+	RejectReason string     `json:"reject_reason" gorm:"text"`
+
+	Department Department     `json:"department" gorm:"foreignKey:DepartmentID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	Items      []WriteOffItem `json:"items" gorm:"foreignKey:WriteOffID"`
+}
+
+// WriteOffItem — строка акта списания. UnitCost и Cost заполняются при
+// утверждении акта.
+type WriteOffItem struct {
+	ID         uint    `json:"id" gorm:"primaryKey"`
+	WriteOffID uint    `json:"write_off_id" gorm:"bigint;index"`
+	ProductID  uint    `json:"product_id" gorm:"bigint;index"`
+	Quantity   int     `json:"quantity" gorm:"int"`
+	UnitCost   float64 `json:"unit_cost" gorm:"decimal(10,2);not null;default:0"`
+	Cost       float64 `json:"cost" gorm:"decimal(10,2);not null;default:0"`
+
+	WriteOff WriteOff `json:"-" gorm:"foreignKey:WriteOffID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Product  Product  `json:"product" gorm:"foreignKey:ProductID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+}
+
+const (
+	AuditCreate = "create"
+	AuditUpdate = "update"
+	AuditDelete = "delete"
+)
+
+// AuditLog — запись журнала изменений. Before/After содержат состояние
+// сущности в JSON, Diff — только измененные поля в виде {"поле": {"from": ..., "to": ...}}.
+type AuditLog struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	ActorID    uint      `json:"actor_id" gorm:"index"`
+	ActorName  string    `json:"actor_name" gorm:"varchar(50)"`
+	Action     string    `json:"action" gorm:"varchar(30)"`
+	EntityType string    `json:"entity_type" gorm:"varchar(30);index:idx_audit_entity"`
+	EntityID   uint      `json:"entity_id" gorm:"index:idx_audit_entity"`
+	Before     string    `json:"before" gorm:"text"`
+	After      string    `json:"after" gorm:"text"`
+	Diff       string    `json:"diff" gorm:"text"`
+	IP         string    `json:"ip" gorm:"varchar(45)"`
+	RequestID  string    `json:"request_id" gorm:"varchar(64);index"`
+	CreatedAt  time.Time `json:"created_at" gorm:"index"`
+}
+
+// AuditFilter — параметры выборки журнала изменений. Нулевые значения не фильтруют.
+type AuditFilter struct {
+	ActorID    uint
+	Action     string
+	EntityType string
+	EntityID   uint
+	RequestID  string
+	Start      *time.Time
+	End        *time.Time
+	Limit      int
+	Offset     int
+}
+
+// ShiftFilter — параметры выборки смен. Нулевые значения не фильтруют.
+type ShiftFilter struct {
+	CashierID uint
+	Register  string
+	Open      *bool
+	Limit     int
+	Offset    int
+}
+
+// Разрезы аналитики продаж: по дням, неделям (с понедельника), месяцам,
+// часам суток за весь период, отделам, кассирам и товарам.
+const (
+	SalesByDay        = "day"
+	SalesByWeek       = "week"
+	SalesByMonth      = "month"
+	SalesByHour       = "hour"
+	SalesByDepartment = "department"
+	SalesByCashier    = "cashier"
+	SalesByProduct    = "product"
+)
+
+var SalesGroupings = []string{SalesByDay, SalesByWeek, SalesByMonth, SalesByHour, SalesByDepartment, SalesByCashier, SalesByProduct}
+
+func IsValidSalesGrouping(groupBy string) bool {
+	return contains(SalesGroupings, groupBy)
+}
+
+// InvoiceFilter — параметры выборки счетов поставщиков. Нулевые значения
+// не фильтруют; Overdue оставляет только неоплаченные счета с истекшим
+// сроком оплаты.
+type InvoiceFilter struct {
+	SupplierID uint
+	Status     string
+	Overdue    bool
+	Limit      int
+	Offset     int
+}
+
+// WriteOffFilter — параметры выборки актов списания. Нулевые значения не
+// фильтруют; DepartmentIDs ограничивает выборку отделами (nil — все).
+type WriteOffFilter struct {
+	DepartmentID  uint
+	DepartmentIDs []uint
+	Status        string
+	Reason        string
+	Limit         int
+	Offset        int
+}
 
-	Supply  Supply  `json:"supply" gorm:"foreignKey:SupplyID"`
-	Product Product `json:"product" gorm:"foreignKey:ProductID"`
+// CustomerFilter — поиск покупателей. Query ищется в имени, телефоне и
+// номере карты. Phone — начало телефона в формате хранения, если запрос
+// похож на номер, набранный через 8.
+type CustomerFilter struct {
+	Query  string
+	Phone  string
+	Limit  int
+	Offset int
 }
 
-type LoginRequest struct {
-	Username string `json:"username" binding:"required"`
-	Password string `json:"password" binding:"required"`
+type RolePermissions struct {
+	Role        string   `json:"role"`
+	Permissions []string `json:"permissions"`
 }
 
-type RegisterRequest struct {
-	Username string `json:"username" binding:"required"`
-	Password string `json:"password" binding:"required"`
-	Role     string `json:"role" binding:"required"`
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
 }
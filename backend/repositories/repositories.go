@@ -1,17 +1,52 @@
 package repositories
 
 import (
+	"errors"
+	"fmt"
+	"time"
+
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 	"grocery-store-api/models"
 )
 
+// ErrStaleVersion возвращается, когда запись изменилась после того, как
+// ее версия была прочитана.
+var ErrStaleVersion = errors.New("запись изменена другим запросом")
+
+// updateVersioned сохраняет все поля записи (включая нулевые), только если
+// ее версия в базе равна version, и увеличивает версию. Связанные объекты
+// не сохраняются.
+func updateVersioned(db *gorm.DB, value interface{}, version uint) error {
+	result := db.Model(value).
+		Select("*").
+		Omit("id", "deleted_at", clause.Associations).
+		Where("version = ?", version).
+		Updates(value)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrStaleVersion
+	}
+	return nil
+}
+
+// batchSize — размер пачки при потоковом чтении больших выборок (Each*).
+const batchSize = 500
+
+// unscoped используется в Preload, чтобы архивные (мягко удаленные)
+// товары, отделы и поставщики оставались видны в исторических записях.
+func unscoped(db *gorm.DB) *gorm.DB {
+	return db.Unscoped()
+}
+
 type UserRepository struct {
 	DB *gorm.DB
 }
 
 func (r *UserRepository) Create(user *models.User) error {
-	return r.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(user).Error
+	return r.DB.Create(user).Error
 }
 
 func (r *UserRepository) FindByID(id uint) (*models.User, error) {
@@ -26,56 +61,307 @@ func (r *UserRepository) FindByUsername(username string) (*models.User, error) {
 	return &user, err
 }
 
-func (r *UserRepository) Update(user *models.User) error {
-	return r.DB.Updates(user).Error
+func (r *UserRepository) FindAll() ([]models.User, error) {
+	var users []models.User
+	err := r.DB.Order("id").Find(&users).Error
+	return users, err
+}
+
+func (r *UserRepository) Count() (int64, error) {
+	var count int64
+	err := r.DB.Model(&models.User{}).Count(&count).Error
+	return count, err
+}
+
+// UpdateFields меняет только столбцы fields пользователя, поэтому
+// параллельные изменения других полей (пароля, версии токенов, счетчиков
+// входа) не затираются. Значениями могут быть выражения, например
+// gorm.Expr("token_version + 1").
+func (r *UserRepository) UpdateFields(id uint, fields map[string]interface{}) error {
+	return r.DB.Model(&models.User{}).Where("id = ?", id).Updates(fields).Error
+}
+
+// RecordLoginFailure увеличивает счетчик неудачных входов и, когда он
+// достигает maxFailures, блокирует вход до lockUntil и обнуляет счетчик.
+// Счетчик меняется в базе, а не по прочитанной записи, поэтому
+// параллельные попытки не теряются и не затирают другие поля.
+func (r *UserRepository) RecordLoginFailure(id uint, maxFailures int, lockUntil time.Time) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.User{}).Where("id = ?", id).
+			Update("failed_login_attempts", gorm.Expr("failed_login_attempts + 1")).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&models.User{}).
+			Where("id = ? AND failed_login_attempts >= ?", id, maxFailures).
+			Updates(map[string]interface{}{
+				"failed_login_attempts": 0,
+				"locked_until":          lockUntil,
+			}).Error
+	})
+}
+
+// RecordLoginSuccess сбрасывает счетчик неудачных входов и блокировку и
+// записывает время входа, не трогая остальные поля.
+func (r *UserRepository) RecordLoginSuccess(id uint, at time.Time) error {
+	return r.DB.Model(&models.User{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"failed_login_attempts": 0,
+		"locked_until":          nil,
+		"last_login_at":         at,
+	}).Error
 }
 
 func (r *UserRepository) Delete(id uint) error {
 	return r.DB.Delete(&models.User{}, id).Error
 }
 
+type RolePermissionRepository struct {
+	DB *gorm.DB
+}
+
+func (r *RolePermissionRepository) FindAll() ([]models.RolePermission, error) {
+	var permissions []models.RolePermission
+	err := r.DB.Order("role, permission").Find(&permissions).Error
+	return permissions, err
+}
+
+func (r *RolePermissionRepository) FindByRole(role string) ([]models.RolePermission, error) {
+	var permissions []models.RolePermission
+	err := r.DB.Where("role = ?", role).Order("permission").Find(&permissions).Error
+	return permissions, err
+}
+
+func (r *RolePermissionRepository) CountMatching(role string, permissions []string) (int64, error) {
+	var count int64
+	err := r.DB.Model(&models.RolePermission{}).Where("role = ? AND permission IN ?", role, permissions).Count(&count).Error
+	return count, err
+}
+
+// ReplaceForRole атомарно заменяет набор разрешений роли.
+func (r *RolePermissionRepository) ReplaceForRole(role string, permissions []string) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("role = ?", role).Delete(&models.RolePermission{}).Error; err != nil {
+			return err
+		}
+		for _, permission := range permissions {
+			rp := models.RolePermission{Role: role, Permission: permission}
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&rp).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *RolePermissionRepository) FindKnownPermissions() ([]models.Permission, error) {
+	var permissions []models.Permission
+	err := r.DB.Find(&permissions).Error
+	return permissions, err
+}
+
+// AddPermission регистрирует новое разрешение и выдает его перечисленным ролям.
+func (r *RolePermissionRepository) AddPermission(permission string, roles []string) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.Permission{Name: permission}).Error; err != nil {
+			return err
+		}
+		for _, role := range roles {
+			rp := models.RolePermission{Role: role, Permission: permission}
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&rp).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+type AuditRepository struct {
+	DB *gorm.DB
+}
+
+func (r *AuditRepository) Create(entry *models.AuditLog) error {
+	return r.DB.Create(entry).Error
+}
+
+func (r *AuditRepository) Find(filter models.AuditFilter) ([]models.AuditLog, error) {
+	query := r.DB.Model(&models.AuditLog{})
+	if filter.ActorID != 0 {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.EntityType != "" {
+		query = query.Where("entity_type = ?", filter.EntityType)
+	}
+	if filter.EntityID != 0 {
+		query = query.Where("entity_id = ?", filter.EntityID)
+	}
+	if filter.RequestID != "" {
+		query = query.Where("request_id = ?", filter.RequestID)
+	}
+	if filter.Start != nil {
+		query = query.Where("created_at >= ?", *filter.Start)
+	}
+	if filter.End != nil {
+		query = query.Where("created_at < ?", *filter.End)
+	}
+
+	var entries []models.AuditLog
+	err := query.Order("id DESC").Limit(filter.Limit).Offset(filter.Offset).Find(&entries).Error
+	return entries, err
+}
+
 type ProductRepository struct {
 	DB *gorm.DB
 }
 
+// Create сохраняет товар и делает его поставщика основным в каталоге.
 func (r *ProductRepository) Create(product *models.Product) error {
-	return r.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(product).Error
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(product).Error; err != nil {
+			return err
+		}
+		return linkPreferred(tx, product.ID, product.SupplierID)
+	})
+}
+
+// CreateAll создает все товары в одной транзакции: при ошибке не сохраняется ни одна запись.
+func (r *ProductRepository) CreateAll(products []models.Product) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.CreateInBatches(products, 100).Error; err != nil {
+			return err
+		}
+		for _, product := range products {
+			if err := linkPreferred(tx, product.ID, product.SupplierID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 }
 
 func (r *ProductRepository) FindByID(id uint) (*models.Product, error) {
 	var product models.Product
-	err := r.DB.Preload("Department").Preload("Supplier").First(&product, id).Error
+	err := r.DB.Preload("Department", unscoped).Preload("Supplier", unscoped).First(&product, id).Error
 	return &product, err
 }
 
+func (r *ProductRepository) FindByIDs(ids []uint) ([]models.Product, error) {
+	var products []models.Product
+	err := r.DB.Preload("Department", unscoped).Preload("Supplier", unscoped).Where("id IN ?", ids).Find(&products).Error
+	return products, err
+}
+
 func (r *ProductRepository) FindAll() ([]models.Product, error) {
 	var products []models.Product
-	err := r.DB.Preload("Department").Preload("Supplier").Find(&products).Error
+	err := r.DB.Preload("Department", unscoped).Preload("Supplier", unscoped).Find(&products).Error
 	return products, err
 }
 
-func (r *ProductRepository) Update(product *models.Product) error {
-	return r.DB.Updates(product).Error
+// Update сохраняет товар целиком при совпадении версии, см. updateVersioned.
+// Поставщик товара становится основным в каталоге.
+func (r *ProductRepository) Update(product *models.Product, version uint) error {
+	product.Version = version + 1
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := updateVersioned(tx, product, version); err != nil {
+			return err
+		}
+		return linkPreferred(tx, product.ID, product.SupplierID)
+	})
+}
+
+// SetGrade меняет сорт товара и увеличивает версию, не трогая остальные
+// поля.
+func (r *ProductRepository) SetGrade(id uint, grade string) error {
+	return r.DB.Model(&models.Product{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"grade": grade, "version": gorm.Expr("version + 1")}).Error
 }
 
 func (r *ProductRepository) Delete(id uint) error {
 	return r.DB.Delete(&models.Product{}, id).Error
 }
 
+// FindArchivedByID находит запись, в том числе архивную.
+func (r *ProductRepository) FindArchivedByID(id uint) (*models.Product, error) {
+	var product models.Product
+	err := r.DB.Unscoped().Preload("Department", unscoped).Preload("Supplier", unscoped).First(&product, id).Error
+	return &product, err
+}
+
+func (r *ProductRepository) FindArchived() ([]models.Product, error) {
+	var products []models.Product
+	err := r.DB.Unscoped().Preload("Department", unscoped).Preload("Supplier", unscoped).Where("deleted_at IS NOT NULL").Find(&products).Error
+	return products, err
+}
+
+func (r *ProductRepository) Restore(id uint) error {
+	return r.DB.Unscoped().Model(&models.Product{}).Where("id = ?", id).Update("deleted_at", nil).Error
+}
+
 func (r *ProductRepository) FindByDepartment(departmentID uint) ([]models.Product, error) {
 	var products []models.Product
-	err := r.DB.Preload("Department").Preload("Supplier").Where("department_id = ?", departmentID).Find(&products).Error
+	err := r.DB.Preload("Department", unscoped).Preload("Supplier", unscoped).Where("department_id = ?", departmentID).Find(&products).Error
+	return products, err
+}
+
+func (r *ProductRepository) FindByDepartments(departmentIDs []uint) ([]models.Product, error) {
+	var products []models.Product
+	err := r.DB.Preload("Department", unscoped).Preload("Supplier", unscoped).Where("department_id IN ?", departmentIDs).Find(&products).Error
 	return products, err
 }
 
 func (r *ProductRepository) FindBySupplier(supplierID uint) ([]models.Product, error) {
 	var products []models.Product
-	err := r.DB.Preload("Department").Preload("Supplier").Where("supplier_id = ?", supplierID).Find(&products).Error
+	err := r.DB.Preload("Department", unscoped).Preload("Supplier", unscoped).Where("supplier_id = ?", supplierID).Find(&products).Error
 	return products, err
 }
 
+// Each передает в fn активные товары пачками, не загружая выборку
+// целиком. departmentIDs ограничивает товары отделами; nil — все отделы.
+func (r *ProductRepository) Each(departmentIDs []uint, fn func([]models.Product) error) error {
+	return r.each(r.DB, departmentIDs, fn)
+}
+
+// EachLowStock работает как Each, но только для товаров, остаток которых
+// не выше минимального.
+func (r *ProductRepository) EachLowStock(departmentIDs []uint, fn func([]models.Product) error) error {
+	return r.each(r.DB.Where("current_qty <= min_threshold"), departmentIDs, fn)
+}
+
+func (r *ProductRepository) each(query *gorm.DB, departmentIDs []uint, fn func([]models.Product) error) error {
+	if departmentIDs != nil {
+		query = query.Where("department_id IN ?", departmentIDs)
+	}
+
+	var products []models.Product
+	return query.Preload("Department", unscoped).Preload("Supplier", unscoped).
+		FindInBatches(&products, batchSize, func(*gorm.DB, int) error {
+			return fn(products)
+		}).Error
+}
+
+// CountByDepartment считает активные (не архивные) товары отдела.
+func (r *ProductRepository) CountByDepartment(departmentID uint) (int64, error) {
+	var count int64
+	err := r.DB.Model(&models.Product{}).Where("department_id = ?", departmentID).Count(&count).Error
+	return count, err
+}
+
+// CountBySupplier считает активные (не архивные) товары поставщика.
+func (r *ProductRepository) CountBySupplier(supplierID uint) (int64, error) {
+	var count int64
+	err := r.DB.Model(&models.Product{}).Where("supplier_id = ?", supplierID).Count(&count).Error
+	return count, err
+}
+
 func (r *ProductRepository) UpdateStock(id uint, quantity int) error {
-	return r.DB.Model(&models.Product{}).Where("id = ?", id).Update("current_qty", gorm.Expr("current_qty + ?", quantity)).Error
+	// Изменение остатка тоже меняет версию, чтобы правка товара по старым
+	// данным не перезаписала остаток после продажи или поставки
+	return r.DB.Model(&models.Product{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"current_qty": gorm.Expr("current_qty + ?", quantity),
+		"version":     gorm.Expr("version + 1"),
+	}).Error
 }
 
 type DepartmentRepository struct {
@@ -98,14 +384,39 @@ func (r *DepartmentRepository) FindAll() ([]models.Department, error) {
 	return departments, err
 }
 
-func (r *DepartmentRepository) Update(department *models.Department) error {
-	return r.DB.Updates(department).Error
+func (r *DepartmentRepository) FindByManager(managerID uint) ([]models.Department, error) {
+	var departments []models.Department
+	err := r.DB.Where("manager_id = ?", managerID).Find(&departments).Error
+	return departments, err
+}
+
+// Update сохраняет отдел целиком при совпадении версии, см. updateVersioned.
+func (r *DepartmentRepository) Update(department *models.Department, version uint) error {
+	department.Version = version + 1
+	return updateVersioned(r.DB, department, version)
 }
 
 func (r *DepartmentRepository) Delete(id uint) error {
 	return r.DB.Delete(&models.Department{}, id).Error
 }
 
+// FindArchivedByID находит запись, в том числе архивную.
+func (r *DepartmentRepository) FindArchivedByID(id uint) (*models.Department, error) {
+	var department models.Department
+	err := r.DB.Unscoped().First(&department, id).Error
+	return &department, err
+}
+
+func (r *DepartmentRepository) FindArchived() ([]models.Department, error) {
+	var departments []models.Department
+	err := r.DB.Unscoped().Where("deleted_at IS NOT NULL").Find(&departments).Error
+	return departments, err
+}
+
+func (r *DepartmentRepository) Restore(id uint) error {
+	return r.DB.Unscoped().Model(&models.Department{}).Where("id = ?", id).Update("deleted_at", nil).Error
+}
+
 type SupplierRepository struct {
 	DB *gorm.DB
 }
@@ -114,6 +425,13 @@ func (r *SupplierRepository) Create(supplier *models.Supplier) error {
 	return r.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(supplier).Error
 }
 
+// CreateAll создает все поставщики в одной транзакции: при ошибке не сохраняется ни одна запись.
+func (r *SupplierRepository) CreateAll(suppliers []models.Supplier) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		return tx.CreateInBatches(suppliers, 100).Error
+	})
+}
+
 func (r *SupplierRepository) FindByID(id uint) (*models.Supplier, error) {
 	var supplier models.Supplier
 	err := r.DB.First(&supplier, id).Error
@@ -126,40 +444,1028 @@ func (r *SupplierRepository) FindAll() ([]models.Supplier, error) {
 	return suppliers, err
 }
 
-func (r *SupplierRepository) Update(supplier *models.Supplier) error {
-	return r.DB.Updates(supplier).Error
+// Update сохраняет поставщика целиком при совпадении версии, см. updateVersioned.
+func (r *SupplierRepository) Update(supplier *models.Supplier, version uint) error {
+	supplier.Version = version + 1
+	return updateVersioned(r.DB, supplier, version)
 }
 
 func (r *SupplierRepository) Delete(id uint) error {
 	return r.DB.Delete(&models.Supplier{}, id).Error
 }
 
+// FindArchivedByID находит запись, в том числе архивную.
+func (r *SupplierRepository) FindArchivedByID(id uint) (*models.Supplier, error) {
+	var supplier models.Supplier
+	err := r.DB.Unscoped().First(&supplier, id).Error
+	return &supplier, err
+}
+
+func (r *SupplierRepository) FindArchived() ([]models.Supplier, error) {
+	var suppliers []models.Supplier
+	err := r.DB.Unscoped().Where("deleted_at IS NOT NULL").Find(&suppliers).Error
+	return suppliers, err
+}
+
+func (r *SupplierRepository) Restore(id uint) error {
+	return r.DB.Unscoped().Model(&models.Supplier{}).Where("id = ?", id).Update("deleted_at", nil).Error
+}
+
+// linkPreferred добавляет поставщика в каталог товара, если его там нет,
+// и делает его основным.
+func linkPreferred(tx *gorm.DB, productID, supplierID uint) error {
+	if supplierID == 0 {
+		return nil
+	}
+
+	link := models.ProductSupplier{ProductID: productID, SupplierID: supplierID, Preferred: true}
+	err := tx.Omit(clause.Associations).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "product_id"}, {Name: "supplier_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"preferred": true}),
+	}).Create(&link).Error
+	if err != nil {
+		return err
+	}
+
+	return tx.Model(&models.ProductSupplier{}).
+		Where("product_id = ? AND supplier_id <> ? AND preferred", productID, supplierID).
+		Update("preferred", false).Error
+}
+
+type ProductSupplierRepository struct {
+	DB *gorm.DB
+}
+
+func (r *ProductSupplierRepository) Find(productID, supplierID uint) (*models.ProductSupplier, error) {
+	var link models.ProductSupplier
+	err := r.DB.Where("product_id = ? AND supplier_id = ?", productID, supplierID).First(&link).Error
+	return &link, err
+}
+
+// FindByProducts возвращает поставщиков товаров, кроме архивных.
+func (r *ProductSupplierRepository) FindByProducts(productIDs []uint) ([]models.ProductSupplier, error) {
+	var links []models.ProductSupplier
+	err := r.DB.InnerJoins("Supplier").
+		Where("product_suppliers.product_id IN ?", productIDs).
+		Order("product_suppliers.product_id, product_suppliers.supplier_id").
+		Find(&links).Error
+	return links, err
+}
+
+// FindBySupplier возвращает каталог поставщика без архивных товаров.
+func (r *ProductSupplierRepository) FindBySupplier(supplierID uint) ([]models.ProductSupplier, error) {
+	var links []models.ProductSupplier
+	err := r.DB.InnerJoins("Product").
+		Where("product_suppliers.supplier_id = ?", supplierID).
+		Order("Product__name, product_suppliers.product_id").
+		Find(&links).Error
+	return links, err
+}
+
+// Save добавляет товар в каталог поставщика или меняет его артикул.
+// Признак основного поставщика здесь не меняется, см. SetPreferred.
+func (r *ProductSupplierRepository) Save(link *models.ProductSupplier) error {
+	return r.DB.Omit(clause.Associations).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "product_id"}, {Name: "supplier_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"supplier_sku"}),
+	}).Create(link).Error
+}
+
+// SetPreferred делает поставщика основным для товара и записывает его в
+// товар. Версия товара увеличивается, как при любом его изменении.
+func (r *ProductSupplierRepository) SetPreferred(productID, supplierID uint) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := linkPreferred(tx, productID, supplierID); err != nil {
+			return err
+		}
+		return tx.Model(&models.Product{}).Where("id = ?", productID).
+			Updates(map[string]interface{}{"supplier_id": supplierID, "version": gorm.Expr("version + 1")}).Error
+	})
+}
+
+func (r *ProductSupplierRepository) Delete(productID, supplierID uint) error {
+	return r.DB.Where("product_id = ? AND supplier_id = ?", productID, supplierID).Delete(&models.ProductSupplier{}).Error
+}
+
+type SupplierPriceRepository struct {
+	DB *gorm.DB
+}
+
+// SavePriceList в одной транзакции добавляет товары прайс-листа в каталог
+// поставщика (артикул обновляется, если указан) и сохраняет цены. Цена с
+// той же датой начала действия заменяется.
+func (r *SupplierPriceRepository) SavePriceList(links []models.ProductSupplier, prices []models.SupplierPrice) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		for i := range links {
+			onConflict := clause.OnConflict{
+				Columns:   []clause.Column{{Name: "product_id"}, {Name: "supplier_id"}},
+				DoNothing: true,
+			}
+			if links[i].SupplierSKU != "" {
+				onConflict.DoNothing = false
+				onConflict.DoUpdates = clause.AssignmentColumns([]string{"supplier_sku"})
+			}
+			if err := tx.Omit(clause.Associations).Clauses(onConflict).Create(&links[i]).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Omit(clause.Associations).Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "supplier_id"}, {Name: "product_id"}, {Name: "valid_from"}},
+			DoUpdates: clause.AssignmentColumns([]string{"pack_size", "cost", "created_by", "created_at"}),
+		}).Create(&prices).Error
+	})
+}
+
+// Current возвращает цены, действующие на момент asOf (не включая его):
+// для каждой пары поставщик–товар последнюю строку, начавшую действовать
+// раньше asOf. Нулевой supplierID и пустой productIDs не фильтруют.
+func (r *SupplierPriceRepository) Current(asOf time.Time, supplierID uint, productIDs []uint) ([]models.SupplierPrice, error) {
+	query := r.DB.Where("supplier_prices.valid_from < ?", asOf).
+		Where(`NOT EXISTS (SELECT 1 FROM supplier_prices AS n
+			WHERE n.supplier_id = supplier_prices.supplier_id AND n.product_id = supplier_prices.product_id
+			AND n.valid_from < ? AND n.valid_from > supplier_prices.valid_from)`, asOf)
+	if supplierID != 0 {
+		query = query.Where("supplier_prices.supplier_id = ?", supplierID)
+	}
+	if len(productIDs) > 0 {
+		query = query.Where("supplier_prices.product_id IN ?", productIDs)
+	}
+
+	var prices []models.SupplierPrice
+	err := query.Order("supplier_prices.product_id, supplier_prices.supplier_id").Find(&prices).Error
+	return prices, err
+}
+
+// FindHistory возвращает все цены товара у поставщика, новые первыми.
+func (r *SupplierPriceRepository) FindHistory(supplierID, productID uint) ([]models.SupplierPrice, error) {
+	var prices []models.SupplierPrice
+	err := r.DB.Where("supplier_id = ? AND product_id = ?", supplierID, productID).
+		Order("valid_from DESC").
+		Find(&prices).Error
+	return prices, err
+}
+
 type SaleRepository struct {
 	DB *gorm.DB
 }
 
+// Create сохраняет продажу и в той же транзакции уменьшает остаток товара,
+// ставит продажу в очередь на фискальную регистрацию и проводит по
+// бонусному счету покупателя списанные и начисленные баллы. Если остатка
+// не хватает, возвращается *InsufficientStockError, если баллов —
+// ErrInsufficientPoints; продажа при этом не сохраняется.
 func (r *SaleRepository) Create(sale *models.Sale) error {
-	return r.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(sale).Error
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Product{}).
+			Where("id = ? AND current_qty >= ?", sale.ProductID, sale.Quantity).
+			Updates(map[string]interface{}{
+				"current_qty": gorm.Expr("current_qty - ?", sale.Quantity),
+				"version":     gorm.Expr("version + 1"),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return &InsufficientStockError{ProductID: sale.ProductID}
+		}
+
+		sale.FiscalStatus = models.FiscalPending
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(sale).Error; err != nil {
+			return err
+		}
+
+		if sale.CustomerID != nil {
+			entries := []models.LoyaltyTransaction{
+				{Type: models.LoyaltyRedemption, Points: -sale.PointsRedeemed},
+				{Type: models.LoyaltyAccrual, Points: sale.PointsEarned},
+			}
+			for i := range entries {
+				if entries[i].Points == 0 {
+					continue
+				}
+				entries[i].CustomerID = *sale.CustomerID
+				entries[i].SaleID = &sale.ID
+				entries[i].CreatedBy = sale.CashierID
+				if err := addPoints(tx, &entries[i]); err != nil {
+					return err
+				}
+			}
+		}
+
+		return tx.Create(&models.FiscalOutboxEntry{SaleID: sale.ID, NextAttemptAt: time.Now()}).Error
+	})
+}
+
+// MarkFiscalized сохраняет реквизиты фискального документа и убирает
+// продажу из очереди.
+func (r *SaleRepository) MarkFiscalized(sale *models.Sale) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(sale).Select("FiscalStatus", "FiscalDocumentNumber", "FiscalSign", "FiscalizedAt").Updates(sale).Error
+		if err != nil {
+			return err
+		}
+		return receiptEntry(tx, sale.ID, nil).Delete(&models.FiscalOutboxEntry{}).Error
+	})
+}
+
+func (r *SaleRepository) UpdateFiscalStatus(id uint, status string) error {
+	return r.DB.Model(&models.Sale{}).Where("id = ?", id).Update("fiscal_status", status).Error
 }
 
 func (r *SaleRepository) FindByID(id uint) (*models.Sale, error) {
 	var sale models.Sale
-	err := r.DB.Preload("Product").Preload("Cashier").First(&sale, id).Error
+	err := r.DB.Preload("Product", unscoped).Preload("Cashier").Preload("Payments").First(&sale, id).Error
 	return &sale, err
 }
 
 func (r *SaleRepository) FindAll() ([]models.Sale, error) {
 	var sales []models.Sale
-	err := r.DB.Preload("Product").Preload("Cashier").Find(&sales).Error
+	err := r.DB.Preload("Product", unscoped).Preload("Cashier").Preload("Payments").Find(&sales).Error
 	return sales, err
 }
 
 func (r *SaleRepository) FindByDateRange(start, end string) ([]models.Sale, error) {
 	var sales []models.Sale
-	err := r.DB.Preload("Product").Preload("Cashier").Where("sale_date BETWEEN ? AND ?", start, end).Find(&sales).Error
+	err := r.DB.Preload("Product", unscoped).Preload("Cashier").Preload("Payments").Where("sale_date BETWEEN ? AND ?", start, end).Find(&sales).Error
 	return sales, err
 }
 
+// EachInPeriod передает в fn продажи за период [from, to) пачками, не
+// загружая выборку целиком. departmentIDs ограничивает продажи товарами
+// этих отделов; nil — все отделы.
+func (r *SaleRepository) EachInPeriod(from, to time.Time, departmentIDs []uint, fn func([]models.Sale) error) error {
+	query := r.DB.Preload("Product", unscoped).Preload("Product.Department", unscoped).Preload("Cashier").
+		Where("sales.sale_date >= ? AND sales.sale_date < ?", from, to)
+	if departmentIDs != nil {
+		query = query.Joins("JOIN products ON products.id = sales.product_id").
+			Where("products.department_id IN ?", departmentIDs)
+	}
+
+	var sales []models.Sale
+	return query.FindInBatches(&sales, batchSize, func(*gorm.DB, int) error {
+		return fn(sales)
+	}).Error
+}
+
+// SalesTotal — количество и сумма продаж.
+type SalesTotal struct {
+	Count int64
+	Total float64
+}
+
+// TotalByShift считает продажи смены.
+func (r *SaleRepository) TotalByShift(shiftID uint) (SalesTotal, error) {
+	var total SalesTotal
+	err := r.DB.Model(&models.Sale{}).
+		Select("COUNT(*) AS count, COALESCE(SUM(total_price), 0) AS total").
+		Where("shift_id = ?", shiftID).
+		Scan(&total).Error
+	return total, err
+}
+
+// CustomerSalesStats — продажи за период в разрезе покупателей. Продажа
+// считается повторной, если у ее покупателя была более ранняя продажа
+// (в том числе до начала периода).
+type CustomerSalesStats struct {
+	SalesCount        int64
+	TotalRevenue      float64
+	IdentifiedSales   int64
+	IdentifiedRevenue float64
+	Customers         int64
+	RepeatSales       int64
+	RepeatRevenue     float64
+	RepeatCustomers   int64
+	PointsEarned      int64
+	PointsRedeemed    int64
+}
+
+// CustomerRevenue — выручка от одного покупателя за период.
+type CustomerRevenue struct {
+	CustomerID uint
+	Name       string
+	Phone      string
+	SalesCount int64
+	Revenue    float64
+}
+
+// saleNetQuantity и saleNetRevenue — количество и выручка продажи s за
+// вычетом оформленных по ней возвратов.
+const (
+	saleNetQuantity = "(s.quantity - s.returned_quantity)"
+	saleNetRevenue  = "(s.total_price - COALESCE((SELECT SUM(sr.amount) FROM sale_returns AS sr WHERE sr.sale_id = s.id), 0))"
+)
+
+// periodSales выбирает продажи за период [from, to) по товарам отделов
+// departmentIDs; nil — все отделы. Таблица продаж доступна как s, товаров
+// — как products. Количество и выручку отчеты считают за вычетом
+// возвратов (saleNetQuantity, saleNetRevenue).
+func (r *SaleRepository) periodSales(from, to time.Time, departmentIDs []uint) *gorm.DB {
+	query := r.DB.Table("sales AS s").
+		Joins("JOIN products ON products.id = s.product_id").
+		Where("s.sale_date >= ? AND s.sale_date < ?", from, to)
+	if departmentIDs != nil {
+		query = query.Where("products.department_id IN ?", departmentIDs)
+	}
+	return query
+}
+
+func (r *SaleRepository) CustomerStats(from, to time.Time, departmentIDs []uint) (CustomerSalesStats, error) {
+	const repeat = "s.customer_id IS NOT NULL AND EXISTS (SELECT 1 FROM sales AS p WHERE p.customer_id = s.customer_id AND p.id < s.id)"
+
+	var stats CustomerSalesStats
+	err := r.periodSales(from, to, departmentIDs).
+		Select(`COUNT(*) AS sales_count,
+			COALESCE(SUM(` + saleNetRevenue + `), 0) AS total_revenue,
+			COUNT(s.customer_id) AS identified_sales,
+			COALESCE(SUM(CASE WHEN s.customer_id IS NOT NULL THEN ` + saleNetRevenue + ` END), 0) AS identified_revenue,
+			COUNT(DISTINCT s.customer_id) AS customers,
+			COALESCE(SUM(CASE WHEN ` + repeat + ` THEN 1 END), 0) AS repeat_sales,
+			COALESCE(SUM(CASE WHEN ` + repeat + ` THEN ` + saleNetRevenue + ` END), 0) AS repeat_revenue,
+			COUNT(DISTINCT CASE WHEN ` + repeat + ` THEN s.customer_id END) AS repeat_customers,
+			COALESCE(SUM(s.points_earned), 0) AS points_earned,
+			COALESCE(SUM(s.points_redeemed), 0) AS points_redeemed`).
+		Scan(&stats).Error
+	return stats, err
+}
+
+// TopCustomers возвращает покупателей с наибольшей выручкой за период.
+func (r *SaleRepository) TopCustomers(from, to time.Time, departmentIDs []uint, limit int) ([]CustomerRevenue, error) {
+	var rows []CustomerRevenue
+	err := r.periodSales(from, to, departmentIDs).
+		Joins("JOIN customers ON customers.id = s.customer_id").
+		Select("customers.id AS customer_id, customers.name, customers.phone, COUNT(*) AS sales_count, SUM(" + saleNetRevenue + ") AS revenue").
+		Group("customers.id, customers.name, customers.phone").
+		Order("revenue DESC, customers.id").
+		Limit(limit).
+		Scan(&rows).Error
+	return rows, err
+}
+
+// SalesSummary — итоги продаж за период.
+type SalesSummary struct {
+	SalesCount int64
+	Quantity   int64
+	Revenue    float64
+}
+
+func (r *SaleRepository) Summary(from, to time.Time, departmentIDs []uint) (SalesSummary, error) {
+	var summary SalesSummary
+	err := r.periodSales(from, to, departmentIDs).
+		Select("COUNT(*) AS sales_count, COALESCE(SUM(" + saleNetQuantity + "), 0) AS quantity, COALESCE(SUM(" + saleNetRevenue + "), 0) AS revenue").
+		Scan(&summary).Error
+	return summary, err
+}
+
+// SalesGroup — продажи за период в одном разрезе. Key — начало дня или
+// недели (YYYY-MM-DD), месяц (YYYY-MM), час (HH:00) или ID отдела, кассира
+// либо товара; Name — его название.
+type SalesGroup struct {
+	Key        string
+	Name       string
+	SalesCount int64
+	Quantity   int64
+	Revenue    float64
+}
+
+// salesGroupings — выражения группировки продаж по разрезам. Время продажи
+// переводится в местное, чтобы продажи попадали в дни магазина.
+var salesGroupings = map[string]struct{ key, name, join string }{
+	models.SalesByDay:        {key: "strftime('%Y-%m-%d', s.sale_date, 'localtime')"},
+	models.SalesByWeek:       {key: "date(s.sale_date, 'localtime', 'weekday 0', '-6 days')"},
+	models.SalesByMonth:      {key: "strftime('%Y-%m', s.sale_date, 'localtime')"},
+	models.SalesByHour:       {key: "strftime('%H:00', s.sale_date, 'localtime')"},
+	models.SalesByDepartment: {key: "products.department_id", name: "departments.name", join: "LEFT JOIN departments ON departments.id = products.department_id"},
+	models.SalesByCashier:    {key: "s.cashier_id", name: "users.username", join: "LEFT JOIN users ON users.id = s.cashier_id"},
+	models.SalesByProduct:    {key: "s.product_id", name: "products.name"},
+}
+
+// Groups считает продажи за период в разрезе groupBy (см.
+// models.SalesGroupings). Периоды идут по времени, остальные разрезы — по
+// убыванию выручки.
+func (r *SaleRepository) Groups(from, to time.Time, departmentIDs []uint, groupBy string) ([]SalesGroup, error) {
+	grouping, ok := salesGroupings[groupBy]
+	if !ok {
+		return nil, fmt.Errorf("неизвестный разрез продаж %q", groupBy)
+	}
+
+	query := r.periodSales(from, to, departmentIDs)
+	name, order := "''", "group_key"
+	if grouping.name != "" {
+		name, order = grouping.name, "revenue DESC, group_key"
+	}
+	if grouping.join != "" {
+		query = query.Joins(grouping.join)
+	}
+
+	var rows []struct {
+		GroupKey   string
+		GroupName  string
+		SalesCount int64
+		Quantity   int64
+		Revenue    float64
+	}
+	err := query.
+		Select("CAST(" + grouping.key + " AS TEXT) AS group_key, " + name + ` AS group_name,
+			COUNT(*) AS sales_count, SUM(` + saleNetQuantity + `) AS quantity, SUM(` + saleNetRevenue + `) AS revenue`).
+		Group("group_key, group_name").
+		Order(order).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make([]SalesGroup, 0, len(rows))
+	for _, row := range rows {
+		groups = append(groups, SalesGroup{
+			Key:        row.GroupKey,
+			Name:       row.GroupName,
+			SalesCount: row.SalesCount,
+			Quantity:   row.Quantity,
+			Revenue:    row.Revenue,
+		})
+	}
+	return groups, nil
+}
+
+// ProductSales — продажи товара за период.
+type ProductSales struct {
+	ProductID      uint
+	Name           string
+	DepartmentName string
+	SalesCount     int64
+	Quantity       int64
+	Revenue        float64
+}
+
+// ProductSales возвращает limit товаров с наибольшей выручкой за период,
+// а при ascending — с наименьшей. В худшие попадают и товары, которые за
+// период не продавались ни разу, кроме архивных.
+func (r *SaleRepository) ProductSales(from, to time.Time, departmentIDs []uint, limit int, ascending bool) ([]ProductSales, error) {
+	query := r.DB.Table("products").
+		Joins("LEFT JOIN sales AS s ON s.product_id = products.id AND s.sale_date >= ? AND s.sale_date < ?", from, to).
+		Joins("LEFT JOIN departments ON departments.id = products.department_id").
+		Where("products.deleted_at IS NULL OR s.id IS NOT NULL")
+	if departmentIDs != nil {
+		query = query.Where("products.department_id IN ?", departmentIDs)
+	}
+
+	order := "revenue DESC, quantity DESC, products.id"
+	if ascending {
+		order = "revenue, quantity, products.id"
+	} else {
+		query = query.Having("COUNT(s.id) > 0")
+	}
+
+	var rows []ProductSales
+	err := query.
+		Select(`products.id AS product_id, products.name, departments.name AS department_name,
+			COUNT(s.id) AS sales_count, COALESCE(SUM(` + saleNetQuantity + `), 0) AS quantity, COALESCE(SUM(` + saleNetRevenue + `), 0) AS revenue`).
+		Group("products.id, products.name, departments.name").
+		Order(order).
+		Limit(limit).
+		Scan(&rows).Error
+	return rows, err
+}
+
+// ProductWeekSales — продажи товара за одну неделю периода. Week — номер
+// недели от начала периода, с нуля.
+type ProductWeekSales struct {
+	ProductID uint
+	Week      int
+	Quantity  int64
+	Revenue   float64
+}
+
+// WeeklyProductSales считает продажи товаров за период [from, to) по
+// неделям, отсчитанным от from.
+func (r *SaleRepository) WeeklyProductSales(from, to time.Time, departmentIDs []uint) ([]ProductWeekSales, error) {
+	var rows []ProductWeekSales
+	err := r.periodSales(from, to, departmentIDs).
+		Select(`s.product_id, CAST((julianday(s.sale_date) - julianday(?)) / 7 AS INTEGER) AS week,
+			SUM(`+saleNetQuantity+`) AS quantity, SUM(`+saleNetRevenue+`) AS revenue`, from).
+		Group("s.product_id, week").
+		Order("s.product_id, week").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// ProductsSoldBefore возвращает ID товаров, которые продавались раньше t.
+func (r *SaleRepository) ProductsSoldBefore(t time.Time) ([]uint, error) {
+	var ids []uint
+	err := r.DB.Model(&models.Sale{}).Where("sale_date < ?", t).Distinct().Pluck("product_id", &ids).Error
+	return ids, err
+}
+
+// DailyQuantities возвращает проданное количество товара по дням периода
+// [from, to) в формате YYYY-MM-DD по местному времени. Дней без продаж в
+// результате нет.
+func (r *SaleRepository) DailyQuantities(productID uint, from, to time.Time) (map[string]int64, error) {
+	var rows []struct {
+		Day      string
+		Quantity int64
+	}
+	err := r.DB.Table("sales AS s").
+		Select("strftime('%Y-%m-%d', s.sale_date, 'localtime') AS day, SUM("+saleNetQuantity+") AS quantity").
+		Where("s.product_id = ? AND s.sale_date >= ? AND s.sale_date < ?", productID, from, to).
+		Group("day").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	quantities := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		quantities[row.Day] = row.Quantity
+	}
+	return quantities, nil
+}
+
+// HasSalesBefore сообщает, продавался ли товар раньше t.
+func (r *SaleRepository) HasSalesBefore(productID uint, t time.Time) (bool, error) {
+	var count int64
+	err := r.DB.Model(&models.Sale{}).Where("product_id = ? AND sale_date < ?", productID, t).Limit(1).Count(&count).Error
+	return count > 0, err
+}
+
+// SoldSince возвращает, сколько каждого товара продано начиная с t, за
+// вычетом возвращенного по этим продажам.
+func (r *SaleRepository) SoldSince(t time.Time) (map[uint]int64, error) {
+	return productQuantities(r.DB.Table("sales AS s").
+		Select("s.product_id, SUM("+saleNetQuantity+") AS quantity").
+		Where("s.sale_date >= ?", t).
+		Group("s.product_id"))
+}
+
+// LastSaleDates возвращает время последней продажи товаров productIDs.
+// Товаров, которые не продавались, в результате нет.
+func (r *SaleRepository) LastSaleDates(productIDs []uint) (map[uint]time.Time, error) {
+	var sales []models.Sale
+	err := r.DB.Select("product_id, sale_date").
+		Where("product_id IN ? AND sale_date = (SELECT MAX(p.sale_date) FROM sales AS p WHERE p.product_id = sales.product_id)", productIDs).
+		Find(&sales).Error
+	if err != nil {
+		return nil, err
+	}
+
+	dates := make(map[uint]time.Time, len(sales))
+	for _, sale := range sales {
+		dates[sale.ProductID] = sale.SaleDate
+	}
+	return dates, nil
+}
+
+// PaymentTotal — платежи одним способом оплаты за период.
+type PaymentTotal struct {
+	Method string
+	Count  int64
+	Amount float64
+}
+
+// PaymentTotals считает оплаты продаж за период по способам оплаты за
+// вычетом выплат по возвратам этих продаж.
+func (r *SaleRepository) PaymentTotals(from, to time.Time, departmentIDs []uint) ([]PaymentTotal, error) {
+	var rows []PaymentTotal
+	err := r.periodSales(from, to, departmentIDs).
+		Joins("JOIN payments ON payments.sale_id = s.id").
+		Select("payments.method, COUNT(*) AS count, SUM(payments.amount) AS amount").
+		Group("payments.method").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var refunds []PaymentTotal
+	err = r.periodSales(from, to, departmentIDs).
+		Joins("JOIN sale_returns AS sr ON sr.sale_id = s.id").
+		Joins("JOIN refunds ON refunds.return_id = sr.id").
+		Select("refunds.method, SUM(refunds.amount) AS amount").
+		Group("refunds.method").
+		Scan(&refunds).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, refund := range refunds {
+		for i := range rows {
+			if rows[i].Method == refund.Method {
+				rows[i].Amount -= refund.Amount
+			}
+		}
+	}
+	return rows, nil
+}
+
+type PaymentRepository struct {
+	DB *gorm.DB
+}
+
+// TotalsByShift возвращает суммы оплат продаж смены по способам оплаты.
+func (r *PaymentRepository) TotalsByShift(shiftID uint) (map[string]float64, error) {
+	var rows []struct {
+		Method string
+		Total  float64
+	}
+	err := r.DB.Model(&models.Payment{}).
+		Select("payments.method, SUM(payments.amount) AS total").
+		Joins("JOIN sales ON sales.id = payments.sale_id").
+		Where("sales.shift_id = ?", shiftID).
+		Group("payments.method").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	totals := make(map[string]float64, len(rows))
+	for _, row := range rows {
+		totals[row.Method] = row.Total
+	}
+	return totals, nil
+}
+
+// ErrReturnExceedsSale возвращается, когда возвращают больше, чем осталось
+// невозвращенным в продаже.
+var ErrReturnExceedsSale = errors.New("количество возврата больше невозвращенного количества продажи")
+
+type SaleReturnRepository struct {
+	DB *gorm.DB
+}
+
+// Create сохраняет возврат вместе с выплатами и в той же транзакции
+// увеличивает возвращенное количество продажи, возвращает товар на склад и
+// проводит баллы по счету покупателя: баллы из выплат возвращаются, а
+// начисленные за товар (ret.PointsReversed) списываются, но не больше
+// остатка на счете. Если в продаже осталось меньше ret.Quantity
+// невозвращенных единиц, возвращается ErrReturnExceedsSale.
+func (r *SaleReturnRepository) Create(ret *models.SaleReturn, sale *models.Sale) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Sale{}).
+			Where("id = ? AND returned_quantity + ? <= quantity", sale.ID, ret.Quantity).
+			Update("returned_quantity", gorm.Expr("returned_quantity + ?", ret.Quantity))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrReturnExceedsSale
+		}
+
+		err := tx.Unscoped().Model(&models.Product{}).Where("id = ?", sale.ProductID).Updates(map[string]interface{}{
+			"current_qty": gorm.Expr("current_qty + ?", ret.Quantity),
+			"version":     gorm.Expr("version + 1"),
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		if sale.CustomerID != nil {
+			if err := returnPoints(tx, ret, *sale.CustomerID, sale.ID); err != nil {
+				return err
+			}
+		}
+
+		ret.FiscalStatus = models.FiscalPending
+		if err := tx.Create(ret).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.FiscalOutboxEntry{SaleID: sale.ID, ReturnID: &ret.ID, NextAttemptAt: time.Now()}).Error
+	})
+}
+
+// FindByID возвращает возврат с выплатами, продажей и ее товаром.
+func (r *SaleReturnRepository) FindByID(id uint) (*models.SaleReturn, error) {
+	var ret models.SaleReturn
+	err := r.DB.Preload("Refunds").Preload("Sale").Preload("Sale.Product", unscoped).Preload("Creator").First(&ret, id).Error
+	return &ret, err
+}
+
+// MarkFiscalized сохраняет реквизиты чека возврата и убирает его из
+// очереди.
+func (r *SaleReturnRepository) MarkFiscalized(ret *models.SaleReturn) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(ret).Select("FiscalStatus", "FiscalDocumentNumber", "FiscalSign", "FiscalizedAt").Updates(ret).Error
+		if err != nil {
+			return err
+		}
+		return receiptEntry(tx, ret.SaleID, &ret.ID).Delete(&models.FiscalOutboxEntry{}).Error
+	})
+}
+
+func (r *SaleReturnRepository) UpdateFiscalStatus(id uint, status string) error {
+	return r.DB.Model(&models.SaleReturn{}).Where("id = ?", id).Update("fiscal_status", status).Error
+}
+
+// returnPoints возвращает покупателю баллы, которыми была оплачена
+// возвращенная часть продажи, и списывает начисленные за нее. Если
+// начисленные баллы уже потрачены, списывается только остаток счета, и
+// ret.PointsReversed уменьшается до фактически списанных.
+func returnPoints(tx *gorm.DB, ret *models.SaleReturn, customerID, saleID uint) error {
+	returned := 0
+	for _, refund := range ret.Refunds {
+		if refund.Method == models.PaymentPoints {
+			returned += int(refund.Amount)
+		}
+	}
+
+	var balance int
+	err := tx.Model(&models.Customer{}).Where("id = ?", customerID).Pluck("points_balance", &balance).Error
+	if err != nil {
+		return err
+	}
+	ret.PointsReversed = min(ret.PointsReversed, balance+returned)
+
+	if returned == ret.PointsReversed {
+		return nil
+	}
+	return addPoints(tx, &models.LoyaltyTransaction{
+		CustomerID: customerID,
+		SaleID:     &saleID,
+		Type:       models.LoyaltyReturn,
+		Points:     returned - ret.PointsReversed,
+		CreatedBy:  ret.CreatedBy,
+	})
+}
+
+// FindBySale возвращает возвраты продажи в порядке оформления.
+func (r *SaleReturnRepository) FindBySale(saleID uint) ([]models.SaleReturn, error) {
+	var returns []models.SaleReturn
+	err := r.DB.Preload("Refunds").Where("sale_id = ?", saleID).Order("id").Find(&returns).Error
+	return returns, err
+}
+
+// TotalByShift считает возвраты, оформленные в смене.
+func (r *SaleReturnRepository) TotalByShift(shiftID uint) (SalesTotal, error) {
+	var total SalesTotal
+	err := r.DB.Model(&models.SaleReturn{}).
+		Select("COUNT(*) AS count, COALESCE(SUM(amount), 0) AS total").
+		Where("shift_id = ?", shiftID).
+		Scan(&total).Error
+	return total, err
+}
+
+// RefundTotalsByShift возвращает суммы выплат по возвратам смены по
+// способам оплаты.
+func (r *SaleReturnRepository) RefundTotalsByShift(shiftID uint) (map[string]float64, error) {
+	var rows []struct {
+		Method string
+		Total  float64
+	}
+	err := r.DB.Model(&models.Refund{}).
+		Select("refunds.method, SUM(refunds.amount) AS total").
+		Joins("JOIN sale_returns ON sale_returns.id = refunds.return_id").
+		Where("sale_returns.shift_id = ?", shiftID).
+		Group("refunds.method").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	totals := make(map[string]float64, len(rows))
+	for _, row := range rows {
+		totals[row.Method] = row.Total
+	}
+	return totals, nil
+}
+
+// ReturnedSince возвращает, сколько каждого товара возвращено начиная с t
+// по продажам, оформленным раньше t. Возвраты более поздних продаж уже
+// вычтены в SaleRepository.SoldSince.
+func (r *SaleReturnRepository) ReturnedSince(t time.Time) (map[uint]int64, error) {
+	return productQuantities(r.DB.Table("sale_returns AS sr").
+		Joins("JOIN sales AS s ON s.id = sr.sale_id").
+		Select("s.product_id, SUM(sr.quantity) AS quantity").
+		Where("sr.created_at >= ? AND s.sale_date < ?", t, t).
+		Group("s.product_id"))
+}
+
+// ErrInsufficientPoints возвращается, когда списание уводит бонусный
+// счет в минус.
+var ErrInsufficientPoints = errors.New("на бонусном счете недостаточно баллов")
+
+// addPoints меняет баланс покупателя на entry.Points и записывает операцию
+// с остатком после нее. Вызывается внутри транзакции.
+func addPoints(tx *gorm.DB, entry *models.LoyaltyTransaction) error {
+	result := tx.Model(&models.Customer{}).
+		Where("id = ? AND points_balance + ? >= 0", entry.CustomerID, entry.Points).
+		Update("points_balance", gorm.Expr("points_balance + ?", entry.Points))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInsufficientPoints
+	}
+
+	err := tx.Model(&models.Customer{}).Where("id = ?", entry.CustomerID).Pluck("points_balance", &entry.Balance).Error
+	if err != nil {
+		return err
+	}
+	return tx.Create(entry).Error
+}
+
+type CustomerRepository struct {
+	DB *gorm.DB
+}
+
+func (r *CustomerRepository) Create(customer *models.Customer) error {
+	return r.DB.Create(customer).Error
+}
+
+func (r *CustomerRepository) FindByID(id uint) (*models.Customer, error) {
+	var customer models.Customer
+	err := r.DB.First(&customer, id).Error
+	return &customer, err
+}
+
+// Find ищет покупателей по подстроке имени, телефона или номера карты.
+func (r *CustomerRepository) Find(filter models.CustomerFilter) ([]models.Customer, error) {
+	query := r.DB.Model(&models.Customer{})
+	if filter.Query != "" {
+		pattern := "%" + filter.Query + "%"
+		condition := r.DB.Where("name LIKE ? OR phone LIKE ? OR card_number LIKE ?", pattern, pattern, pattern)
+		if filter.Phone != "" {
+			condition = condition.Or("phone LIKE ?", filter.Phone+"%")
+		}
+		query = query.Where(condition)
+	}
+
+	var customers []models.Customer
+	err := query.Order("name, id").Limit(filter.Limit).Offset(filter.Offset).Find(&customers).Error
+	return customers, err
+}
+
+// Update сохраняет данные покупателя при совпадении версии. Баланс баллов
+// здесь не меняется: он изменяется только операциями по счету.
+func (r *CustomerRepository) Update(customer *models.Customer, version uint) error {
+	customer.Version = version + 1
+	result := r.DB.Model(customer).
+		Select("name", "phone", "card_number", "version").
+		Where("version = ?", version).
+		Updates(customer)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrStaleVersion
+	}
+	return nil
+}
+
+// AddPoints проводит ручную операцию по бонусному счету.
+func (r *CustomerRepository) AddPoints(entry *models.LoyaltyTransaction) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		return addPoints(tx, entry)
+	})
+}
+
+// LoyaltyTotals — сколько баллов покупатель получил и потратил за все время.
+type LoyaltyTotals struct {
+	Earned   int64
+	Redeemed int64
+}
+
+type LoyaltyTransactionRepository struct {
+	DB *gorm.DB
+}
+
+// FindByCustomer возвращает операции по счету покупателя, новые первыми.
+func (r *LoyaltyTransactionRepository) FindByCustomer(customerID uint, limit, offset int) ([]models.LoyaltyTransaction, error) {
+	var entries []models.LoyaltyTransaction
+	err := r.DB.Where("customer_id = ?", customerID).
+		Order("id DESC").Limit(limit).Offset(offset).
+		Find(&entries).Error
+	return entries, err
+}
+
+func (r *LoyaltyTransactionRepository) TotalsByCustomer(customerID uint) (LoyaltyTotals, error) {
+	var totals LoyaltyTotals
+	err := r.DB.Model(&models.LoyaltyTransaction{}).
+		Select("COALESCE(SUM(CASE WHEN points > 0 THEN points END), 0) AS earned, "+
+			"COALESCE(-SUM(CASE WHEN points < 0 THEN points END), 0) AS redeemed").
+		Where("customer_id = ?", customerID).
+		Scan(&totals).Error
+	return totals, err
+}
+
+type FiscalOutboxRepository struct {
+	DB *gorm.DB
+}
+
+// FindDue возвращает записи очереди, время попытки которых наступило и
+// которые сейчас не обрабатываются.
+func (r *FiscalOutboxRepository) FindDue(now time.Time, limit int) ([]models.FiscalOutboxEntry, error) {
+	var entries []models.FiscalOutboxEntry
+	err := r.DB.Where("next_attempt_at <= ? AND (locked_until IS NULL OR locked_until < ?)", now, now).
+		Order("next_attempt_at").Limit(limit).Find(&entries).Error
+	return entries, err
+}
+
+// Claim захватывает запись чека продажи saleID или, если returnID не nil,
+// чека возврата до until, чтобы регистрацию не начал параллельно другой
+// обработчик. Возвращает false, если записи нет или она уже захвачена.
+func (r *FiscalOutboxRepository) Claim(saleID uint, returnID *uint, now, until time.Time) (*models.FiscalOutboxEntry, bool, error) {
+	result := receiptEntry(r.DB.Model(&models.FiscalOutboxEntry{}), saleID, returnID).
+		Where("locked_until IS NULL OR locked_until < ?", now).
+		Update("locked_until", until)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil, false, result.Error
+	}
+
+	var entry models.FiscalOutboxEntry
+	if err := receiptEntry(r.DB, saleID, returnID).First(&entry).Error; err != nil {
+		return nil, false, err
+	}
+	return &entry, true, nil
+}
+
+// receiptEntry выбирает запись очереди с чеком продажи saleID или, если
+// returnID не nil, с чеком возврата returnID.
+func receiptEntry(query *gorm.DB, saleID uint, returnID *uint) *gorm.DB {
+	if returnID != nil {
+		return query.Where("return_id = ?", *returnID)
+	}
+	return query.Where("sale_id = ? AND return_id IS NULL", saleID)
+}
+
+// Reschedule сохраняет результат неудачной попытки и снимает захват.
+func (r *FiscalOutboxRepository) Reschedule(entry *models.FiscalOutboxEntry) error {
+	return r.DB.Model(entry).Updates(map[string]interface{}{
+		"attempts":        entry.Attempts,
+		"last_error":      entry.LastError,
+		"next_attempt_at": entry.NextAttemptAt,
+		"locked_until":    nil,
+	}).Error
+}
+
+type ShiftRepository struct {
+	DB *gorm.DB
+}
+
+func (r *ShiftRepository) Create(shift *models.Shift) error {
+	return r.DB.Create(shift).Error
+}
+
+func (r *ShiftRepository) FindByID(id uint) (*models.Shift, error) {
+	var shift models.Shift
+	err := r.DB.Preload("Cashier").First(&shift, id).Error
+	return &shift, err
+}
+
+// FindOpenByCashier находит открытую смену кассира.
+func (r *ShiftRepository) FindOpenByCashier(cashierID uint) (*models.Shift, error) {
+	var shift models.Shift
+	err := r.DB.Preload("Cashier").Where("cashier_id = ? AND closed_at IS NULL", cashierID).First(&shift).Error
+	return &shift, err
+}
+
+// CountOpenByRegister считает открытые смены на кассе.
+func (r *ShiftRepository) CountOpenByRegister(register string) (int64, error) {
+	var count int64
+	err := r.DB.Model(&models.Shift{}).Where("register = ? AND closed_at IS NULL", register).Count(&count).Error
+	return count, err
+}
+
+func (r *ShiftRepository) Find(filter models.ShiftFilter) ([]models.Shift, error) {
+	query := r.DB.Preload("Cashier")
+	if filter.CashierID != 0 {
+		query = query.Where("cashier_id = ?", filter.CashierID)
+	}
+	if filter.Register != "" {
+		query = query.Where("register = ?", filter.Register)
+	}
+	if filter.Open != nil {
+		if *filter.Open {
+			query = query.Where("closed_at IS NULL")
+		} else {
+			query = query.Where("closed_at IS NOT NULL")
+		}
+	}
+
+	var shifts []models.Shift
+	err := query.Order("opened_at DESC").Limit(filter.Limit).Offset(filter.Offset).Find(&shifts).Error
+	return shifts, err
+}
+
+// Close закрывает смену, если она еще открыта. Возвращает false, если
+// смену уже закрыл другой запрос.
+func (r *ShiftRepository) Close(shift *models.Shift) (bool, error) {
+	result := r.DB.Model(shift).Where("closed_at IS NULL").
+		Select("ClosedAt", "ExpectedCash", "CountedCash").
+		Updates(shift)
+	return result.RowsAffected > 0, result.Error
+}
+
+type CashMovementRepository struct {
+	DB *gorm.DB
+}
+
+func (r *CashMovementRepository) Create(movement *models.CashMovement) error {
+	return r.DB.Create(movement).Error
+}
+
+func (r *CashMovementRepository) FindByShift(shiftID uint) ([]models.CashMovement, error) {
+	var movements []models.CashMovement
+	err := r.DB.Where("shift_id = ?", shiftID).Order("id").Find(&movements).Error
+	return movements, err
+}
+
 type SupplyRepository struct {
 	DB *gorm.DB
 }
@@ -170,13 +1476,13 @@ func (r *SupplyRepository) Create(supply *models.Supply) error {
 
 func (r *SupplyRepository) FindByID(id uint) (*models.Supply, error) {
 	var supply models.Supply
-	err := r.DB.Preload("Supplier").Preload("Approver").First(&supply, id).Error
+	err := r.DB.Preload("Supplier", unscoped).Preload("Approver").First(&supply, id).Error
 	return &supply, err
 }
 
 func (r *SupplyRepository) FindAll() ([]models.Supply, error) {
 	var supplies []models.Supply
-	err := r.DB.Preload("Supplier").Preload("Approver").Find(&supplies).Error
+	err := r.DB.Preload("Supplier", unscoped).Preload("Approver").Find(&supplies).Error
 	return supplies, err
 }
 
@@ -190,6 +1496,344 @@ func (r *SupplyItemRepository) Create(item *models.SupplyItem) error {
 
 func (r *SupplyItemRepository) FindBySupplyID(supplyID uint) ([]models.SupplyItem, error) {
 	var items []models.SupplyItem
-	err := r.DB.Preload("Product").Where("supply_id = ?", supplyID).Find(&items).Error
+	err := r.DB.Preload("Product", unscoped).Where("supply_id = ?", supplyID).Find(&items).Error
 	return items, err
 }
+
+// FindBySupplier возвращает позиции поставок поставщика за период
+// [from, to) вместе с поставкой и товаром в порядке приемки.
+func (r *SupplyItemRepository) FindBySupplier(supplierID uint, from, to time.Time) ([]models.SupplyItem, error) {
+	var items []models.SupplyItem
+	err := r.DB.Preload("Supply").Preload("Product", unscoped).
+		Joins("JOIN supplies ON supplies.id = supply_items.supply_id").
+		Where("supplies.supplier_id = ? AND supplies.supply_date >= ? AND supplies.supply_date < ?", supplierID, from, to).
+		Order("supplies.supply_date, supply_items.supply_id, supply_items.id").
+		Find(&items).Error
+	return items, err
+}
+
+// EachInPeriod передает в fn позиции поставок за период [from, to) вместе
+// с поставкой, поставщиком и товаром пачками, не загружая выборку целиком.
+func (r *SupplyItemRepository) EachInPeriod(from, to time.Time, fn func([]models.SupplyItem) error) error {
+	var items []models.SupplyItem
+	return r.DB.Preload("Supply.Supplier", unscoped).Preload("Supply.Approver").Preload("Product", unscoped).
+		Joins("JOIN supplies ON supplies.id = supply_items.supply_id").
+		Where("supplies.supply_date >= ? AND supplies.supply_date < ?", from, to).
+		FindInBatches(&items, batchSize, func(*gorm.DB, int) error {
+			return fn(items)
+		}).Error
+}
+
+// ReceivedSince возвращает, сколько каждого товара поступило с поставками
+// начиная с t.
+func (r *SupplyItemRepository) ReceivedSince(t time.Time) (map[uint]int64, error) {
+	return productQuantities(r.DB.Model(&models.SupplyItem{}).
+		Select("supply_items.product_id, SUM(supply_items.quantity) AS quantity").
+		Joins("JOIN supplies ON supplies.id = supply_items.supply_id").
+		Where("supplies.supply_date >= ?", t).
+		Group("supply_items.product_id"))
+}
+
+// FindReceivedBefore возвращает позиции поставок, принятых раньше t, по
+// товарам productIDs: по каждому товару сначала самые поздние.
+func (r *SupplyItemRepository) FindReceivedBefore(t time.Time, productIDs []uint) ([]models.SupplyItem, error) {
+	var items []models.SupplyItem
+	err := r.DB.Select("supply_items.*").
+		Joins("JOIN supplies ON supplies.id = supply_items.supply_id").
+		Where("supplies.supply_date < ? AND supply_items.product_id IN ?", t, productIDs).
+		Order("supply_items.product_id, supplies.supply_date DESC, supply_items.id DESC").
+		Find(&items).Error
+	return items, err
+}
+
+// productQuantities выполняет запрос, возвращающий product_id и quantity,
+// и собирает результат по товарам.
+func productQuantities(query *gorm.DB) (map[uint]int64, error) {
+	var rows []struct {
+		ProductID uint
+		Quantity  int64
+	}
+	if err := query.Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	quantities := make(map[uint]int64, len(rows))
+	for _, row := range rows {
+		quantities[row.ProductID] = row.Quantity
+	}
+	return quantities, nil
+}
+
+type SupplierInvoiceRepository struct {
+	DB *gorm.DB
+}
+
+func (r *SupplierInvoiceRepository) Create(invoice *models.SupplierInvoice) error {
+	return r.DB.Omit(clause.Associations).Create(invoice).Error
+}
+
+func (r *SupplierInvoiceRepository) FindByID(id uint) (*models.SupplierInvoice, error) {
+	var invoice models.SupplierInvoice
+	err := r.DB.Preload("Supplier", unscoped).
+		Preload("Payments", func(db *gorm.DB) *gorm.DB { return db.Order("paid_at, id") }).
+		First(&invoice, id).Error
+	return &invoice, err
+}
+
+// Find возвращает счета по фильтру, сначала с ближайшим сроком оплаты.
+// Просроченными считаются неоплаченные счета со сроком раньше today.
+func (r *SupplierInvoiceRepository) Find(filter models.InvoiceFilter, today time.Time) ([]models.SupplierInvoice, error) {
+	query := r.DB.Preload("Supplier", unscoped)
+	if filter.SupplierID != 0 {
+		query = query.Where("supplier_id = ?", filter.SupplierID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Overdue {
+		query = query.Where("status <> ? AND due_date < ?", models.InvoicePaid, today)
+	}
+
+	var invoices []models.SupplierInvoice
+	err := query.Order("due_date, id").Limit(filter.Limit).Offset(filter.Offset).Find(&invoices).Error
+	return invoices, err
+}
+
+// FindOutstanding возвращает все неоплаченные и частично оплаченные счета.
+func (r *SupplierInvoiceRepository) FindOutstanding() ([]models.SupplierInvoice, error) {
+	var invoices []models.SupplierInvoice
+	err := r.DB.Preload("Supplier", unscoped).
+		Where("status <> ?", models.InvoicePaid).
+		Order("supplier_id, due_date, id").
+		Find(&invoices).Error
+	return invoices, err
+}
+
+// FindNewlyOverdue возвращает просроченные на today счета, о которых еще
+// не оповещали.
+func (r *SupplierInvoiceRepository) FindNewlyOverdue(today time.Time) ([]models.SupplierInvoice, error) {
+	var invoices []models.SupplierInvoice
+	err := r.DB.Preload("Supplier", unscoped).
+		Where("status <> ? AND due_date < ? AND overdue_notified_at IS NULL", models.InvoicePaid, today).
+		Order("due_date, id").
+		Find(&invoices).Error
+	return invoices, err
+}
+
+func (r *SupplierInvoiceRepository) MarkOverdueNotified(ids []uint, at time.Time) error {
+	return r.DB.Model(&models.SupplierInvoice{}).Where("id IN ?", ids).Update("overdue_notified_at", at).Error
+}
+
+// FindOverdueAlerts возвращает неоплаченные счета, о просрочке которых
+// оповестили, но оповещение еще не подтвердили.
+func (r *SupplierInvoiceRepository) FindOverdueAlerts() ([]models.SupplierInvoice, error) {
+	var invoices []models.SupplierInvoice
+	err := r.DB.Preload("Supplier", unscoped).
+		Where("status <> ? AND overdue_notified_at IS NOT NULL AND overdue_acknowledged_at IS NULL", models.InvoicePaid).
+		Order("due_date, id").
+		Find(&invoices).Error
+	return invoices, err
+}
+
+// AcknowledgeOverdue подтверждает оповещение о просрочке счета. Возвращает
+// false, если оповещения не было или его уже подтвердили.
+func (r *SupplierInvoiceRepository) AcknowledgeOverdue(id, by uint, at time.Time) (bool, error) {
+	result := r.DB.Model(&models.SupplierInvoice{}).
+		Where("id = ? AND overdue_notified_at IS NOT NULL AND overdue_acknowledged_at IS NULL", id).
+		Updates(map[string]interface{}{"overdue_acknowledged_at": at, "overdue_acknowledged_by": by})
+	return result.RowsAffected > 0, result.Error
+}
+
+// ErrOverpayment возвращается, когда оплата больше неоплаченного остатка
+// счета.
+var ErrOverpayment = errors.New("оплата превышает остаток по счету")
+
+// AddPayment в одной транзакции записывает оплату и увеличивает оплаченную
+// сумму счета. Остаток проверяется в том же запросе, поэтому одновременные
+// оплаты не переплатят счет.
+func (r *SupplierInvoiceRepository) AddPayment(payment *models.InvoicePayment) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.SupplierInvoice{}).
+			Where("id = ? AND ROUND(paid_amount + ?, 2) <= amount", payment.InvoiceID, payment.Amount).
+			Updates(map[string]interface{}{
+				"paid_amount": gorm.Expr("ROUND(paid_amount + ?, 2)", payment.Amount),
+				"status": gorm.Expr("CASE WHEN ROUND(paid_amount + ?, 2) >= amount THEN ? ELSE ? END",
+					payment.Amount, models.InvoicePaid, models.InvoicePartial),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrOverpayment
+		}
+
+		return tx.Omit(clause.Associations).Create(payment).Error
+	})
+}
+
+type WriteOffRepository struct {
+	DB *gorm.DB
+}
+
+// Create сохраняет акт списания вместе со строками.
+func (r *WriteOffRepository) Create(writeOff *models.WriteOff) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Create(writeOff).Error; err != nil {
+			return err
+		}
+		for i := range writeOff.Items {
+			writeOff.Items[i].WriteOffID = writeOff.ID
+		}
+		return tx.Omit(clause.Associations).Create(&writeOff.Items).Error
+	})
+}
+
+func (r *WriteOffRepository) FindByID(id uint) (*models.WriteOff, error) {
+	var writeOff models.WriteOff
+	err := r.DB.Preload("Department", unscoped).
+		Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("Items.Product", unscoped).
+		First(&writeOff, id).Error
+	return &writeOff, err
+}
+
+// Find возвращает акты списания по фильтру, новые первыми.
+func (r *WriteOffRepository) Find(filter models.WriteOffFilter) ([]models.WriteOff, error) {
+	query := r.DB.Preload("Department", unscoped).
+		Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("Items.Product", unscoped)
+	if filter.DepartmentIDs != nil {
+		query = query.Where("department_id IN ?", filter.DepartmentIDs)
+	}
+	if filter.DepartmentID != 0 {
+		query = query.Where("department_id = ?", filter.DepartmentID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Reason != "" {
+		query = query.Where("reason = ?", filter.Reason)
+	}
+
+	var writeOffs []models.WriteOff
+	err := query.Order("created_at DESC, id DESC").Limit(filter.Limit).Offset(filter.Offset).Find(&writeOffs).Error
+	return writeOffs, err
+}
+
+// ErrWriteOffReviewed возвращается, когда акт списания уже утвержден или
+// отклонен.
+var ErrWriteOffReviewed = errors.New("акт списания уже рассмотрен")
+
+// InsufficientStockError возвращается, когда списание или продажа больше
+// остатка товара ProductID.
+type InsufficientStockError struct {
+	ProductID uint
+}
+
+func (e *InsufficientStockError) Error() string {
+	return fmt.Sprintf("остатка товара %d недостаточно", e.ProductID)
+}
+
+// Approve в одной транзакции утверждает акт списания, записывает
+// себестоимость строк и уменьшает остатки. Статус и остатки проверяются
+// в тех же запросах, поэтому акт не спишется дважды, а остаток не уйдет в
+// минус. При нехватке остатка возвращается *InsufficientStockError.
+func (r *WriteOffRepository) Approve(writeOff *models.WriteOff) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.WriteOff{}).
+			Where("id = ? AND status = ?", writeOff.ID, models.WriteOffPending).
+			Updates(map[string]interface{}{
+				"status":      models.WriteOffApproved,
+				"total_cost":  writeOff.TotalCost,
+				"reviewed_by": writeOff.ReviewedBy,
+				"reviewed_at": writeOff.ReviewedAt,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrWriteOffReviewed
+		}
+
+		for _, item := range writeOff.Items {
+			err := tx.Model(&models.WriteOffItem{}).Where("id = ?", item.ID).Updates(map[string]interface{}{
+				"unit_cost": item.UnitCost,
+				"cost":      item.Cost,
+			}).Error
+			if err != nil {
+				return err
+			}
+
+			result := tx.Model(&models.Product{}).
+				Where("id = ? AND current_qty >= ?", item.ProductID, item.Quantity).
+				Updates(map[string]interface{}{
+					"current_qty": gorm.Expr("current_qty - ?", item.Quantity),
+					"version":     gorm.Expr("version + 1"),
+				})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return &InsufficientStockError{ProductID: item.ProductID}
+			}
+		}
+		return nil
+	})
+}
+
+// Reject отклоняет акт списания, если он еще не рассмотрен.
+func (r *WriteOffRepository) Reject(writeOff *models.WriteOff) error {
+	result := r.DB.Model(&models.WriteOff{}).
+		Where("id = ? AND status = ?", writeOff.ID, models.WriteOffPending).
+		Updates(map[string]interface{}{
+			"status":        models.WriteOffRejected,
+			"reviewed_by":   writeOff.ReviewedBy,
+			"reviewed_at":   writeOff.ReviewedAt,
+			"reject_reason": writeOff.RejectReason,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrWriteOffReviewed
+	}
+	return nil
+}
+
+// WrittenOffSince возвращает, сколько каждого товара списано по актам,
+// утвержденным начиная с t.
+func (r *WriteOffRepository) WrittenOffSince(t time.Time) (map[uint]int64, error) {
+	return productQuantities(r.DB.Model(&models.WriteOffItem{}).
+		Select("write_off_items.product_id, SUM(write_off_items.quantity) AS quantity").
+		Joins("JOIN write_offs ON write_offs.id = write_off_items.write_off_id").
+		Where("write_offs.status = ? AND write_offs.reviewed_at >= ?", models.WriteOffApproved, t).
+		Group("write_off_items.product_id"))
+}
+
+// ShrinkageRow — списания отдела по одной причине за период.
+type ShrinkageRow struct {
+	DepartmentID   uint
+	DepartmentName string
+	Reason         string
+	WriteOffs      int
+	Quantity       int64
+	Cost           float64
+}
+
+// Shrinkage суммирует строки актов, утвержденных в [from, to), по отделам
+// и причинам. departmentIDs ограничивает отделы (nil — все).
+func (r *WriteOffRepository) Shrinkage(from, to time.Time, departmentIDs []uint) ([]ShrinkageRow, error) {
+	query := r.DB.Table("write_off_items AS i").
+		Select("w.department_id, d.name AS department_name, w.reason, "+
+			"COUNT(DISTINCT w.id) AS write_offs, SUM(i.quantity) AS quantity, SUM(i.cost) AS cost").
+		Joins("JOIN write_offs AS w ON w.id = i.write_off_id").
+		Joins("JOIN departments AS d ON d.id = w.department_id").
+		Where("w.status = ? AND w.reviewed_at >= ? AND w.reviewed_at < ?", models.WriteOffApproved, from, to)
+	if departmentIDs != nil {
+		query = query.Where("w.department_id IN ?", departmentIDs)
+	}
+
+	var rows []ShrinkageRow
+	err := query.Group("w.department_id, d.name, w.reason").Order("w.department_id, w.reason").Scan(&rows).Error
+	return rows, err
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"encoding/json"
+	"grocery-store-api/models"
+	"grocery-store-api/repositories"
+	"log"
+	"reflect"
+	"time"
+)
+
+const (
+	AuditEntityUser               = "user"
+	AuditEntityRolePermissions    = "role_permissions"
+	AuditEntityProduct            = "product"
+	AuditEntityDepartment         = "department"
+	AuditEntitySupplier           = "supplier"
+	AuditEntitySale               = "sale"
+	AuditEntitySupply             = "supply"
+	AuditEntityShift              = "shift"
+	AuditEntityCashMovement       = "cash_movement"
+	AuditEntityCustomer           = "customer"
+	AuditEntityLoyaltyTransaction = "loyalty_transaction"
+	AuditEntityProductSupplier    = "product_supplier"
+	AuditEntitySupplierPriceList  = "supplier_price_list"
+	AuditEntitySupplierInvoice    = "supplier_invoice"
+	AuditEntityInvoicePayment     = "invoice_payment"
+	AuditEntityWriteOff           = "write_off"
+	AuditEntitySaleReturn         = "sale_return"
+)
+
+// AuditService записывает в журнал все изменения данных. Ошибка записи
+// журнала не отменяет уже выполненную операцию и только логируется.
+type AuditService struct {
+	Repo repositories.AuditRepository
+}
+
+// Record сохраняет запись журнала. before или after равны nil для
+// создания и удаления соответственно.
+func (s *AuditService) Record(actor Actor, action, entityType string, entityID uint, before, after interface{}) {
+	beforeSnapshot := auditSnapshot(before)
+	afterSnapshot := auditSnapshot(after)
+
+	entry := models.AuditLog{
+		ActorID:    actor.UserID,
+		ActorName:  actor.Username,
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Before:     marshalSnapshot(beforeSnapshot),
+		After:      marshalSnapshot(afterSnapshot),
+		Diff:       marshalSnapshot(auditDiff(beforeSnapshot, afterSnapshot)),
+		IP:         actor.IP,
+		RequestID:  actor.RequestID,
+		CreatedAt:  time.Now(),
+	}
+
+	if err := s.Repo.Create(&entry); err != nil {
+		log.Printf("audit: не удалось записать %s %s #%d: %v", action, entityType, entityID, err)
+	}
+}
+
+func (s *AuditService) Find(filter models.AuditFilter) ([]models.AuditLog, error) {
+	if filter.Limit <= 0 || filter.Limit > 1000 {
+		filter.Limit = 100
+	}
+
+	return s.Repo.Find(filter)
+}
+
+// auditSnapshot превращает сущность в карту ее JSON-полей. Вложенные
+// связанные объекты отбрасываются: в журнал попадают только собственные
+// поля сущности, а связи видны по их ID.
+func auditSnapshot(v interface{}) map[string]interface{} {
+	if v == nil || (reflect.ValueOf(v).Kind() == reflect.Ptr && reflect.ValueOf(v).IsNil()) {
+		return nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+
+	var snapshot map[string]interface{}
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil
+	}
+
+	return stripNested(snapshot, true)
+}
+
+func stripNested(m map[string]interface{}, keepLists bool) map[string]interface{} {
+	for key, value := range m {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			delete(m, key)
+		case []interface{}:
+			if !keepLists {
+				delete(m, key)
+				continue
+			}
+			for _, elem := range v {
+				if nested, ok := elem.(map[string]interface{}); ok {
+					stripNested(nested, false)
+				}
+			}
+		}
+	}
+	return m
+}
+
+func auditDiff(before, after map[string]interface{}) map[string]interface{} {
+	diff := make(map[string]interface{})
+	for key, value := range after {
+		if old, ok := before[key]; !ok || !reflect.DeepEqual(old, value) {
+			diff[key] = map[string]interface{}{"from": before[key], "to": value}
+		}
+	}
+	for key, value := range before {
+		if _, ok := after[key]; !ok {
+			diff[key] = map[string]interface{}{"from": value, "to": nil}
+		}
+	}
+	return diff
+}
+
+func marshalSnapshot(v map[string]interface{}) string {
+	if v == nil {
+		return ""
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
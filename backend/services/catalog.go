@@ -0,0 +1,337 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	"grocery-store-api/models"
+	"grocery-store-api/repositories"
+	"grocery-store-api/services/errs"
+)
+
+var (
+	ErrProductSupplierNotFound = errs.NewNotFound("product_supplier_not_found", "поставщик не связан с товаром")
+	ErrPreferredSupplier       = errs.NewConflict("preferred_supplier", "основного поставщика нельзя убрать из каталога товара, сначала назначьте основным другого")
+	ErrDuplicatePriceListItem  = errs.NewValidation("duplicate_price_list_item", "товар указан в прайс-листе несколько раз")
+)
+
+// SupplierOffer — предложение поставщика по товару: артикул в его
+// каталоге и цена, действующая на дату запроса (nil, если цены нет).
+type SupplierOffer struct {
+	Supplier    models.Supplier
+	SupplierSKU string
+	Preferred   bool
+	Price       *models.SupplierPrice
+}
+
+// PriceListItem — строка действующего прайс-листа поставщика.
+type PriceListItem struct {
+	Product     models.Product
+	SupplierSKU string
+	Preferred   bool
+	Price       *models.SupplierPrice
+}
+
+// PriceListEntry — строка загружаемого прайс-листа.
+type PriceListEntry struct {
+	ProductID   uint
+	SupplierSKU string
+	PackSize    int
+	Cost        float64
+}
+
+// PriceComparison — сравнение цен поставщиков по товару для заказа.
+// Offers упорядочены по цене за единицу, поставщики без цены в конце.
+type PriceComparison struct {
+	Product   models.Product
+	Offers    []SupplierOffer
+	Best      *SupplierOffer
+	Preferred *SupplierOffer
+}
+
+// CatalogService ведет каталоги поставщиков: какие товары у кого можно
+// заказать, по каким артикулам и ценам.
+type CatalogService struct {
+	LinkRepo     repositories.ProductSupplierRepository
+	PriceRepo    repositories.SupplierPriceRepository
+	ProductRepo  repositories.ProductRepository
+	SupplierRepo repositories.SupplierRepository
+	Scope        DepartmentScope
+	Audit        AuditService
+}
+
+// GetProductSuppliers возвращает поставщиков товара с ценами на дату day,
+// дешевые первыми.
+func (s *CatalogService) GetProductSuppliers(productID uint, day time.Time) ([]SupplierOffer, error) {
+	if _, err := s.ProductRepo.FindByID(productID); err != nil {
+		return nil, notFound(err, ErrProductNotFound)
+	}
+
+	offers, err := s.offers([]uint{productID}, day)
+	if err != nil {
+		return nil, err
+	}
+	return offers[productID], nil
+}
+
+// SaveProductSupplier добавляет поставщика в каталог товара или меняет
+// артикул. preferred делает поставщика основным; снять этот признак можно
+// только назначив основным другого поставщика.
+func (s *CatalogService) SaveProductSupplier(actor Actor, link *models.ProductSupplier) error {
+	product, err := s.ProductRepo.FindByID(link.ProductID)
+	if err != nil {
+		return notFound(err, ErrProductNotFound)
+	}
+
+	if err := s.Scope.Check(actor, product.DepartmentID); err != nil {
+		return err
+	}
+
+	if _, err := s.SupplierRepo.FindByID(link.SupplierID); err != nil {
+		return notFound(err, ErrSupplierNotFound)
+	}
+
+	before, err := s.LinkRepo.Find(link.ProductID, link.SupplierID)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		before = nil
+	case err != nil:
+		return err
+	case before.Preferred && !link.Preferred:
+		return ErrPreferredSupplier
+	}
+
+	if err := s.LinkRepo.Save(link); err != nil {
+		return err
+	}
+	if link.Preferred && product.SupplierID != link.SupplierID {
+		if err := s.LinkRepo.SetPreferred(link.ProductID, link.SupplierID); err != nil {
+			return err
+		}
+	}
+
+	saved, err := s.LinkRepo.Find(link.ProductID, link.SupplierID)
+	if err != nil {
+		return err
+	}
+
+	action := models.AuditUpdate
+	if before == nil {
+		action = models.AuditCreate
+	}
+	s.Audit.Record(actor, action, AuditEntityProductSupplier, link.ProductID, before, saved)
+	*link = *saved
+	return nil
+}
+
+// DeleteProductSupplier убирает товар из каталога поставщика. История цен
+// при этом сохраняется.
+func (s *CatalogService) DeleteProductSupplier(actor Actor, productID, supplierID uint) error {
+	product, err := s.ProductRepo.FindByID(productID)
+	if err != nil {
+		return notFound(err, ErrProductNotFound)
+	}
+
+	if err := s.Scope.Check(actor, product.DepartmentID); err != nil {
+		return err
+	}
+
+	existing, err := s.LinkRepo.Find(productID, supplierID)
+	if err != nil {
+		return notFound(err, ErrProductSupplierNotFound)
+	}
+	if existing.Preferred {
+		return ErrPreferredSupplier
+	}
+
+	if err := s.LinkRepo.Delete(productID, supplierID); err != nil {
+		return err
+	}
+
+	s.Audit.Record(actor, models.AuditDelete, AuditEntityProductSupplier, productID, existing, nil)
+	return nil
+}
+
+// GetPriceList возвращает каталог поставщика с ценами на дату day.
+func (s *CatalogService) GetPriceList(supplierID uint, day time.Time) ([]PriceListItem, error) {
+	if _, err := s.SupplierRepo.FindByID(supplierID); err != nil {
+		return nil, notFound(err, ErrSupplierNotFound)
+	}
+
+	links, err := s.LinkRepo.FindBySupplier(supplierID)
+	if err != nil {
+		return nil, err
+	}
+
+	prices, err := s.PriceRepo.Current(day.AddDate(0, 0, 1), supplierID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	byProduct := make(map[uint]*models.SupplierPrice, len(prices))
+	for i := range prices {
+		byProduct[prices[i].ProductID] = &prices[i]
+	}
+
+	items := make([]PriceListItem, 0, len(links))
+	for _, link := range links {
+		items = append(items, PriceListItem{
+			Product:     link.Product,
+			SupplierSKU: link.SupplierSKU,
+			Preferred:   link.Preferred,
+			Price:       byProduct[link.ProductID],
+		})
+	}
+	return items, nil
+}
+
+// GetPriceHistory возвращает все цены товара у поставщика, новые первыми.
+func (s *CatalogService) GetPriceHistory(supplierID, productID uint) ([]models.SupplierPrice, error) {
+	if _, err := s.LinkRepo.Find(productID, supplierID); err != nil {
+		return nil, notFound(err, ErrProductSupplierNotFound)
+	}
+	return s.PriceRepo.FindHistory(supplierID, productID)
+}
+
+// UploadPriceList сохраняет цены поставщика, действующие с дня validFrom
+// (нулевое значение — с сегодняшнего). Товары, которых еще нет в каталоге
+// поставщика, добавляются в него.
+func (s *CatalogService) UploadPriceList(actor Actor, supplierID uint, validFrom time.Time, entries []PriceListEntry) ([]models.SupplierPrice, error) {
+	if _, err := s.SupplierRepo.FindByID(supplierID); err != nil {
+		return nil, notFound(err, ErrSupplierNotFound)
+	}
+
+	if validFrom.IsZero() {
+		validFrom = time.Now()
+	}
+	validFrom = startOfDay(validFrom)
+
+	links := make([]models.ProductSupplier, 0, len(entries))
+	prices := make([]models.SupplierPrice, 0, len(entries))
+	seen := make(map[uint]bool, len(entries))
+	for _, entry := range entries {
+		if seen[entry.ProductID] {
+			return nil, fmt.Errorf("%w: %d", ErrDuplicatePriceListItem, entry.ProductID)
+		}
+		seen[entry.ProductID] = true
+
+		product, err := s.ProductRepo.FindByID(entry.ProductID)
+		if err != nil {
+			return nil, notFound(err, fmt.Errorf("%w: %d", ErrUnknownProduct, entry.ProductID))
+		}
+		if err := s.Scope.Check(actor, product.DepartmentID); err != nil {
+			return nil, err
+		}
+
+		packSize := entry.PackSize
+		if packSize <= 0 {
+			packSize = 1
+		}
+		links = append(links, models.ProductSupplier{
+			ProductID:   entry.ProductID,
+			SupplierID:  supplierID,
+			SupplierSKU: entry.SupplierSKU,
+		})
+		prices = append(prices, models.SupplierPrice{
+			SupplierID: supplierID,
+			ProductID:  entry.ProductID,
+			ValidFrom:  validFrom,
+			PackSize:   packSize,
+			Cost:       roundMoney(entry.Cost),
+			CreatedBy:  actor.UserID,
+			CreatedAt:  time.Now(),
+		})
+	}
+
+	if err := s.PriceRepo.SavePriceList(links, prices); err != nil {
+		return nil, err
+	}
+
+	s.Audit.Record(actor, models.AuditCreate, AuditEntitySupplierPriceList, supplierID, nil, prices)
+	return prices, nil
+}
+
+// ComparePrices сравнивает цены поставщиков на дату day по товарам,
+// которые собираются заказать. Результат идет в порядке productIDs.
+func (s *CatalogService) ComparePrices(productIDs []uint, day time.Time) ([]PriceComparison, error) {
+	products, err := s.ProductRepo.FindByIDs(productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uint]models.Product, len(products))
+	for _, product := range products {
+		byID[product.ID] = product
+	}
+
+	offers, err := s.offers(productIDs, day)
+	if err != nil {
+		return nil, err
+	}
+
+	comparisons := make([]PriceComparison, 0, len(productIDs))
+	for _, id := range productIDs {
+		product, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("%w: %d", ErrUnknownProduct, id)
+		}
+
+		comparison := PriceComparison{Product: product, Offers: offers[id]}
+		for i := range comparison.Offers {
+			offer := &comparison.Offers[i]
+			if offer.Preferred {
+				comparison.Preferred = offer
+			}
+			if offer.Price != nil && comparison.Best == nil {
+				comparison.Best = offer
+			}
+		}
+		comparisons = append(comparisons, comparison)
+	}
+	return comparisons, nil
+}
+
+// offers собирает предложения активных поставщиков по товарам с ценами,
+// действующими на дату day, и упорядочивает их по цене за единицу.
+func (s *CatalogService) offers(productIDs []uint, day time.Time) (map[uint][]SupplierOffer, error) {
+	links, err := s.LinkRepo.FindByProducts(productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	prices, err := s.PriceRepo.Current(day.AddDate(0, 0, 1), 0, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	type key struct{ product, supplier uint }
+	byKey := make(map[key]*models.SupplierPrice, len(prices))
+	for i := range prices {
+		byKey[key{prices[i].ProductID, prices[i].SupplierID}] = &prices[i]
+	}
+
+	result := make(map[uint][]SupplierOffer, len(productIDs))
+	for _, link := range links {
+		result[link.ProductID] = append(result[link.ProductID], SupplierOffer{
+			Supplier:    link.Supplier,
+			SupplierSKU: link.SupplierSKU,
+			Preferred:   link.Preferred,
+			Price:       byKey[key{link.ProductID, link.SupplierID}],
+		})
+	}
+
+	for _, list := range result {
+		sort.SliceStable(list, func(i, j int) bool {
+			a, b := list[i].Price, list[j].Price
+			if a == nil || b == nil {
+				return a != nil && b == nil
+			}
+			return a.UnitCost() < b.UnitCost()
+		})
+	}
+	return result, nil
+}
@@ -0,0 +1,382 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"grocery-store-api/models"
+	"grocery-store-api/repositories"
+	"grocery-store-api/services/errs"
+)
+
+// Классы XYZ-анализа: X — стабильный спрос, Y — колеблющийся, Z —
+// нерегулярный. Классы ABC совпадают с сортами models.GradeA…GradeC.
+const (
+	DemandStable    = "X"
+	DemandVariable  = "Y"
+	DemandIrregular = "Z"
+)
+
+// minDemandWeeks — меньше стольких недель спрос не оценивается: товар,
+// впервые проданный недавно, считается по последним minDemandWeeks неделям.
+// maxWindowWeeks — предел окна анализа.
+const (
+	minDemandWeeks = 4
+	maxWindowWeeks = 104
+)
+
+var ErrInvalidWindow = errs.NewValidation("invalid_window", fmt.Sprintf("окно анализа — от %d до %d недель", minDemandWeeks, maxWindowWeeks))
+
+// ABCXYZPolicy задает параметры ABC/XYZ-анализа. Товары, дающие первые
+// AShare выручки, относятся к классу A, следующие до BShare — к B,
+// остальные — к C. Спрос с коэффициентом вариации недельных продаж не
+// больше XMaxVariation — класс X, не больше YMaxVariation — Y, выше — Z.
+type ABCXYZPolicy struct {
+	Weeks         int
+	AShare        float64
+	BShare        float64
+	XMaxVariation float64
+	YMaxVariation float64
+}
+
+var DefaultABCXYZPolicy = ABCXYZPolicy{
+	Weeks:         13,
+	AShare:        0.8,
+	BShare:        0.95,
+	XMaxVariation: 0.25,
+	YMaxVariation: 0.5,
+}
+
+// ProductClass — классы товара по ABC/XYZ-анализу. Share — доля товара в
+// выручке, CumulativeShare — доля с учетом всех более доходных товаров.
+// Variation — коэффициент вариации недельного спроса за DemandWeeks
+// недель; nil, если товар не продавался.
+type ProductClass struct {
+	Product         models.Product
+	Revenue         float64
+	Share           float64
+	CumulativeShare float64
+	Quantity        int64
+	DemandWeeks     int
+	AvgWeekly       float64
+	Variation       *float64
+	ABC             string
+	XYZ             string
+}
+
+// Class возвращает ячейку матрицы, например AX.
+func (c *ProductClass) Class() string {
+	return c.ABC + c.XYZ
+}
+
+// ABCXYZCell — ячейка матрицы ABC/XYZ.
+type ABCXYZCell struct {
+	Class    string
+	Products int
+	Revenue  float64
+	Share    float64
+}
+
+// ABCXYZAnalysis — ABC/XYZ-анализ активных товаров за период [From, To).
+// Cells идут от AX к CZ, Products — по убыванию выручки.
+type ABCXYZAnalysis struct {
+	From, To time.Time
+	Policy   ABCXYZPolicy
+	Revenue  float64
+	Cells    []ABCXYZCell
+	Products []ProductClass
+}
+
+// GradeChange — сорт товара, замененный классом ABC.
+type GradeChange struct {
+	Product models.Product
+	From    string
+	To      string
+}
+
+// ClassificationService относит товары к классам ABC по вкладу в выручку
+// и XYZ по стабильности спроса и может записывать класс ABC в сорт товара.
+// Если ApplyInterval больше нуля, Run делает это периодически.
+type ClassificationService struct {
+	SaleRepo      repositories.SaleRepository
+	ProductRepo   repositories.ProductRepository
+	Scope         DepartmentScope
+	Audit         AuditService
+	Policy        ABCXYZPolicy
+	ApplyInterval time.Duration
+}
+
+// Classify проводит ABC/XYZ-анализ товаров отделов, доступных
+// пользователю, за weeks недель, заканчивающихся днем day. weeks <= 0
+// заменяется окном из политики.
+func (s *ClassificationService) Classify(actor Actor, day time.Time, weeks int) (*ABCXYZAnalysis, error) {
+	ids, all, err := s.Scope.Departments(actor)
+	if err != nil {
+		return nil, err
+	}
+	if all {
+		ids = nil
+	}
+	return s.classify(ids, day, weeks)
+}
+
+// Apply записывает класс ABC в сорт товаров доступных пользователю
+// отделов, у которых он отличается. Классы считаются по всем товарам, как
+// в Run: иначе сорт зависел бы от того, чьи отделы попали в анализ, и
+// менялся бы при каждом фоновом пересчете. Возвращает сделанные замены.
+func (s *ClassificationService) Apply(actor Actor, day time.Time, weeks int) ([]GradeChange, error) {
+	ids, all, err := s.Scope.Departments(actor)
+	if err != nil {
+		return nil, err
+	}
+
+	analysis, err := s.classify(nil, day, weeks)
+	if err != nil {
+		return nil, err
+	}
+
+	if !all {
+		allowed := make(map[uint]bool, len(ids))
+		for _, id := range ids {
+			allowed[id] = true
+		}
+		products := analysis.Products[:0]
+		for _, class := range analysis.Products {
+			if allowed[class.Product.DepartmentID] {
+				products = append(products, class)
+			}
+		}
+		analysis.Products = products
+	}
+
+	return s.applyGrades(actor, analysis)
+}
+
+func (s *ClassificationService) applyGrades(actor Actor, analysis *ABCXYZAnalysis) ([]GradeChange, error) {
+	var changes []GradeChange
+	for i := range analysis.Products {
+		class := &analysis.Products[i]
+		if class.Product.Grade == class.ABC {
+			continue
+		}
+
+		if err := s.ProductRepo.SetGrade(class.Product.ID, class.ABC); err != nil {
+			return changes, err
+		}
+
+		before := class.Product
+		class.Product.Grade = class.ABC
+		class.Product.Version++
+		s.Audit.Record(actor, models.AuditUpdate, AuditEntityProduct, before.ID, before, class.Product)
+		changes = append(changes, GradeChange{Product: class.Product, From: before.Grade, To: class.ABC})
+	}
+	return changes, nil
+}
+
+// Run периодически пересчитывает классы всех товаров и записывает их в
+// сорт, пока не отменен ctx. При ApplyInterval <= 0 ничего не делает.
+func (s *ClassificationService) Run(ctx context.Context) {
+	if s.ApplyInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(s.ApplyInterval)
+	defer ticker.Stop()
+
+	for {
+		analysis, err := s.classify(nil, time.Now(), 0)
+		if err == nil {
+			var changes []GradeChange
+			changes, err = s.applyGrades(systemActor, analysis)
+			if len(changes) > 0 {
+				log.Printf("ABC/XYZ-анализ: сорт обновлен у %d товаров", len(changes))
+			}
+		}
+		if err != nil {
+			log.Printf("ABC/XYZ-анализ: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// classify проводит анализ товаров отделов departmentIDs (nil — всех).
+func (s *ClassificationService) classify(departmentIDs []uint, day time.Time, weeks int) (*ABCXYZAnalysis, error) {
+	policy := s.Policy
+	if policy.Weeks <= 0 {
+		policy = DefaultABCXYZPolicy
+	}
+	if weeks > 0 {
+		policy.Weeks = weeks
+	}
+	if policy.Weeks < minDemandWeeks || policy.Weeks > maxWindowWeeks {
+		return nil, ErrInvalidWindow
+	}
+
+	to := startOfDay(day).AddDate(0, 0, 1)
+	from := to.AddDate(0, 0, -7*policy.Weeks)
+
+	var products []models.Product
+	var err error
+	if departmentIDs == nil {
+		products, err = s.ProductRepo.FindAll()
+	} else {
+		products, err = s.ProductRepo.FindByDepartments(departmentIDs)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.SaleRepo.WeeklyProductSales(from, to, departmentIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	established, err := s.SaleRepo.ProductsSoldBefore(from)
+	if err != nil {
+		return nil, err
+	}
+	soldBefore := make(map[uint]bool, len(established))
+	for _, id := range established {
+		soldBefore[id] = true
+	}
+
+	analysis := analyzeSales(policy, products, rows, soldBefore)
+	analysis.From, analysis.To = from, to
+	return analysis, nil
+}
+
+// analyzeSales относит товары products к классам ABC и XYZ по недельным
+// продажам rows за окно policy.Weeks недель. soldBefore — товары,
+// продававшиеся до начала окна: их спрос оценивается за все окно.
+func analyzeSales(policy ABCXYZPolicy, products []models.Product, rows []repositories.ProductWeekSales, soldBefore map[uint]bool) *ABCXYZAnalysis {
+	type demand struct {
+		weekly    []int64
+		firstWeek int
+	}
+	classes := make([]ProductClass, 0, len(products))
+	demands := make(map[uint]*demand, len(products))
+	index := make(map[uint]int, len(products))
+	for i, product := range products {
+		classes = append(classes, ProductClass{Product: product})
+		demands[product.ID] = &demand{weekly: make([]int64, policy.Weeks), firstWeek: policy.Weeks}
+		index[product.ID] = i
+	}
+
+	analysis := &ABCXYZAnalysis{Policy: policy}
+	for _, row := range rows {
+		i, ok := index[row.ProductID]
+		if !ok {
+			// Архивный товар: в анализ не входит
+			continue
+		}
+		week := min(max(row.Week, 0), policy.Weeks-1)
+		d := demands[row.ProductID]
+		d.weekly[week] += row.Quantity
+		d.firstWeek = min(d.firstWeek, week)
+		classes[i].Quantity += row.Quantity
+		classes[i].Revenue += row.Revenue
+		analysis.Revenue += row.Revenue
+	}
+
+	sort.SliceStable(classes, func(i, j int) bool {
+		if classes[i].Revenue != classes[j].Revenue {
+			return classes[i].Revenue > classes[j].Revenue
+		}
+		if classes[i].Quantity != classes[j].Quantity {
+			return classes[i].Quantity > classes[j].Quantity
+		}
+		return classes[i].Product.ID < classes[j].Product.ID
+	})
+
+	cells := make(map[string]*ABCXYZCell)
+	for _, abc := range models.Grades {
+		for _, xyz := range []string{DemandStable, DemandVariable, DemandIrregular} {
+			analysis.Cells = append(analysis.Cells, ABCXYZCell{Class: abc + xyz})
+		}
+	}
+	for i := range analysis.Cells {
+		cells[analysis.Cells[i].Class] = &analysis.Cells[i]
+	}
+
+	var cumulative float64
+	for i := range classes {
+		class := &classes[i]
+		class.Revenue = roundMoney(class.Revenue)
+
+		// Класс определяется долей выручки до товара, поэтому товар, на
+		// котором накопленная доля переходит порог, остается в старшем классе
+		class.ABC = models.GradeC
+		if analysis.Revenue > 0 && class.Revenue > 0 {
+			class.Share = class.Revenue / analysis.Revenue
+			switch {
+			case cumulative < policy.AShare:
+				class.ABC = models.GradeA
+			case cumulative < policy.BShare:
+				class.ABC = models.GradeB
+			}
+			cumulative += class.Share
+		}
+		class.CumulativeShare = cumulative
+
+		d := demands[class.Product.ID]
+		class.DemandWeeks = policy.Weeks
+		class.XYZ = DemandIrregular
+		if class.Quantity > 0 {
+			start := 0
+			if !soldBefore[class.Product.ID] {
+				start = max(min(d.firstWeek, policy.Weeks-minDemandWeeks), 0)
+			}
+			class.DemandWeeks = policy.Weeks - start
+			mean, variation := demandVariation(d.weekly[start:])
+			class.AvgWeekly = mean
+			class.Variation = &variation
+			switch {
+			case variation <= policy.XMaxVariation:
+				class.XYZ = DemandStable
+			case variation <= policy.YMaxVariation:
+				class.XYZ = DemandVariable
+			}
+		}
+
+		cell := cells[class.Class()]
+		cell.Products++
+		cell.Revenue += class.Revenue
+	}
+
+	for i := range analysis.Cells {
+		cell := &analysis.Cells[i]
+		cell.Revenue = roundMoney(cell.Revenue)
+		if analysis.Revenue > 0 {
+			cell.Share = cell.Revenue / analysis.Revenue
+		}
+	}
+	analysis.Revenue = roundMoney(analysis.Revenue)
+	analysis.Products = classes
+	return analysis
+}
+
+// demandVariation возвращает средние недельные продажи и коэффициент
+// вариации — отношение стандартного отклонения к среднему.
+func demandVariation(weekly []int64) (mean, variation float64) {
+	for _, quantity := range weekly {
+		mean += float64(quantity)
+	}
+	mean /= float64(len(weekly))
+	if mean == 0 {
+		return 0, 0
+	}
+
+	var squares float64
+	for _, quantity := range weekly {
+		squares += (float64(quantity) - mean) * (float64(quantity) - mean)
+	}
+	return mean, math.Sqrt(squares/float64(len(weekly))) / mean
+}
@@ -0,0 +1,200 @@
+package services
+
+import (
+	"testing"
+
+	"grocery-store-api/models"
+	"grocery-store-api/repositories"
+)
+
+// weeklySales строит продажи товаров по неделям: sales[i][w] — количество
+// товара с ID i+1 за неделю w по цене price.
+func weeklySales(sales [][]int64, price float64) ([]models.Product, []repositories.ProductWeekSales) {
+	var products []models.Product
+	var rows []repositories.ProductWeekSales
+	for i, weeks := range sales {
+		id := uint(i + 1)
+		products = append(products, models.Product{ID: id})
+		for week, quantity := range weeks {
+			if quantity > 0 {
+				rows = append(rows, repositories.ProductWeekSales{ProductID: id, Week: week, Quantity: quantity, Revenue: float64(quantity) * price})
+			}
+		}
+	}
+	return products, rows
+}
+
+func classesByID(analysis *ABCXYZAnalysis) map[uint]ProductClass {
+	classes := make(map[uint]ProductClass, len(analysis.Products))
+	for _, class := range analysis.Products {
+		classes[class.Product.ID] = class
+	}
+	return classes
+}
+
+func TestAnalyzeSalesABC(t *testing.T) {
+	tests := []struct {
+		name string
+		// revenue — выручка товаров с ID 1, 2, ... за одну неделю
+		revenue []int64
+		want    []string
+	}{
+		{
+			name:    "доля до товара ровно на пороге",
+			revenue: []int64{80, 15, 5},
+			want:    []string{models.GradeA, models.GradeB, models.GradeC},
+		},
+		{
+			name:    "товар, переходящий порог, остается в старшем классе",
+			revenue: []int64{70, 20, 6, 4},
+			want:    []string{models.GradeA, models.GradeA, models.GradeB, models.GradeC},
+		},
+		{
+			name:    "один товар",
+			revenue: []int64{10},
+			want:    []string{models.GradeA},
+		},
+		{
+			name:    "товар без продаж",
+			revenue: []int64{50, 0},
+			want:    []string{models.GradeA, models.GradeC},
+		},
+		{
+			name:    "нет выручки",
+			revenue: []int64{0, 0},
+			want:    []string{models.GradeC, models.GradeC},
+		},
+		{
+			name:    "порядок не влияет на класс",
+			revenue: []int64{5, 15, 80},
+			want:    []string{models.GradeC, models.GradeB, models.GradeA},
+		},
+	}
+
+	policy := DefaultABCXYZPolicy
+	policy.Weeks = minDemandWeeks
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sales := make([][]int64, len(tt.revenue))
+			for i, revenue := range tt.revenue {
+				sales[i] = []int64{revenue}
+			}
+			products, rows := weeklySales(sales, 1)
+
+			classes := classesByID(analyzeSales(policy, products, rows, nil))
+			for i, want := range tt.want {
+				if got := classes[uint(i+1)].ABC; got != want {
+					t.Errorf("product %d: ABC = %s, want %s", i+1, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestAnalyzeSalesXYZ(t *testing.T) {
+	tests := []struct {
+		name       string
+		weekly     []int64
+		soldBefore bool
+		want       string
+		// demandWeeks — за сколько недель оценивается спрос
+		demandWeeks int
+	}{
+		{
+			name:        "ровный спрос",
+			weekly:      []int64{4, 4, 4, 4},
+			soldBefore:  true,
+			want:        DemandStable,
+			demandWeeks: 4,
+		},
+		{
+			name:        "вариация ровно на пороге X",
+			weekly:      []int64{3, 5, 3, 5},
+			soldBefore:  true,
+			want:        DemandStable,
+			demandWeeks: 4,
+		},
+		{
+			name:        "вариация ровно на пороге Y",
+			weekly:      []int64{2, 6, 2, 6},
+			soldBefore:  true,
+			want:        DemandVariable,
+			demandWeeks: 4,
+		},
+		{
+			name:        "вариация выше порога Y",
+			weekly:      []int64{1, 7, 1, 7},
+			soldBefore:  true,
+			want:        DemandIrregular,
+			demandWeeks: 4,
+		},
+		{
+			name:        "нет продаж",
+			weekly:      []int64{0, 0, 0, 0},
+			want:        DemandIrregular,
+			demandWeeks: 4,
+		},
+		{
+			name:        "новый товар оценивается с первой продажи",
+			weekly:      []int64{0, 0, 0, 0, 4, 4, 4, 4},
+			want:        DemandStable,
+			demandWeeks: 4,
+		},
+		{
+			name:        "новый товар оценивается не меньше чем за minDemandWeeks недель",
+			weekly:      []int64{0, 0, 0, 0, 0, 0, 4, 4},
+			want:        DemandIrregular,
+			demandWeeks: minDemandWeeks,
+		},
+		{
+			name:        "давний товар оценивается за все окно",
+			weekly:      []int64{0, 0, 0, 0, 4, 4, 4, 4},
+			soldBefore:  true,
+			want:        DemandIrregular,
+			demandWeeks: 8,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := DefaultABCXYZPolicy
+			policy.Weeks = len(tt.weekly)
+			products, rows := weeklySales([][]int64{tt.weekly}, 1)
+			soldBefore := map[uint]bool{1: tt.soldBefore}
+
+			class := classesByID(analyzeSales(policy, products, rows, soldBefore))[1]
+			if class.XYZ != tt.want {
+				t.Errorf("XYZ = %s, want %s", class.XYZ, tt.want)
+			}
+			if class.DemandWeeks != tt.demandWeeks {
+				t.Errorf("DemandWeeks = %d, want %d", class.DemandWeeks, tt.demandWeeks)
+			}
+			if (class.Variation == nil) != (class.Quantity == 0) {
+				t.Errorf("Variation = %v with quantity %d", class.Variation, class.Quantity)
+			}
+		})
+	}
+}
+
+func TestAnalyzeSalesCells(t *testing.T) {
+	policy := DefaultABCXYZPolicy
+	policy.Weeks = minDemandWeeks
+	products, rows := weeklySales([][]int64{{4, 4, 4, 4}, {1, 7, 1, 7}, {0, 0, 0, 0}}, 1)
+	// Архивный товар без карточки не входит в анализ
+	rows = append(rows, repositories.ProductWeekSales{ProductID: 99, Week: 0, Quantity: 100, Revenue: 100})
+
+	analysis := analyzeSales(policy, products, rows, map[uint]bool{1: true, 2: true})
+
+	if analysis.Revenue != 32 {
+		t.Errorf("Revenue = %v, want 32", analysis.Revenue)
+	}
+	if len(analysis.Cells) != 9 || analysis.Cells[0].Class != "AX" || analysis.Cells[8].Class != "CZ" {
+		t.Fatalf("Cells = %+v, want AX…CZ", analysis.Cells)
+	}
+	want := map[string]int{"AX": 1, "AZ": 1, "CZ": 1}
+	for _, cell := range analysis.Cells {
+		if cell.Products != want[cell.Class] {
+			t.Errorf("cell %s: Products = %d, want %d", cell.Class, cell.Products, want[cell.Class])
+		}
+	}
+}
@@ -0,0 +1,283 @@
+package services
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"grocery-store-api/models"
+	"grocery-store-api/repositories"
+	"grocery-store-api/services/errs"
+)
+
+var (
+	ErrCustomerNotFound   = errs.NewNotFound("customer_not_found", "покупатель не найден")
+	ErrUnknownCustomer    = errs.NewValidation("unknown_customer", "указанный покупатель не существует")
+	ErrCustomerExists     = errs.NewConflict("customer_exists", "покупатель с таким телефоном или номером карты уже зарегистрирован")
+	ErrInvalidPhone       = errs.NewValidation("invalid_phone", "некорректный номер телефона, ожидается 10–15 цифр")
+	ErrCustomerRequired   = errs.NewValidation("customer_required", "для списания баллов укажите покупателя")
+	ErrRedeemLimit        = errs.NewValidation("redeem_limit_exceeded", "баллами можно оплатить не больше допустимой в отделе доли покупки")
+	ErrInsufficientPoints = errs.NewConflict("insufficient_points", "на бонусном счете недостаточно баллов")
+)
+
+// LoyaltyBalance — состояние бонусного счета покупателя.
+type LoyaltyBalance struct {
+	Customer models.Customer
+	Earned   int64
+	Redeemed int64
+}
+
+// CustomerService ведет покупателей и их бонусные счета. Один балл равен
+// одному рублю при оплате.
+type CustomerService struct {
+	Repo            repositories.CustomerRepository
+	TransactionRepo repositories.LoyaltyTransactionRepository
+	Audit           AuditService
+}
+
+func (s *CustomerService) CreateCustomer(actor Actor, customer *models.Customer) error {
+	phone, err := normalizePhone(customer.Phone)
+	if err != nil {
+		return err
+	}
+	customer.Phone = phone
+
+	if customer.CardNumber == "" {
+		if customer.CardNumber, err = newCardNumber(); err != nil {
+			return err
+		}
+	}
+	customer.PointsBalance = 0
+	customer.Version = 1
+
+	if err := s.Repo.Create(customer); err != nil {
+		return customerExists(err)
+	}
+
+	s.Audit.Record(actor, models.AuditCreate, AuditEntityCustomer, customer.ID, nil, customer)
+	return nil
+}
+
+func (s *CustomerService) GetCustomerByID(id uint) (*models.Customer, error) {
+	customer, err := s.Repo.FindByID(id)
+	return customer, notFound(err, ErrCustomerNotFound)
+}
+
+// GetCustomers ищет покупателей. Из запроса, похожего на телефон,
+// убираются скобки и пробелы, а номер, набранный через 8, дополнительно
+// ищется по началу телефона с 7.
+func (s *CustomerService) GetCustomers(filter models.CustomerFilter) ([]models.Customer, error) {
+	if filter.Limit <= 0 || filter.Limit > 1000 {
+		filter.Limit = 100
+	}
+
+	filter.Query = strings.TrimSpace(filter.Query)
+	if filter.Query != "" && strings.Trim(filter.Query, "+0123456789 -()") == "" {
+		filter.Query = phoneDigits(filter.Query)
+		if strings.HasPrefix(filter.Query, "8") {
+			filter.Phone = "7" + filter.Query[1:]
+		}
+	}
+	return s.Repo.Find(filter)
+}
+
+func (s *CustomerService) UpdateCustomer(actor Actor, customer *models.Customer, version uint) error {
+	existing, err := s.Repo.FindByID(customer.ID)
+	if err != nil {
+		return notFound(err, ErrCustomerNotFound)
+	}
+
+	if err := checkVersion(version, existing.Version); err != nil {
+		return err
+	}
+
+	phone, err := normalizePhone(customer.Phone)
+	if err != nil {
+		return err
+	}
+	customer.Phone = phone
+	if customer.CardNumber == "" {
+		customer.CardNumber = existing.CardNumber
+	}
+
+	if err := s.Repo.Update(customer, existing.Version); err != nil {
+		return customerExists(staleVersion(err))
+	}
+
+	updated, err := s.Repo.FindByID(customer.ID)
+	if err != nil {
+		return err
+	}
+
+	s.Audit.Record(actor, models.AuditUpdate, AuditEntityCustomer, customer.ID, existing, updated)
+	*customer = *updated
+	return nil
+}
+
+func (s *CustomerService) GetBalance(id uint) (*LoyaltyBalance, error) {
+	customer, err := s.GetCustomerByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	totals, err := s.TransactionRepo.TotalsByCustomer(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LoyaltyBalance{Customer: *customer, Earned: totals.Earned, Redeemed: totals.Redeemed}, nil
+}
+
+// GetHistory возвращает операции по бонусному счету, новые первыми.
+func (s *CustomerService) GetHistory(id uint, limit, offset int) ([]models.LoyaltyTransaction, error) {
+	if _, err := s.GetCustomerByID(id); err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 || limit > 1000 {
+		limit = 100
+	}
+	return s.TransactionRepo.FindByCustomer(id, limit, offset)
+}
+
+// AdjustPoints вручную начисляет (points > 0) или списывает (points < 0)
+// баллы, например при жалобе покупателя. Уйти в минус счет не может.
+func (s *CustomerService) AdjustPoints(actor Actor, id uint, points int, comment string) (*models.LoyaltyTransaction, error) {
+	if _, err := s.GetCustomerByID(id); err != nil {
+		return nil, err
+	}
+
+	entry := &models.LoyaltyTransaction{
+		CustomerID: id,
+		Type:       models.LoyaltyAdjustment,
+		Points:     points,
+		Comment:    comment,
+		CreatedBy:  actor.UserID,
+	}
+	if err := s.Repo.AddPoints(entry); err != nil {
+		if errors.Is(err, repositories.ErrInsufficientPoints) {
+			return nil, ErrInsufficientPoints
+		}
+		return nil, err
+	}
+
+	s.Audit.Record(actor, models.AuditCreate, AuditEntityLoyaltyTransaction, entry.ID, nil, entry)
+	return entry, nil
+}
+
+// PrepareLoyalty проверяет списание баллов в оплату продажи и считает
+// начисление по правилам отдела товара. В sale.PointsRedeemed приходит
+// запрошенное списание. Баланс проверяется заранее, чтобы не проводить
+// оплату картой впустую; окончательно он проверяется при сохранении.
+func (s *CustomerService) PrepareLoyalty(sale *models.Sale, department *models.Department) error {
+	if sale.CustomerID == nil {
+		if sale.PointsRedeemed > 0 {
+			return ErrCustomerRequired
+		}
+		return nil
+	}
+
+	customer, err := s.Repo.FindByID(*sale.CustomerID)
+	if err != nil {
+		return notFound(err, ErrUnknownCustomer)
+	}
+
+	if sale.PointsRedeemed > 0 {
+		limit := int(math.Floor(sale.TotalPrice * department.LoyaltyRedeemPercent / 100))
+		if sale.PointsRedeemed > limit {
+			return errs.NewValidation(ErrRedeemLimit.Code, fmt.Sprintf("%s: не больше %d баллов", ErrRedeemLimit.Message, limit))
+		}
+		if sale.PointsRedeemed > customer.PointsBalance {
+			return ErrInsufficientPoints
+		}
+	}
+
+	// Баллы начисляются только на сумму, оплаченную деньгами
+	paid := sale.TotalPrice - float64(sale.PointsRedeemed)
+	sale.PointsEarned = int(math.Floor(roundMoney(paid*department.LoyaltyAccrualPercent) / 100))
+	return nil
+}
+
+// normalizePhone оставляет в номере только цифры и приводит российские
+// номера к виду 7XXXXXXXXXX.
+func normalizePhone(phone string) (string, error) {
+	digits := phoneDigits(phone)
+	switch {
+	case len(digits) == 10:
+		digits = "7" + digits
+	case len(digits) == 11 && digits[0] == '8':
+		digits = "7" + digits[1:]
+	}
+
+	if len(digits) < 10 || len(digits) > 15 {
+		return "", ErrInvalidPhone
+	}
+	return digits, nil
+}
+
+func phoneDigits(phone string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, phone)
+}
+
+// newCardNumber выдает номер бонусной карты: 13 цифр с префиксом 29.
+func newCardNumber() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1e11))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("29%011d", n), nil
+}
+
+// customerExists заменяет нарушение уникальности телефона или карты на
+// ошибку предметной области.
+func customerExists(err error) error {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return ErrCustomerExists
+	}
+	return err
+}
+
+// CustomerAnalytics — выручка за период в разрезе покупателей: доля
+// продаж с картой покупателя, повторные покупки и лучшие покупатели.
+type CustomerAnalytics struct {
+	Stats repositories.CustomerSalesStats
+	Top   []repositories.CustomerRevenue
+}
+
+// topCustomersLimit — сколько лучших покупателей попадает в аналитику.
+const topCustomersLimit = 10
+
+// GetCustomerAnalytics считает аналитику по покупателям за период
+// [from, to) по товарам отделов, доступных пользователю.
+func (s *SaleService) GetCustomerAnalytics(actor Actor, from, to time.Time) (*CustomerAnalytics, error) {
+	ids, all, err := s.Scope.Departments(actor)
+	if err != nil {
+		return nil, err
+	}
+	if all {
+		ids = nil
+	}
+
+	stats, err := s.Repo.CustomerStats(from, to, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	top, err := s.Repo.TopCustomers(from, to, ids, topCustomersLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CustomerAnalytics{Stats: stats, Top: top}, nil
+}
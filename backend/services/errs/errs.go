@@ -0,0 +1,87 @@
+// Package errs содержит типизированные ошибки предметной области. Сервисы
+// возвращают их, а контроллеры по виду ошибки выбирают HTTP-статус и код
+// ответа, не разбирая текст сообщений.
+package errs
+
+import "errors"
+
+type Kind string
+
+const (
+	NotFound        Kind = "not_found"
+	Conflict        Kind = "conflict"
+	Validation      Kind = "validation"
+	Forbidden       Kind = "forbidden"
+	Unauthorized    Kind = "unauthorized"
+	TooManyRequests Kind = "too_many_requests"
+	// PreconditionFailed — запись изменилась с тех пор, как клиент ее прочитал.
+	PreconditionFailed Kind = "precondition_failed"
+	// PaymentRequired — оплата не прошла, например банк отклонил карту.
+	PaymentRequired Kind = "payment_required"
+	// Unavailable — внешняя система (например, платежный терминал) не отвечает.
+	Unavailable Kind = "unavailable"
+)
+
+// Error — ошибка предметной области. Code — стабильный машиночитаемый код,
+// Message — сообщение для пользователя, Fields — ошибки отдельных полей
+// для ошибок валидации.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	Fields  []FieldError
+}
+
+// FieldError описывает нарушенное правило для одного поля запроса. Field —
+// путь к полю в JSON, например items[0].quantity.
+type FieldError struct {
+	Field   string `json:"field" example:"price"`
+	Rule    string `json:"rule" example:"gt"`
+	Message string `json:"message" example:"должно быть больше 0"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func New(kind Kind, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+func NewNotFound(code, message string) *Error {
+	return New(NotFound, code, message)
+}
+
+// NewFieldValidation создает ошибку валидации со списком ошибок полей.
+func NewFieldValidation(code, message string, fields []FieldError) *Error {
+	return &Error{Kind: Validation, Code: code, Message: message, Fields: fields}
+}
+
+func NewConflict(code, message string) *Error {
+	return New(Conflict, code, message)
+}
+
+func NewValidation(code, message string) *Error {
+	return New(Validation, code, message)
+}
+
+func NewForbidden(code, message string) *Error {
+	return New(Forbidden, code, message)
+}
+
+func NewUnauthorized(code, message string) *Error {
+	return New(Unauthorized, code, message)
+}
+
+func NewTooManyRequests(code, message string) *Error {
+	return New(TooManyRequests, code, message)
+}
+
+// As возвращает ошибку предметной области из цепочки err, если она там есть.
+func As(err error) (*Error, bool) {
+	var domainErr *Error
+	if errors.As(err, &domainErr) {
+		return domainErr, true
+	}
+	return nil, false
+}
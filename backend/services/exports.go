@@ -0,0 +1,58 @@
+package services
+
+import (
+	"time"
+
+	"grocery-store-api/models"
+	"grocery-store-api/repositories"
+)
+
+// ExportService читает данные для выгрузок пачками, чтобы выгрузка за
+// большой период не загружала все записи в память. Продажи и товары
+// ограничиваются отделами, доступными пользователю.
+type ExportService struct {
+	SaleRepo       repositories.SaleRepository
+	SupplyItemRepo repositories.SupplyItemRepository
+	ProductRepo    repositories.ProductRepository
+	Scope          DepartmentScope
+}
+
+// EachSale передает в fn продажи за период [from, to).
+func (s *ExportService) EachSale(actor Actor, from, to time.Time, fn func([]models.Sale) error) error {
+	ids, err := s.departments(actor)
+	if err != nil {
+		return err
+	}
+	return s.SaleRepo.EachInPeriod(from, to, ids, fn)
+}
+
+// EachSupplyItem передает в fn позиции поставок за период [from, to).
+func (s *ExportService) EachSupplyItem(from, to time.Time, fn func([]models.SupplyItem) error) error {
+	return s.SupplyItemRepo.EachInPeriod(from, to, fn)
+}
+
+func (s *ExportService) EachProduct(actor Actor, fn func([]models.Product) error) error {
+	ids, err := s.departments(actor)
+	if err != nil {
+		return err
+	}
+	return s.ProductRepo.Each(ids, fn)
+}
+
+func (s *ExportService) EachLowStockProduct(actor Actor, fn func([]models.Product) error) error {
+	ids, err := s.departments(actor)
+	if err != nil {
+		return err
+	}
+	return s.ProductRepo.EachLowStock(ids, fn)
+}
+
+// departments возвращает отделы для фильтра выборки или nil, если доступ
+// пользователя не ограничен.
+func (s *ExportService) departments(actor Actor) ([]uint, error) {
+	ids, all, err := s.Scope.Departments(actor)
+	if err != nil || all {
+		return nil, err
+	}
+	return ids, nil
+}
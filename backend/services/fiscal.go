@@ -0,0 +1,299 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"grocery-store-api/models"
+	"grocery-store-api/repositories"
+)
+
+// FiscalItem — позиция фискального чека.
+type FiscalItem struct {
+	Name     string  `json:"name"`
+	Quantity float64 `json:"quantity"`
+	Price    float64 `json:"price"`
+	Sum      float64 `json:"sum"`
+}
+
+// FiscalPayment — сумма оплаты чека одним способом.
+type FiscalPayment struct {
+	Method string  `json:"method"`
+	Amount float64 `json:"amount"`
+}
+
+// Признак расчета фискального чека.
+const (
+	ReceiptIncome       = "income"        // приход — продажа
+	ReceiptIncomeReturn = "income_return" // возврат прихода — возврат товара
+)
+
+// FiscalReceipt — чек, который передается в фискальный регистратор: чек
+// прихода продажи SaleID или чек возврата прихода по возврату ReturnID.
+type FiscalReceipt struct {
+	Type     string          `json:"type"`
+	SaleID   uint            `json:"sale_id"`
+	ReturnID uint            `json:"return_id,omitempty"`
+	Date     time.Time       `json:"date"`
+	Cashier  string          `json:"cashier"`
+	Items    []FiscalItem    `json:"items"`
+	Total    float64         `json:"total"`
+	Payments []FiscalPayment `json:"payments"`
+}
+
+// FiscalDocument — реквизиты зарегистрированного чека.
+type FiscalDocument struct {
+	Number       string    `json:"number"`
+	FiscalSign   string    `json:"fiscal_sign"`
+	RegisteredAt time.Time `json:"registered_at"`
+}
+
+// FiscalDriver регистрирует чеки в фискальном регистраторе или ОФД.
+// Повторная регистрация чека той же продажи или того же возврата
+// (например, после обрыва связи до получения ответа) должна возвращать уже
+// выданный документ, а не пробивать чек второй раз.
+type FiscalDriver interface {
+	Register(ctx context.Context, receipt FiscalReceipt) (*FiscalDocument, error)
+}
+
+// FiscalPolicy задает расписание повторов фискализации.
+type FiscalPolicy struct {
+	// Задержка перед повтором удваивается после каждой неудачи, начиная
+	// с RetryDelay, но не превышает MaxRetryDelay.
+	RetryDelay    time.Duration
+	MaxRetryDelay time.Duration
+	// PollInterval — как часто фоновый обработчик проверяет очередь.
+	PollInterval time.Duration
+	// Timeout ограничивает одно обращение к регистратору; на это время
+	// запись очереди захватывается.
+	Timeout   time.Duration
+	BatchSize int
+}
+
+var DefaultFiscalPolicy = FiscalPolicy{
+	RetryDelay:    30 * time.Second,
+	MaxRetryDelay: 30 * time.Minute,
+	PollInterval:  10 * time.Second,
+	Timeout:       30 * time.Second,
+	BatchSize:     50,
+}
+
+// FiscalService регистрирует продажи и возвраты через FiscalDriver. Чек
+// попадает в очередь (outbox) при создании продажи или возврата; первая
+// попытка делается сразу после них, а неудачные повторяются фоновым
+// обработчиком Run.
+type FiscalService struct {
+	Driver     FiscalDriver
+	SaleRepo   repositories.SaleRepository
+	ReturnRepo repositories.SaleReturnRepository
+	OutboxRepo repositories.FiscalOutboxRepository
+	Policy     FiscalPolicy
+}
+
+// Fiscalize пытается зарегистрировать продажу и обновляет ее фискальные
+// поля. Ошибка регистратора не возвращается: продажа уже совершена, а
+// попытка будет повторена позже.
+func (s *FiscalService) Fiscalize(sale *models.Sale) {
+	entry, document, ok := s.register(sale.ID, nil, newFiscalReceipt(sale))
+	if !ok {
+		if entry != nil {
+			sale.FiscalStatus = models.FiscalFailed
+		}
+		return
+	}
+
+	sale.FiscalStatus = models.FiscalRegistered
+	sale.FiscalDocumentNumber = document.Number
+	sale.FiscalSign = document.FiscalSign
+	sale.FiscalizedAt = &document.RegisteredAt
+	if err := s.SaleRepo.MarkFiscalized(sale); err != nil {
+		// Чек пробит, но не сохранен: следующая попытка получит тот же
+		// документ от регистратора
+		s.reschedule(entry, err)
+		sale.FiscalStatus = models.FiscalFailed
+	}
+}
+
+// FiscalizeReturn пытается зарегистрировать чек возврата прихода и
+// обновляет фискальные поля возврата. В ret должны быть загружены продажа
+// с товаром, оформивший возврат и выплаты.
+func (s *FiscalService) FiscalizeReturn(ret *models.SaleReturn) {
+	entry, document, ok := s.register(ret.SaleID, &ret.ID, newReturnReceipt(ret))
+	if !ok {
+		if entry != nil {
+			ret.FiscalStatus = models.FiscalFailed
+		}
+		return
+	}
+
+	ret.FiscalStatus = models.FiscalRegistered
+	ret.FiscalDocumentNumber = document.Number
+	ret.FiscalSign = document.FiscalSign
+	ret.FiscalizedAt = &document.RegisteredAt
+	if err := s.ReturnRepo.MarkFiscalized(ret); err != nil {
+		s.reschedule(entry, err)
+		ret.FiscalStatus = models.FiscalFailed
+	}
+}
+
+// register захватывает запись очереди и передает чек регистратору. Если
+// запись не захвачена, entry равен nil; если регистрация не удалась,
+// попытка откладывается.
+func (s *FiscalService) register(saleID uint, returnID *uint, receipt FiscalReceipt) (*models.FiscalOutboxEntry, *FiscalDocument, bool) {
+	now := time.Now()
+	entry, claimed, err := s.OutboxRepo.Claim(saleID, returnID, now, now.Add(s.Policy.Timeout))
+	if err != nil {
+		log.Printf("Фискализация %s: %v", receiptSubject(saleID, returnID), err)
+		return nil, nil, false
+	}
+	if !claimed {
+		// Чек уже зарегистрирован или его обрабатывает другой запрос
+		return nil, nil, false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.Policy.Timeout)
+	defer cancel()
+
+	document, err := s.Driver.Register(ctx, receipt)
+	if err != nil {
+		s.reschedule(entry, err)
+		return entry, nil, false
+	}
+	return entry, document, true
+}
+
+func (s *FiscalService) reschedule(entry *models.FiscalOutboxEntry, cause error) {
+	subject := receiptSubject(entry.SaleID, entry.ReturnID)
+	log.Printf("Фискализация %s, попытка %d: %v", subject, entry.Attempts+1, cause)
+
+	delay := s.Policy.RetryDelay << min(entry.Attempts, 16)
+	if delay > s.Policy.MaxRetryDelay || delay <= 0 {
+		delay = s.Policy.MaxRetryDelay
+	}
+	entry.Attempts++
+	entry.LastError = cause.Error()
+	entry.NextAttemptAt = time.Now().Add(delay)
+
+	if err := s.OutboxRepo.Reschedule(entry); err != nil {
+		log.Printf("Фискализация %s: не удалось отложить попытку: %v", subject, err)
+	}
+
+	var err error
+	if entry.ReturnID != nil {
+		err = s.ReturnRepo.UpdateFiscalStatus(*entry.ReturnID, models.FiscalFailed)
+	} else {
+		err = s.SaleRepo.UpdateFiscalStatus(entry.SaleID, models.FiscalFailed)
+	}
+	if err != nil {
+		log.Printf("Фискализация %s: %v", subject, err)
+	}
+}
+
+// receiptSubject называет чек в журнале: продажу или возврат.
+func receiptSubject(saleID uint, returnID *uint) string {
+	if returnID != nil {
+		return fmt.Sprintf("возврата %d", *returnID)
+	}
+	return fmt.Sprintf("продажи %d", saleID)
+}
+
+// Run обрабатывает очередь фискализации, пока не отменен ctx.
+func (s *FiscalService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.Policy.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		s.processDue()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *FiscalService) processDue() {
+	entries, err := s.OutboxRepo.FindDue(time.Now(), s.Policy.BatchSize)
+	if err != nil {
+		log.Printf("Очередь фискализации: %v", err)
+		return
+	}
+
+	for _, entry := range entries {
+		if entry.ReturnID != nil {
+			ret, err := s.ReturnRepo.FindByID(*entry.ReturnID)
+			if err != nil {
+				log.Printf("Фискализация %s: %v", receiptSubject(entry.SaleID, entry.ReturnID), err)
+				continue
+			}
+			s.FiscalizeReturn(ret)
+			continue
+		}
+
+		sale, err := s.SaleRepo.FindByID(entry.SaleID)
+		if err != nil {
+			log.Printf("Фискализация %s: %v", receiptSubject(entry.SaleID, nil), err)
+			continue
+		}
+		s.Fiscalize(sale)
+	}
+}
+
+// newFiscalReceipt собирает чек продажи. В sale должны быть загружены
+// товар, кассир и платежи.
+func newFiscalReceipt(sale *models.Sale) FiscalReceipt {
+	price := 0.0
+	if sale.Quantity != 0 {
+		price = sale.TotalPrice / float64(sale.Quantity)
+	}
+
+	receipt := FiscalReceipt{
+		Type:    ReceiptIncome,
+		SaleID:  sale.ID,
+		Date:    sale.SaleDate,
+		Cashier: sale.Cashier.Username,
+		Items: []FiscalItem{{
+			Name:     sale.Product.Name,
+			Quantity: float64(sale.Quantity),
+			Price:    price,
+			Sum:      sale.TotalPrice,
+		}},
+		Total: sale.TotalPrice,
+	}
+	for _, payment := range sale.Payments {
+		receipt.Payments = append(receipt.Payments, FiscalPayment{Method: payment.Method, Amount: payment.Amount})
+	}
+	return receipt
+}
+
+// newReturnReceipt собирает чек возврата прихода: возвращенное количество
+// по цене продажи на сумму возврата, выплаченную теми же способами, что и
+// в выплатах возврата.
+func newReturnReceipt(ret *models.SaleReturn) FiscalReceipt {
+	price := 0.0
+	if ret.Sale.Quantity != 0 {
+		price = ret.Sale.TotalPrice / float64(ret.Sale.Quantity)
+	}
+
+	receipt := FiscalReceipt{
+		Type:     ReceiptIncomeReturn,
+		SaleID:   ret.SaleID,
+		ReturnID: ret.ID,
+		Date:     ret.CreatedAt,
+		Cashier:  ret.Creator.Username,
+		Items: []FiscalItem{{
+			Name:     ret.Sale.Product.Name,
+			Quantity: float64(ret.Quantity),
+			Price:    price,
+			Sum:      ret.Amount,
+		}},
+		Total: ret.Amount,
+	}
+	for _, refund := range ret.Refunds {
+		receipt.Payments = append(receipt.Payments, FiscalPayment{Method: refund.Method, Amount: refund.Amount})
+	}
+	return receipt
+}
@@ -0,0 +1,269 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"grocery-store-api/models"
+	"grocery-store-api/repositories"
+	"grocery-store-api/services/errs"
+)
+
+// Методы прогноза спроса: скользящее среднее и экспоненциальное
+// сглаживание с недельной сезонностью.
+const (
+	ForecastMovingAverage = "moving_average"
+	ForecastSeasonal      = "seasonal"
+)
+
+// Параметры прогноза. Скользящее среднее берется за movingAverageDays
+// последних дней; для сезонной модели нужно не меньше двух недель истории,
+// для проверки на истории — еще backtestDays дней сверх них.
+const (
+	DefaultForecastDays = 14
+	maxForecastDays     = 90
+	DefaultHistoryDays  = 84
+	minHistoryDays      = 28
+	maxHistoryDays      = 730
+	movingAverageDays   = 28
+	backtestDays        = 14
+	seasonLength        = 7
+)
+
+var (
+	ErrInvalidForecastDays = errs.NewValidation("invalid_forecast_days", fmt.Sprintf("горизонт прогноза — от 1 до %d дней", maxForecastDays))
+	ErrInvalidHistoryDays  = errs.NewValidation("invalid_history_days", fmt.Sprintf("история для прогноза — от %d до %d дней", minHistoryDays, maxHistoryDays))
+)
+
+// ForecastDay — прогноз продаж товара на день по каждому методу. Пока
+// истории меньше двух недель, сезонный прогноз равен скользящему среднему.
+type ForecastDay struct {
+	Date          time.Time
+	MovingAverage float64
+	Seasonal      float64
+}
+
+// ForecastAccuracy — ошибки метода при проверке на истории: прогноз на
+// последние Days дней истории по данным до них. WAPE — сумма абсолютных
+// ошибок к сумме продаж, Bias — доля, на которую прогноз завышен (если
+// отрицательная — занижен); nil, если за эти дни продаж не было.
+type ForecastAccuracy struct {
+	Method string
+	Days   int
+	MAE    float64
+	RMSE   float64
+	WAPE   *float64
+	Bias   *float64
+}
+
+// DemandForecast — прогноз дневного спроса на товар. История —
+// [HistoryFrom, HistoryTo); если товар впервые продан внутри окна,
+// история начинается с дня первой продажи. Recommended — метод с меньшей
+// ошибкой на истории.
+type DemandForecast struct {
+	Product     models.Product
+	HistoryFrom time.Time
+	HistoryTo   time.Time
+	HistoryDays int
+	AvgDaily    float64
+	Alpha       float64
+	Gamma       float64
+	Days        []ForecastDay
+	Backtest    []ForecastAccuracy
+	Recommended string
+}
+
+// ForecastService прогнозирует спрос на товары по истории продаж.
+type ForecastService struct {
+	SaleRepo    repositories.SaleRepository
+	ProductRepo repositories.ProductRepository
+	Scope       DepartmentScope
+}
+
+// Forecast прогнозирует продажи товара на days дней начиная с сегодняшнего
+// по продажам за historyDays дней до него. Нулевые значения заменяются
+// значениями по умолчанию.
+func (s *ForecastService) Forecast(actor Actor, productID uint, days, historyDays int) (*DemandForecast, error) {
+	if days == 0 {
+		days = DefaultForecastDays
+	}
+	if days < 1 || days > maxForecastDays {
+		return nil, ErrInvalidForecastDays
+	}
+	if historyDays == 0 {
+		historyDays = DefaultHistoryDays
+	}
+	if historyDays < minHistoryDays || historyDays > maxHistoryDays {
+		return nil, ErrInvalidHistoryDays
+	}
+
+	product, err := s.ProductRepo.FindByID(productID)
+	if err != nil {
+		return nil, notFound(err, ErrProductNotFound)
+	}
+	if err := s.Scope.Check(actor, product.DepartmentID); err != nil {
+		return nil, err
+	}
+
+	today := startOfDay(time.Now())
+	from := today.AddDate(0, 0, -historyDays)
+	quantities, err := s.SaleRepo.DailyQuantities(productID, from, today)
+	if err != nil {
+		return nil, err
+	}
+	soldBefore, err := s.SaleRepo.HasSalesBefore(productID, from)
+	if err != nil {
+		return nil, err
+	}
+
+	// Дни до первой продажи нового товара — не отсутствие спроса, а
+	// отсутствие товара, поэтому в историю не входят
+	var series []float64
+	forecast := &DemandForecast{Product: *product, HistoryTo: today}
+	for day := from; day.Before(today); day = day.AddDate(0, 0, 1) {
+		quantity, ok := quantities[day.Format("2006-01-02")]
+		if series == nil && !ok && !soldBefore {
+			continue
+		}
+		if series == nil {
+			forecast.HistoryFrom = day
+		}
+		series = append(series, float64(quantity))
+	}
+	forecast.HistoryDays = len(series)
+	forecast.AvgDaily = mean(series)
+
+	average := movingAverage(series)
+	seasonal, fitted := fitSeasonal(series)
+	forecast.Recommended = ForecastMovingAverage
+	if fitted {
+		forecast.Alpha, forecast.Gamma = seasonal.alpha, seasonal.gamma
+		forecast.Recommended = ForecastSeasonal
+	}
+
+	for i := 0; i < days; i++ {
+		day := ForecastDay{Date: today.AddDate(0, 0, i), MovingAverage: average, Seasonal: average}
+		if fitted {
+			day.Seasonal = seasonal.forecast(len(series), i)
+		}
+		forecast.Days = append(forecast.Days, day)
+	}
+
+	if len(series) >= 2*seasonLength+backtestDays {
+		train, actual := series[:len(series)-backtestDays], series[len(series)-backtestDays:]
+		predicted := make([]float64, backtestDays)
+		for i := range predicted {
+			predicted[i] = movingAverage(train)
+		}
+		averageAccuracy := accuracy(ForecastMovingAverage, predicted, actual)
+
+		model, _ := fitSeasonal(train)
+		for i := range predicted {
+			predicted[i] = model.forecast(len(train), i)
+		}
+		seasonalAccuracy := accuracy(ForecastSeasonal, predicted, actual)
+
+		forecast.Backtest = []ForecastAccuracy{averageAccuracy, seasonalAccuracy}
+		if averageAccuracy.MAE < seasonalAccuracy.MAE {
+			forecast.Recommended = ForecastMovingAverage
+		}
+	}
+	return forecast, nil
+}
+
+// seasonalModel — экспоненциальное сглаживание с аддитивной недельной
+// сезонностью: уровень спроса и поправки на дни недели.
+type seasonalModel struct {
+	alpha, gamma float64
+	level        float64
+	season       [seasonLength]float64
+}
+
+// forecast возвращает прогноз на день h (с нуля) после n дней истории.
+func (m *seasonalModel) forecast(n, h int) float64 {
+	return math.Max(m.level+m.season[(n+h)%seasonLength], 0)
+}
+
+// fitSeasonal подбирает параметры сглаживания по наименьшей ошибке
+// прогноза на день вперед. Если истории меньше двух недель, модель не
+// строится.
+func fitSeasonal(series []float64) (seasonalModel, bool) {
+	if len(series) < 2*seasonLength {
+		return seasonalModel{}, false
+	}
+
+	var best seasonalModel
+	bestError := math.Inf(1)
+	for _, alpha := range []float64{0.1, 0.2, 0.3, 0.5, 0.7, 0.9} {
+		for _, gamma := range []float64{0.05, 0.1, 0.2, 0.3} {
+			model, err := smoothSeasonal(series, alpha, gamma)
+			if err < bestError {
+				best, bestError = model, err
+			}
+		}
+	}
+	return best, true
+}
+
+// smoothSeasonal проходит по истории моделью с параметрами alpha (уровень)
+// и gamma (сезонность) и возвращает ее вместе с суммой абсолютных ошибок
+// прогноза на день вперед. Начальные значения — средние первых двух
+// недель.
+func smoothSeasonal(series []float64, alpha, gamma float64) (seasonalModel, float64) {
+	model := seasonalModel{alpha: alpha, gamma: gamma, level: mean(series[:2*seasonLength])}
+	for i := range model.season {
+		model.season[i] = (series[i]+series[i+seasonLength])/2 - model.level
+	}
+
+	var sum float64
+	for t, actual := range series {
+		i := t % seasonLength
+		sum += math.Abs(actual - (model.level + model.season[i]))
+		level := alpha*(actual-model.season[i]) + (1-alpha)*model.level
+		model.season[i] = gamma*(actual-level) + (1-gamma)*model.season[i]
+		model.level = level
+	}
+	return model, sum
+}
+
+// movingAverage возвращает среднее за последние movingAverageDays дней.
+func movingAverage(series []float64) float64 {
+	if len(series) > movingAverageDays {
+		series = series[len(series)-movingAverageDays:]
+	}
+	return mean(series)
+}
+
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, value := range values {
+		sum += value
+	}
+	return sum / float64(len(values))
+}
+
+// accuracy сравнивает прогноз с фактическими продажами.
+func accuracy(method string, predicted, actual []float64) ForecastAccuracy {
+	result := ForecastAccuracy{Method: method, Days: len(actual)}
+	var absSum, squareSum, predictedSum, actualSum float64
+	for i := range actual {
+		diff := predicted[i] - actual[i]
+		absSum += math.Abs(diff)
+		squareSum += diff * diff
+		predictedSum += predicted[i]
+		actualSum += actual[i]
+	}
+
+	result.MAE = absSum / float64(len(actual))
+	result.RMSE = math.Sqrt(squareSum / float64(len(actual)))
+	if actualSum > 0 {
+		wape := absSum / actualSum
+		bias := (predictedSum - actualSum) / actualSum
+		result.WAPE, result.Bias = &wape, &bias
+	}
+	return result
+}
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"math"
+	"testing"
+)
+
+const epsilon = 1e-9
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < epsilon
+}
+
+// repeatWeeks повторяет недельный профиль спроса weeks раз.
+func repeatWeeks(week [seasonLength]float64, weeks int) []float64 {
+	series := make([]float64, 0, weeks*seasonLength)
+	for range weeks {
+		series = append(series, week[:]...)
+	}
+	return series
+}
+
+func TestFitSeasonal(t *testing.T) {
+	weekly := [seasonLength]float64{1, 2, 3, 4, 5, 10, 20}
+
+	tests := []struct {
+		name   string
+		series []float64
+		ok     bool
+		// want — ожидаемый прогноз на следующие дни после истории
+		want []float64
+	}{
+		{
+			name:   "меньше двух недель",
+			series: make([]float64, 2*seasonLength-1),
+			ok:     false,
+		},
+		{
+			name:   "ровный спрос",
+			series: repeatWeeks([seasonLength]float64{5, 5, 5, 5, 5, 5, 5}, 3),
+			ok:     true,
+			want:   []float64{5, 5, 5, 5, 5, 5, 5},
+		},
+		{
+			name:   "точная недельная сезонность",
+			series: repeatWeeks(weekly, 4),
+			ok:     true,
+			want:   weekly[:],
+		},
+		{
+			name:   "история не с начала недели",
+			series: repeatWeeks(weekly, 4)[3:24],
+			ok:     true,
+			want:   []float64{4, 5, 10, 20, 1, 2, 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model, ok := fitSeasonal(tt.series)
+			if ok != tt.ok {
+				t.Fatalf("ok = %v, want %v", ok, tt.ok)
+			}
+			for h, want := range tt.want {
+				if got := model.forecast(len(tt.series), h); !almostEqual(got, want) {
+					t.Errorf("forecast(%d) = %v, want %v", h, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestSeasonalForecastNotNegative(t *testing.T) {
+	model := seasonalModel{level: 1, season: [seasonLength]float64{-3, 2}}
+
+	if got := model.forecast(0, 0); got != 0 {
+		t.Errorf("forecast(0, 0) = %v, want 0", got)
+	}
+	if got := model.forecast(0, 1); got != 3 {
+		t.Errorf("forecast(0, 1) = %v, want 3", got)
+	}
+}
+
+func TestAccuracy(t *testing.T) {
+	tests := []struct {
+		name              string
+		predicted, actual []float64
+		mae, rmse         float64
+		// wape и bias равны nil, если фактических продаж не было
+		wape, bias *float64
+	}{
+		{
+			name:      "точный прогноз",
+			predicted: []float64{3, 0, 5},
+			actual:    []float64{3, 0, 5},
+			wape:      ptr(0.0),
+			bias:      ptr(0.0),
+		},
+		{
+			name:      "ошибки в обе стороны",
+			predicted: []float64{2, 2},
+			actual:    []float64{1, 3},
+			mae:       1,
+			rmse:      1,
+			wape:      ptr(0.5),
+			bias:      ptr(0.0),
+		},
+		{
+			name:      "завышенный прогноз",
+			predicted: []float64{4, 4, 4, 4},
+			actual:    []float64{2, 2, 2, 2},
+			mae:       2,
+			rmse:      2,
+			wape:      ptr(1.0),
+			bias:      ptr(1.0),
+		},
+		{
+			name:      "заниженный прогноз с разными ошибками",
+			predicted: []float64{0, 3},
+			actual:    []float64{4, 4},
+			mae:       2.5,
+			rmse:      math.Sqrt(8.5),
+			wape:      ptr(5.0 / 8),
+			bias:      ptr(-5.0 / 8),
+		},
+		{
+			name:      "нет фактических продаж",
+			predicted: []float64{1, 0},
+			actual:    []float64{0, 0},
+			mae:       0.5,
+			rmse:      math.Sqrt(0.5),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := accuracy(ForecastSeasonal, tt.predicted, tt.actual)
+
+			if got.Method != ForecastSeasonal || got.Days != len(tt.actual) {
+				t.Errorf("method, days = %q, %d, want %q, %d", got.Method, got.Days, ForecastSeasonal, len(tt.actual))
+			}
+			if !almostEqual(got.MAE, tt.mae) {
+				t.Errorf("MAE = %v, want %v", got.MAE, tt.mae)
+			}
+			if !almostEqual(got.RMSE, tt.rmse) {
+				t.Errorf("RMSE = %v, want %v", got.RMSE, tt.rmse)
+			}
+			checkRatio(t, "WAPE", got.WAPE, tt.wape)
+			checkRatio(t, "Bias", got.Bias, tt.bias)
+		})
+	}
+}
+
+func checkRatio(t *testing.T, name string, got, want *float64) {
+	t.Helper()
+	switch {
+	case got == nil && want == nil:
+	case got == nil || want == nil:
+		t.Errorf("%s = %v, want %v", name, got, want)
+	case !almostEqual(*got, *want):
+		t.Errorf("%s = %v, want %v", name, *got, *want)
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,223 @@
+package services
+
+import (
+	"fmt"
+	"grocery-store-api/models"
+	"grocery-store-api/repositories"
+	"grocery-store-api/services/errs"
+	"strings"
+)
+
+// MaxImportRows ограничивает размер одного импорта.
+const MaxImportRows = 5000
+
+var errTooManyImportRows = errs.NewValidation("import_too_large", fmt.Sprintf("за один раз можно импортировать не больше %d строк", MaxImportRows))
+
+// ImportReport — результат проверки или выполнения импорта. Ошибки
+// относятся к строкам файла: Field имеет вид rows[<номер строки>].<колонка>.
+type ImportReport struct {
+	DryRun    bool              `json:"dry_run"`
+	TotalRows int               `json:"total_rows"`
+	ValidRows int               `json:"valid_rows"`
+	Created   int               `json:"created"`
+	Errors    []errs.FieldError `json:"errors"`
+}
+
+// ProductImportRow — строка файла импорта товаров. Отдел и поставщик
+// указываются названиями и сопоставляются с активными записями. Errors
+// содержит ошибки, найденные при разборе строки.
+type ProductImportRow struct {
+	Line       int
+	Product    models.Product
+	Department string
+	Supplier   string
+	Errors     []errs.FieldError
+}
+
+type SupplierImportRow struct {
+	Line     int
+	Supplier models.Supplier
+	Errors   []errs.FieldError
+}
+
+// RowError формирует ошибку поля для строки файла импорта.
+func RowError(line int, field, rule, message string) errs.FieldError {
+	return errs.FieldError{Field: fmt.Sprintf("rows[%d].%s", line, field), Rule: rule, Message: message}
+}
+
+// ImportService выполняет массовый импорт по принципу «все или ничего»:
+// если хотя бы одна строка содержит ошибку, ничего не сохраняется.
+type ImportService struct {
+	ProductRepo    repositories.ProductRepository
+	DepartmentRepo repositories.DepartmentRepository
+	SupplierRepo   repositories.SupplierRepository
+	Scope          DepartmentScope
+	Audit          AuditService
+}
+
+func (s *ImportService) ImportProducts(actor Actor, rows []ProductImportRow, dryRun bool) (*ImportReport, error) {
+	if len(rows) > MaxImportRows {
+		return nil, errTooManyImportRows
+	}
+
+	departments, err := s.DepartmentRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	departmentIDs := make(map[string][]uint)
+	for _, department := range departments {
+		key := importKey(department.Name)
+		departmentIDs[key] = append(departmentIDs[key], department.ID)
+	}
+
+	suppliers, err := s.SupplierRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	supplierIDs := make(map[string][]uint)
+	for _, supplier := range suppliers {
+		key := importKey(supplier.Name)
+		supplierIDs[key] = append(supplierIDs[key], supplier.ID)
+	}
+
+	report := &ImportReport{DryRun: dryRun, TotalRows: len(rows), Errors: []errs.FieldError{}}
+	products := make([]models.Product, 0, len(rows))
+	for _, row := range rows {
+		rowErrors := row.Errors
+
+		product := row.Product
+		if row.Department != "" {
+			id, fieldErr := resolveByName(row.Line, "department", row.Department, departmentIDs)
+			if fieldErr != nil {
+				rowErrors = append(rowErrors, *fieldErr)
+			} else if err := s.Scope.Check(actor, id); err != nil {
+				if _, ok := errs.As(err); !ok {
+					return nil, err
+				}
+				rowErrors = append(rowErrors, RowError(row.Line, "department", "scope", ErrOutOfScope.Message))
+			} else {
+				product.DepartmentID = id
+			}
+		}
+		if row.Supplier != "" {
+			id, fieldErr := resolveByName(row.Line, "supplier", row.Supplier, supplierIDs)
+			if fieldErr != nil {
+				rowErrors = append(rowErrors, *fieldErr)
+			} else {
+				product.SupplierID = id
+			}
+		}
+
+		if len(rowErrors) > 0 {
+			report.Errors = append(report.Errors, rowErrors...)
+			continue
+		}
+
+		product.Version = 1
+		products = append(products, product)
+	}
+	report.ValidRows = len(products)
+
+	if err := importFailed(report); err != nil || dryRun {
+		return report, err
+	}
+
+	if err := s.ProductRepo.CreateAll(products); err != nil {
+		return nil, err
+	}
+	for i := range products {
+		s.Audit.Record(actor, models.AuditCreate, AuditEntityProduct, products[i].ID, nil, &products[i])
+	}
+
+	report.Created = len(products)
+	return report, nil
+}
+
+// ImportSuppliers создает поставщиков. Названия должны быть уникальны среди
+// активных поставщиков и внутри файла, так как по ним затем сопоставляются
+// товары.
+func (s *ImportService) ImportSuppliers(actor Actor, rows []SupplierImportRow, dryRun bool) (*ImportReport, error) {
+	if len(rows) > MaxImportRows {
+		return nil, errTooManyImportRows
+	}
+
+	existing, err := s.SupplierRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	taken := make(map[string]int)
+	for _, supplier := range existing {
+		taken[importKey(supplier.Name)] = 0
+	}
+
+	report := &ImportReport{DryRun: dryRun, TotalRows: len(rows), Errors: []errs.FieldError{}}
+	suppliers := make([]models.Supplier, 0, len(rows))
+	for _, row := range rows {
+		rowErrors := row.Errors
+
+		key := importKey(row.Supplier.Name)
+		if line, ok := taken[key]; ok && key != "" {
+			message := "поставщик с таким названием уже существует"
+			if line > 0 {
+				message = fmt.Sprintf("название повторяет строку %d", line)
+			}
+			rowErrors = append(rowErrors, RowError(row.Line, "name", "unique", message))
+		} else if key != "" {
+			taken[key] = row.Line
+		}
+
+		if len(rowErrors) > 0 {
+			report.Errors = append(report.Errors, rowErrors...)
+			continue
+		}
+
+		supplier := row.Supplier
+		supplier.Version = 1
+		suppliers = append(suppliers, supplier)
+	}
+	report.ValidRows = len(suppliers)
+
+	if err := importFailed(report); err != nil || dryRun {
+		return report, err
+	}
+
+	if err := s.SupplierRepo.CreateAll(suppliers); err != nil {
+		return nil, err
+	}
+	for i := range suppliers {
+		s.Audit.Record(actor, models.AuditCreate, AuditEntitySupplier, suppliers[i].ID, nil, &suppliers[i])
+	}
+
+	report.Created = len(suppliers)
+	return report, nil
+}
+
+// importFailed возвращает ошибку отмены импорта, если в отчете есть ошибки.
+// При пробном запуске ошибки остаются только в отчете.
+func importFailed(report *ImportReport) error {
+	if len(report.Errors) == 0 || report.DryRun {
+		return nil
+	}
+	invalid := report.TotalRows - report.ValidRows
+	return errs.NewFieldValidation("import_failed", fmt.Sprintf("импорт отменен: ошибки в строках — %d", invalid), report.Errors)
+}
+
+func resolveByName(line int, field, name string, ids map[string][]uint) (uint, *errs.FieldError) {
+	matches := ids[importKey(name)]
+	switch len(matches) {
+	case 1:
+		return matches[0], nil
+	case 0:
+		fieldErr := RowError(line, field, "exists", fmt.Sprintf("«%s» не найден среди активных записей", name))
+		return 0, &fieldErr
+	default:
+		fieldErr := RowError(line, field, "ambiguous", fmt.Sprintf("найдено несколько записей с названием «%s»", name))
+		return 0, &fieldErr
+	}
+}
+
+// importKey нормализует название для сопоставления без учета регистра и
+// лишних пробелов.
+func importKey(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
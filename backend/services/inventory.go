@@ -0,0 +1,454 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"grocery-store-api/models"
+	"grocery-store-api/repositories"
+	"grocery-store-api/services/errs"
+)
+
+// Группировки оценки запасов.
+const (
+	ValuationByDepartment = "department"
+	ValuationBySupplier   = "supplier"
+)
+
+// DefaultDeadStockDays — сколько дней без продаж по умолчанию делают
+// остаток неликвидным.
+const (
+	DefaultDeadStockDays = 30
+	maxDeadStockDays     = 365
+)
+
+var (
+	ErrInvalidValuationGrouping = errs.NewValidation("invalid_group_by", "группировка оценки запасов — department или supplier")
+	ErrInvalidDeadStockDays     = errs.NewValidation("invalid_days", fmt.Sprintf("срок без продаж — от 1 до %d дней", maxDeadStockDays))
+)
+
+// StockValue — стоимость остатка по себестоимости и в розничных ценах.
+// Uncosted — количество, для которого не нашлось ни одной поставки: в
+// CostValue оно не входит.
+type StockValue struct {
+	Products    int
+	Quantity    int64
+	Uncosted    int64
+	CostValue   float64
+	RetailValue float64
+}
+
+// Margin возвращает ожидаемую наценку на остаток.
+func (v *StockValue) Margin() float64 {
+	return roundMoney(v.RetailValue - v.CostValue)
+}
+
+func (v *StockValue) add(quantity, uncosted int64, cost, retail float64) {
+	v.Products++
+	v.Quantity += quantity
+	v.Uncosted += uncosted
+	v.CostValue += cost
+	v.RetailValue += retail
+}
+
+func (v *StockValue) round() {
+	v.CostValue = roundMoney(v.CostValue)
+	v.RetailValue = roundMoney(v.RetailValue)
+}
+
+// ValuationGroup — стоимость остатков отдела или поставщика.
+type ValuationGroup struct {
+	ID   uint
+	Name string
+	StockValue
+}
+
+// StockValuation — оценка остатков на конец дня Date. Groups идут по
+// убыванию себестоимости.
+type StockValuation struct {
+	Date    time.Time
+	GroupBy string
+	Groups  []ValuationGroup
+	Total   StockValue
+}
+
+// TurnoverFigures — оборачиваемость запаса за период в единицах товара:
+// Turnover — продажи к среднему остатку, DaysOnHand — на сколько дней
+// продаж хватает среднего остатка. Оба nil, если их нельзя посчитать:
+// продаж не было или средний остаток нулевой.
+type TurnoverFigures struct {
+	Opening    int64
+	Closing    int64
+	Average    float64
+	Sold       int64
+	Turnover   *float64
+	DaysOnHand *float64
+}
+
+func (f *TurnoverFigures) compute(days int) {
+	f.Average = float64(f.Opening+f.Closing) / 2
+	if f.Sold == 0 || f.Average == 0 {
+		return
+	}
+	turnover := float64(f.Sold) / f.Average
+	onHand := float64(days) / turnover
+	f.Turnover, f.DaysOnHand = &turnover, &onHand
+}
+
+// ProductTurnover — оборачиваемость запаса товара.
+type ProductTurnover struct {
+	Product models.Product
+	TurnoverFigures
+}
+
+// InventoryTurnover — оборачиваемость запасов за период [From, To) из
+// Days дней. Products идут от самых медленных: сначала остатки без
+// продаж, затем по убыванию дней запаса.
+type InventoryTurnover struct {
+	From, To time.Time
+	Days     int
+	Products []ProductTurnover
+	Total    TurnoverFigures
+}
+
+// DeadStockItem — остаток товара без продаж. LastSale и DaysWithoutSales
+// равны nil, если товар не продавался никогда.
+type DeadStockItem struct {
+	Product          models.Product
+	Quantity         int64
+	LastSale         *time.Time
+	DaysWithoutSales *int
+	CostValue        float64
+	RetailValue      float64
+}
+
+// DeadStock — товары с остатком, не продававшиеся с Since (Days дней).
+// Items идут по убыванию себестоимости остатка.
+type DeadStock struct {
+	Days  int
+	Since time.Time
+	Items []DeadStockItem
+	Total StockValue
+}
+
+// InventoryService оценивает товарные запасы. Движения запаса учитываются
+// по продажам, поставкам и утвержденным актам списания: остаток на прошлую
+// дату восстанавливается от текущего, ручные правки остатка в карточке
+// товара не учитываются. Себестоимость считается по FIFO — остаток состоит
+// из последних поставок; розничная стоимость — по текущей цене товара.
+type InventoryService struct {
+	ProductRepo    repositories.ProductRepository
+	SaleRepo       repositories.SaleRepository
+	ReturnRepo     repositories.SaleReturnRepository
+	SupplyItemRepo repositories.SupplyItemRepository
+	WriteOffRepo   repositories.WriteOffRepository
+	Scope          DepartmentScope
+}
+
+// GetValuation оценивает остатки товаров отделов, доступных пользователю,
+// на конец дня day с группировкой по отделам или поставщикам.
+func (s *InventoryService) GetValuation(actor Actor, day time.Time, groupBy string) (*StockValuation, error) {
+	if groupBy == "" {
+		groupBy = ValuationByDepartment
+	}
+	if groupBy != ValuationByDepartment && groupBy != ValuationBySupplier {
+		return nil, ErrInvalidValuationGrouping
+	}
+
+	products, err := s.products(actor)
+	if err != nil {
+		return nil, err
+	}
+
+	day = startOfDay(day)
+	at := day.AddDate(0, 0, 1)
+	stock, err := s.stockAt(products, at)
+	if err != nil {
+		return nil, err
+	}
+	costs, err := stockCosts(s.SupplyItemRepo, products, stock, at)
+	if err != nil {
+		return nil, err
+	}
+
+	valuation := &StockValuation{Date: day, GroupBy: groupBy}
+	groups := make(map[uint]*ValuationGroup)
+	var order []uint
+	for _, product := range products {
+		quantity := stock[product.ID]
+		if quantity == 0 {
+			continue
+		}
+
+		id, name := product.DepartmentID, product.Department.Name
+		if groupBy == ValuationBySupplier {
+			id, name = product.SupplierID, product.Supplier.Name
+		}
+		group, ok := groups[id]
+		if !ok {
+			group = &ValuationGroup{ID: id, Name: name}
+			groups[id] = group
+			order = append(order, id)
+		}
+
+		cost := costs[product.ID]
+		retail := float64(quantity) * product.Price
+		group.add(quantity, cost.uncosted, cost.value, retail)
+		valuation.Total.add(quantity, cost.uncosted, cost.value, retail)
+	}
+
+	for _, id := range order {
+		group := groups[id]
+		group.round()
+		valuation.Groups = append(valuation.Groups, *group)
+	}
+	valuation.Total.round()
+	sort.SliceStable(valuation.Groups, func(i, j int) bool {
+		return valuation.Groups[i].CostValue > valuation.Groups[j].CostValue
+	})
+	return valuation, nil
+}
+
+// GetTurnover считает оборачиваемость запасов товаров отделов, доступных
+// пользователю, за период [from, to). Товары без остатка и продаж за
+// период в отчет не входят.
+func (s *InventoryService) GetTurnover(actor Actor, from, to time.Time) (*InventoryTurnover, error) {
+	products, err := s.products(actor)
+	if err != nil {
+		return nil, err
+	}
+
+	opening, err := s.stockAt(products, from)
+	if err != nil {
+		return nil, err
+	}
+	closing, err := s.stockAt(products, to)
+	if err != nil {
+		return nil, err
+	}
+	soldFrom, err := s.SaleRepo.SoldSince(from)
+	if err != nil {
+		return nil, err
+	}
+	soldTo, err := s.SaleRepo.SoldSince(to)
+	if err != nil {
+		return nil, err
+	}
+
+	days := int(math.Round(to.Sub(from).Hours() / 24))
+	turnover := &InventoryTurnover{From: from, To: to, Days: days}
+	for _, product := range products {
+		figures := TurnoverFigures{
+			Opening: opening[product.ID],
+			Closing: closing[product.ID],
+			Sold:    soldFrom[product.ID] - soldTo[product.ID],
+		}
+		if figures.Opening == 0 && figures.Closing == 0 && figures.Sold == 0 {
+			continue
+		}
+		figures.compute(days)
+		turnover.Products = append(turnover.Products, ProductTurnover{Product: product, TurnoverFigures: figures})
+
+		turnover.Total.Opening += figures.Opening
+		turnover.Total.Closing += figures.Closing
+		turnover.Total.Sold += figures.Sold
+	}
+	turnover.Total.compute(days)
+
+	// Остаток без продаж оборачивается медленнее всех, продажи при нулевом
+	// среднем остатке — быстрее всех
+	slowness := func(f *TurnoverFigures) float64 {
+		switch {
+		case f.DaysOnHand != nil:
+			return *f.DaysOnHand
+		case f.Sold == 0:
+			return math.Inf(1)
+		default:
+			return -1
+		}
+	}
+	sort.SliceStable(turnover.Products, func(i, j int) bool {
+		a, b := slowness(&turnover.Products[i].TurnoverFigures), slowness(&turnover.Products[j].TurnoverFigures)
+		if a != b {
+			return a > b
+		}
+		return turnover.Products[i].Product.ID < turnover.Products[j].Product.ID
+	})
+	return turnover, nil
+}
+
+// GetDeadStock возвращает товары отделов, доступных пользователю, которые
+// есть в остатке, но не продавались days дней (0 — DefaultDeadStockDays).
+func (s *InventoryService) GetDeadStock(actor Actor, days int) (*DeadStock, error) {
+	if days == 0 {
+		days = DefaultDeadStockDays
+	}
+	if days < 1 || days > maxDeadStockDays {
+		return nil, ErrInvalidDeadStockDays
+	}
+
+	products, err := s.products(actor)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	today := startOfDay(now)
+	since := today.AddDate(0, 0, -days)
+	sold, err := s.SaleRepo.SoldSince(since)
+	if err != nil {
+		return nil, err
+	}
+
+	var dead []models.Product
+	var ids []uint
+	stock := make(map[uint]int64)
+	for _, product := range products {
+		if product.CurrentQty <= 0 || sold[product.ID] > 0 {
+			continue
+		}
+		dead = append(dead, product)
+		ids = append(ids, product.ID)
+		stock[product.ID] = int64(product.CurrentQty)
+	}
+
+	report := &DeadStock{Days: days, Since: since}
+	if len(dead) == 0 {
+		return report, nil
+	}
+
+	lastSales, err := s.SaleRepo.LastSaleDates(ids)
+	if err != nil {
+		return nil, err
+	}
+	costs, err := stockCosts(s.SupplyItemRepo, dead, stock, now)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, product := range dead {
+		quantity := stock[product.ID]
+		cost := costs[product.ID]
+		item := DeadStockItem{
+			Product:     product,
+			Quantity:    quantity,
+			CostValue:   roundMoney(cost.value),
+			RetailValue: roundMoney(float64(quantity) * product.Price),
+		}
+		if last, ok := lastSales[product.ID]; ok {
+			daysWithout := DaysOverdue(last, today)
+			item.LastSale, item.DaysWithoutSales = &last, &daysWithout
+		}
+		report.Items = append(report.Items, item)
+		report.Total.add(quantity, cost.uncosted, item.CostValue, item.RetailValue)
+	}
+	report.Total.round()
+
+	sort.SliceStable(report.Items, func(i, j int) bool {
+		return report.Items[i].CostValue > report.Items[j].CostValue
+	})
+	return report, nil
+}
+
+// products возвращает активные товары отделов, доступных пользователю.
+func (s *InventoryService) products(actor Actor) ([]models.Product, error) {
+	ids, all, err := s.Scope.Departments(actor)
+	if err != nil {
+		return nil, err
+	}
+	if all {
+		return s.ProductRepo.FindAll()
+	}
+	return s.ProductRepo.FindByDepartments(ids)
+}
+
+// stockAt восстанавливает остатки товаров на момент t: к текущему остатку
+// возвращается проданное и списанное с t и вычитается поступившее и
+// возвращенное покупателями с t.
+func (s *InventoryService) stockAt(products []models.Product, t time.Time) (map[uint]int64, error) {
+	received, err := s.SupplyItemRepo.ReceivedSince(t)
+	if err != nil {
+		return nil, err
+	}
+	sold, err := s.SaleRepo.SoldSince(t)
+	if err != nil {
+		return nil, err
+	}
+	returned, err := s.ReturnRepo.ReturnedSince(t)
+	if err != nil {
+		return nil, err
+	}
+	writtenOff, err := s.WriteOffRepo.WrittenOffSince(t)
+	if err != nil {
+		return nil, err
+	}
+
+	stock := make(map[uint]int64, len(products))
+	for _, product := range products {
+		stock[product.ID] = max(int64(product.CurrentQty)-received[product.ID]+sold[product.ID]-returned[product.ID]+writtenOff[product.ID], 0)
+	}
+	return stock, nil
+}
+
+// stockCost — себестоимость остатка товара.
+type stockCost struct {
+	value    float64
+	uncosted int64
+}
+
+// stockCosts оценивает остатки stock по себестоимости поставок, принятых
+// до t. Остаток покрывается поставками от последней к первым; если поставок
+// не хватает, остаток сверх них оценивается по цене самой ранней.
+func stockCosts(repo repositories.SupplyItemRepository, products []models.Product, stock map[uint]int64, t time.Time) (map[uint]stockCost, error) {
+	var ids []uint
+	for _, product := range products {
+		if stock[product.ID] > 0 {
+			ids = append(ids, product.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return map[uint]stockCost{}, nil
+	}
+
+	items, err := repo.FindReceivedBefore(t, ids)
+	if err != nil {
+		return nil, err
+	}
+	return costLayers(ids, stock, items), nil
+}
+
+// costLayers оценивает остатки stock товаров ids по позициям поставок
+// items, идущим по каждому товару от последней к первым.
+func costLayers(ids []uint, stock map[uint]int64, items []models.SupplyItem) map[uint]stockCost {
+	layers := make(map[uint][]models.SupplyItem, len(ids))
+	for _, item := range items {
+		layers[item.ProductID] = append(layers[item.ProductID], item)
+	}
+
+	result := make(map[uint]stockCost, len(ids))
+	for _, id := range ids {
+		remaining := stock[id]
+		var cost stockCost
+		var lastPrice float64
+		for _, item := range layers[id] {
+			if remaining == 0 {
+				break
+			}
+			quantity := min(remaining, int64(item.Quantity))
+			cost.value += float64(quantity) * item.UnitPrice
+			remaining -= quantity
+			lastPrice = item.UnitPrice
+		}
+		if remaining > 0 {
+			if len(layers[id]) == 0 {
+				cost.uncosted = remaining
+			} else {
+				cost.value += float64(remaining) * lastPrice
+			}
+		}
+		result[id] = cost
+	}
+	return result
+}
@@ -0,0 +1,170 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"grocery-store-api/models"
+	"grocery-store-api/repositories"
+)
+
+func TestCostLayers(t *testing.T) {
+	tests := []struct {
+		name  string
+		stock int64
+		// layers — поставки товара от последней к первой
+		layers []models.SupplyItem
+		want   stockCost
+	}{
+		{
+			name:   "остаток в пределах последней поставки",
+			stock:  3,
+			layers: []models.SupplyItem{{Quantity: 5, UnitPrice: 2}},
+			want:   stockCost{value: 6},
+		},
+		{
+			name:   "остаток ровно по последней поставке",
+			stock:  5,
+			layers: []models.SupplyItem{{Quantity: 5, UnitPrice: 2}, {Quantity: 5, UnitPrice: 100}},
+			want:   stockCost{value: 10},
+		},
+		{
+			name:   "остаток покрывает несколько поставок",
+			stock:  7,
+			layers: []models.SupplyItem{{Quantity: 5, UnitPrice: 3}, {Quantity: 4, UnitPrice: 2}},
+			want:   stockCost{value: 19},
+		},
+		{
+			name:   "остаток сверх поставок по цене самой ранней",
+			stock:  10,
+			layers: []models.SupplyItem{{Quantity: 2, UnitPrice: 3}, {Quantity: 3, UnitPrice: 2}},
+			want:   stockCost{value: 22},
+		},
+		{
+			name:  "нет поставок",
+			stock: 4,
+			want:  stockCost{uncosted: 4},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			const id = 1
+			var items []models.SupplyItem
+			for _, layer := range tt.layers {
+				layer.ProductID = id
+				items = append(items, layer)
+			}
+			// Поставки другого товара не влияют на оценку
+			items = append(items, models.SupplyItem{ProductID: id + 1, Quantity: 100, UnitPrice: 1000})
+
+			got := costLayers([]uint{id}, map[uint]int64{id: tt.stock}, items)[id]
+			if !almostEqual(got.value, tt.want.value) || got.uncosted != tt.want.uncosted {
+				t.Errorf("cost = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStockCostsWithoutStock(t *testing.T) {
+	products := []models.Product{{ID: 1}, {ID: 2}}
+	stock := map[uint]int64{1: 0}
+
+	// Без остатков поставки не запрашиваются
+	costs, err := stockCosts(repositories.SupplyItemRepository{}, products, stock, time.Now())
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+	if len(costs) != 0 {
+		t.Errorf("costs = %+v, want empty", costs)
+	}
+}
+
+// newTestDB открывает пустую базу в памяти со схемой таблиц tables.
+func newTestDB(t *testing.T, tables ...interface{}) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Каждое соединение получило бы свою базу в памяти
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	if err := db.AutoMigrate(tables...); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestStockAtAfterReturns(t *testing.T) {
+	db := newTestDB(t, &models.Product{}, &models.Supply{}, &models.SupplyItem{},
+		&models.Sale{}, &models.SaleReturn{}, &models.WriteOff{}, &models.WriteOffItem{})
+
+	now := time.Now().UTC().Truncate(time.Second)
+	day := func(n int) time.Time { return now.AddDate(0, 0, n) }
+
+	// Поступило 10, продано 4 и 3, по продажам вернули 1 и 2: на складе 6
+	product := models.Product{ID: 1, Name: "Milk", CurrentQty: 6}
+	rows := []interface{}{
+		&product,
+		&models.Supply{ID: 1, SupplyDate: day(-10)},
+		&models.SupplyItem{SupplyID: 1, ProductID: 1, Quantity: 10},
+		&models.Sale{ID: 1, ProductID: 1, Quantity: 4, ReturnedQuantity: 1, SaleDate: day(-5)},
+		&models.Sale{ID: 2, ProductID: 1, Quantity: 3, ReturnedQuantity: 2, SaleDate: day(-3)},
+		&models.SaleReturn{SaleID: 1, Quantity: 1, CreatedAt: day(-1)},
+		&models.SaleReturn{SaleID: 2, Quantity: 2, CreatedAt: day(-1)},
+	}
+	for _, row := range rows {
+		if err := db.Create(row).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	service := InventoryService{
+		SaleRepo:       repositories.SaleRepository{DB: db},
+		ReturnRepo:     repositories.SaleReturnRepository{DB: db},
+		SupplyItemRepo: repositories.SupplyItemRepository{DB: db},
+		WriteOffRepo:   repositories.WriteOffRepository{DB: db},
+	}
+
+	tests := []struct {
+		name string
+		at   time.Time
+		want int64
+	}{
+		{name: "до поставки", at: day(-11), want: 0},
+		{name: "до продаж", at: day(-6), want: 10},
+		{name: "между продажами", at: day(-4), want: 6},
+		{name: "после продаж, до возвратов", at: day(-2), want: 3},
+		{name: "после возвратов", at: now, want: 6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stock, err := service.stockAt([]models.Product{product}, tt.at)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stock[product.ID] != tt.want {
+				t.Errorf("stock = %d, want %d", stock[product.ID], tt.want)
+			}
+		})
+	}
+
+	// Продажи считаются за вычетом возвращенного
+	sold, err := service.SaleRepo.SoldSince(day(-6))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sold[product.ID] != 4 {
+		t.Errorf("sold = %d, want 4", sold[product.ID])
+	}
+}
@@ -0,0 +1,329 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	"grocery-store-api/models"
+	"grocery-store-api/repositories"
+	"grocery-store-api/services/errs"
+)
+
+var (
+	ErrInvoiceNotFound      = errs.NewNotFound("invoice_not_found", "счет поставщика не найден")
+	ErrInvoiceExists        = errs.NewConflict("invoice_exists", "по поставке уже зарегистрирован счет или у поставщика уже есть счет с таким номером")
+	ErrInvalidInvoiceAmount = errs.NewValidation("invalid_invoice_amount", "сумма счета должна быть больше нуля")
+	ErrInvalidDueDate       = errs.NewValidation("invalid_due_date", "срок оплаты не может быть раньше даты счета")
+	ErrInvalidInvoiceStatus = errs.NewValidation("invalid_invoice_status", "недопустимый статус счета")
+	ErrInvoicePaid          = errs.NewConflict("invoice_paid", "счет уже оплачен полностью")
+	ErrOverpayment          = errs.NewValidation("overpayment", "оплата превышает остаток по счету")
+	ErrInvalidPaymentAmount = errs.NewValidation("invalid_payment_amount", "сумма оплаты должна быть больше нуля")
+	ErrNoOverdueAlert       = errs.NewConflict("no_overdue_alert", "по счету нет неподтвержденного оповещения о просрочке")
+)
+
+// DefaultOverdueCheckInterval — как часто проверяются просроченные счета.
+const DefaultOverdueCheckInterval = time.Hour
+
+// PayablesService ведет расчеты с поставщиками: счета за поставки, их
+// оплату и просроченную задолженность.
+type PayablesService struct {
+	Repo          repositories.SupplierInvoiceRepository
+	SupplyRepo    repositories.SupplyRepository
+	Audit         AuditService
+	CheckInterval time.Duration
+}
+
+// RegisterInvoice регистрирует счет поставщика за поставку. Без суммы
+// берется стоимость поставки, без даты — сегодняшний день, без срока
+// оплаты — дата счета плюс отсрочка поставщика.
+func (s *PayablesService) RegisterInvoice(actor Actor, invoice *models.SupplierInvoice) error {
+	supply, err := s.SupplyRepo.FindByID(invoice.SupplyID)
+	if err != nil {
+		return notFound(err, ErrSupplyNotFound)
+	}
+
+	invoice.SupplierID = supply.SupplierID
+	if invoice.Amount == 0 {
+		invoice.Amount = supply.TotalCost
+	}
+	invoice.Amount = roundMoney(invoice.Amount)
+	if invoice.Amount <= 0 {
+		return ErrInvalidInvoiceAmount
+	}
+
+	if invoice.InvoiceDate.IsZero() {
+		invoice.InvoiceDate = time.Now()
+	}
+	invoice.InvoiceDate = startOfDay(invoice.InvoiceDate)
+	if invoice.DueDate.IsZero() {
+		invoice.DueDate = invoice.InvoiceDate.AddDate(0, 0, supply.Supplier.PaymentTermDays)
+	}
+	invoice.DueDate = startOfDay(invoice.DueDate)
+	if invoice.DueDate.Before(invoice.InvoiceDate) {
+		return ErrInvalidDueDate
+	}
+
+	invoice.PaidAmount = 0
+	invoice.Status = models.InvoiceOpen
+	invoice.CreatedBy = actor.UserID
+
+	if err := s.Repo.Create(invoice); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return ErrInvoiceExists
+		}
+		return err
+	}
+
+	created, err := s.Repo.FindByID(invoice.ID)
+	if err != nil {
+		return err
+	}
+
+	s.Audit.Record(actor, models.AuditCreate, AuditEntitySupplierInvoice, invoice.ID, nil, created)
+	*invoice = *created
+	return nil
+}
+
+func (s *PayablesService) GetInvoice(id uint) (*models.SupplierInvoice, error) {
+	invoice, err := s.Repo.FindByID(id)
+	return invoice, notFound(err, ErrInvoiceNotFound)
+}
+
+func (s *PayablesService) GetInvoices(filter models.InvoiceFilter) ([]models.SupplierInvoice, error) {
+	if filter.Status != "" && !models.IsValidInvoiceStatus(filter.Status) {
+		return nil, ErrInvalidInvoiceStatus
+	}
+	if filter.Limit <= 0 || filter.Limit > 1000 {
+		filter.Limit = 100
+	}
+	return s.Repo.Find(filter, startOfDay(time.Now()))
+}
+
+// PayInvoice записывает оплату счета, в том числе частичную. Оплатить
+// больше остатка нельзя. Возвращает счет после оплаты.
+func (s *PayablesService) PayInvoice(actor Actor, payment *models.InvoicePayment) (*models.SupplierInvoice, error) {
+	invoice, err := s.GetInvoice(payment.InvoiceID)
+	if err != nil {
+		return nil, err
+	}
+	if invoice.Status == models.InvoicePaid {
+		return nil, ErrInvoicePaid
+	}
+
+	// Сумма меньше копейки после округления обнулилась бы
+	payment.Amount = roundMoney(payment.Amount)
+	if payment.Amount <= 0 {
+		return nil, ErrInvalidPaymentAmount
+	}
+	if payment.Method == "" {
+		payment.Method = models.InvoicePaymentTransfer
+	}
+	if payment.PaidAt.IsZero() {
+		payment.PaidAt = time.Now()
+	}
+	payment.PaidAt = startOfDay(payment.PaidAt)
+	payment.CreatedBy = actor.UserID
+
+	if err := s.Repo.AddPayment(payment); err != nil {
+		if errors.Is(err, repositories.ErrOverpayment) {
+			return nil, errs.NewValidation(ErrOverpayment.Code, fmt.Sprintf("%s: остаток %.2f", ErrOverpayment.Message, invoice.Outstanding()))
+		}
+		return nil, err
+	}
+
+	s.Audit.Record(actor, models.AuditCreate, AuditEntityInvoicePayment, payment.ID, nil, payment)
+	return s.Repo.FindByID(invoice.ID)
+}
+
+// GetOverdue возвращает неоплаченные счета, срок оплаты которых прошел к
+// дню day, начиная с самых старых.
+func (s *PayablesService) GetOverdue(day time.Time) ([]models.SupplierInvoice, error) {
+	return s.Repo.Find(models.InvoiceFilter{Overdue: true, Limit: -1}, startOfDay(day))
+}
+
+// AgingTotals — неоплаченные остатки по срокам просрочки.
+type AgingTotals struct {
+	Invoices   int
+	Current    float64 // срок оплаты еще не наступил
+	Days1To30  float64
+	Days31To60 float64
+	Days61To90 float64
+	Over90     float64
+	Total      float64
+}
+
+func (t *AgingTotals) add(amount float64, daysOverdue int) {
+	t.Invoices++
+	t.Total += amount
+	switch {
+	case daysOverdue <= 0:
+		t.Current += amount
+	case daysOverdue <= 30:
+		t.Days1To30 += amount
+	case daysOverdue <= 60:
+		t.Days31To60 += amount
+	case daysOverdue <= 90:
+		t.Days61To90 += amount
+	default:
+		t.Over90 += amount
+	}
+}
+
+func (t *AgingTotals) round() {
+	for _, amount := range []*float64{&t.Current, &t.Days1To30, &t.Days31To60, &t.Days61To90, &t.Over90, &t.Total} {
+		*amount = roundMoney(*amount)
+	}
+}
+
+// SupplierAging — задолженность перед поставщиком по срокам просрочки.
+type SupplierAging struct {
+	Supplier      models.Supplier
+	OldestDueDate time.Time
+	AgingTotals
+}
+
+// PayablesAging — отчет о задолженности перед поставщиками.
+type PayablesAging struct {
+	Date      time.Time
+	Suppliers []SupplierAging
+	Total     AgingTotals
+}
+
+// GetAging распределяет текущие неоплаченные остатки счетов по срокам
+// просрочки на день day. Поставщики идут по убыванию задолженности.
+func (s *PayablesService) GetAging(day time.Time) (*PayablesAging, error) {
+	invoices, err := s.Repo.FindOutstanding()
+	if err != nil {
+		return nil, err
+	}
+
+	day = startOfDay(day)
+	aging := &PayablesAging{Date: day}
+	bySupplier := make(map[uint]*SupplierAging)
+	var order []uint
+	for i := range invoices {
+		invoice := &invoices[i]
+		row, ok := bySupplier[invoice.SupplierID]
+		if !ok {
+			row = &SupplierAging{Supplier: invoice.Supplier, OldestDueDate: invoice.DueDate}
+			bySupplier[invoice.SupplierID] = row
+			order = append(order, invoice.SupplierID)
+		}
+
+		days := DaysOverdue(invoice.DueDate, day)
+		row.add(invoice.Outstanding(), days)
+		aging.Total.add(invoice.Outstanding(), days)
+		if invoice.DueDate.Before(row.OldestDueDate) {
+			row.OldestDueDate = invoice.DueDate
+		}
+	}
+
+	for _, id := range order {
+		row := bySupplier[id]
+		row.round()
+		aging.Suppliers = append(aging.Suppliers, *row)
+	}
+	aging.Total.round()
+	sort.SliceStable(aging.Suppliers, func(i, j int) bool {
+		return aging.Suppliers[i].Total > aging.Suppliers[j].Total
+	})
+	return aging, nil
+}
+
+// NotifyOverdue оповещает о счетах, которые стали просроченными, один раз
+// на каждый счет. Оповещение записывается в журнал сервера и остается в
+// GetOverdueAlerts, пока его не подтвердят или счет не оплатят.
+func (s *PayablesService) NotifyOverdue() {
+	today := startOfDay(time.Now())
+	invoices, err := s.Repo.FindNewlyOverdue(today)
+	if err != nil {
+		log.Printf("Проверка просроченных счетов: %v", err)
+		return
+	}
+	if len(invoices) == 0 {
+		return
+	}
+
+	ids := make([]uint, 0, len(invoices))
+	for i := range invoices {
+		invoice := &invoices[i]
+		log.Printf("Просрочен счет %s поставщика %q: остаток %.2f, срок оплаты %s (%d дн. назад)",
+			invoice.Number, invoice.Supplier.Name, invoice.Outstanding(),
+			invoice.DueDate.Format("02.01.2006"), DaysOverdue(invoice.DueDate, today))
+		ids = append(ids, invoice.ID)
+	}
+
+	if err := s.Repo.MarkOverdueNotified(ids, time.Now()); err != nil {
+		log.Printf("Проверка просроченных счетов: %v", err)
+	}
+}
+
+// GetOverdueAlerts возвращает неподтвержденные оповещения о просрочке,
+// начиная с самых старых счетов.
+func (s *PayablesService) GetOverdueAlerts() ([]models.SupplierInvoice, error) {
+	return s.Repo.FindOverdueAlerts()
+}
+
+// AcknowledgeOverdue подтверждает оповещение о просрочке счета и
+// возвращает счет.
+func (s *PayablesService) AcknowledgeOverdue(actor Actor, id uint) (*models.SupplierInvoice, error) {
+	existing, err := s.GetInvoice(id)
+	if err != nil {
+		return nil, err
+	}
+
+	acknowledged, err := s.Repo.AcknowledgeOverdue(id, actor.UserID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if !acknowledged {
+		return nil, ErrNoOverdueAlert
+	}
+
+	invoice, err := s.Repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.Audit.Record(actor, models.AuditUpdate, AuditEntitySupplierInvoice, id, existing, invoice)
+	return invoice, nil
+}
+
+// Run проверяет просроченные счета, пока не отменен ctx.
+func (s *PayablesService) Run(ctx context.Context) {
+	interval := s.CheckInterval
+	if interval <= 0 {
+		interval = DefaultOverdueCheckInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.NotifyOverdue()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DaysOverdue возвращает, на сколько дней к дню day просрочен срок
+// оплаты due; отрицательное значение — сколько дней осталось.
+func DaysOverdue(due, day time.Time) int {
+	return int(math.Round(startOfDay(day).Sub(startOfDay(due)).Hours() / 24))
+}
+
+// startOfDay возвращает начало дня t по местному времени.
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.In(time.Local).Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.Local)
+}
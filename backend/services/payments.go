@@ -0,0 +1,258 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"time"
+
+	"grocery-store-api/models"
+	"grocery-store-api/services/errs"
+)
+
+var (
+	ErrPaymentMismatch     = errs.NewValidation("payment_mismatch", "сумма платежей не совпадает со стоимостью продажи")
+	ErrInsufficientTender  = errs.NewValidation("insufficient_tender", "получено наличных меньше суммы оплаты")
+	ErrMultipleCashTenders = errs.NewValidation("multiple_cash_tenders", "наличными можно оплатить только одним платежом")
+	ErrInvalidPayment      = errs.NewValidation("invalid_payment", "недопустимый способ оплаты")
+	ErrPaymentDeclined     = errs.New(errs.PaymentRequired, "payment_declined", "оплата картой отклонена")
+	ErrTerminalUnavailable = errs.New(errs.Unavailable, "terminal_unavailable", "платежный терминал недоступен")
+	ErrRefundDeclined      = errs.NewConflict("refund_declined", "возврат на карту отклонен")
+)
+
+// TerminalCharge — запрос на списание с карты. Reference — уникальный номер
+// операции кассы: повторный запрос с тем же номером (например, после обрыва
+// связи) не должен списывать деньги второй раз.
+type TerminalCharge struct {
+	Reference string
+	Register  string
+	Amount    float64
+}
+
+// TerminalRefund — запрос на возврат денег на карту по ранее одобренной
+// операции TransactionID. Reference, как и у списания, защищает от
+// повторного возврата.
+type TerminalRefund struct {
+	Reference     string
+	Register      string
+	TransactionID string
+	Amount        float64
+}
+
+// TerminalResult — ответ терминала. Отказ банка — это не ошибка связи:
+// Approved = false и причина в DeclineReason.
+type TerminalResult struct {
+	Approved      bool
+	DeclineReason string
+	TransactionID string
+	AuthCode      string
+	CardMask      string
+}
+
+// PaymentTerminal — банковский платежный терминал.
+type PaymentTerminal interface {
+	Charge(ctx context.Context, charge TerminalCharge) (*TerminalResult, error)
+	// Refund возвращает часть или всю сумму одобренной операции.
+	Refund(ctx context.Context, refund TerminalRefund) (*TerminalResult, error)
+	// Cancel отменяет одобренную операцию (в том числе возврат), если
+	// продажу или возврат не удалось провести.
+	Cancel(ctx context.Context, transactionID string) error
+}
+
+// PaymentService проверяет оплату продажи и проводит оплату картой через
+// платежный терминал.
+type PaymentService struct {
+	Terminal PaymentTerminal
+	// Timeout ограничивает ожидание ответа терминала: покупатель может
+	// долго вводить PIN-код.
+	Timeout time.Duration
+}
+
+const DefaultTerminalTimeout = 2 * time.Minute
+
+// PreparePayments проверяет платежи продажи и считает сдачу. due — сумма к
+// оплате деньгами (стоимость продажи за вычетом баллов). Без платежей
+// продажа считается оплаченной наличными без сдачи. Сумма платежей должна
+// совпадать с due с точностью до копейки.
+func (s *PaymentService) PreparePayments(sale *models.Sale, due float64) error {
+	if len(sale.Payments) == 0 {
+		if due > 0 {
+			sale.Payments = []models.Payment{{
+				Method:   models.PaymentCash,
+				Amount:   due,
+				Tendered: due,
+			}}
+		}
+		return nil
+	}
+
+	var total float64
+	cashTenders := 0
+	for i := range sale.Payments {
+		payment := &sale.Payments[i]
+		payment.Amount = roundMoney(payment.Amount)
+
+		switch payment.Method {
+		case models.PaymentCash:
+			cashTenders++
+			if payment.Tendered == 0 {
+				payment.Tendered = payment.Amount
+			}
+			payment.Tendered = roundMoney(payment.Tendered)
+			if payment.Tendered < payment.Amount {
+				return ErrInsufficientTender
+			}
+			payment.ChangeDue = roundMoney(payment.Tendered - payment.Amount)
+		case models.PaymentCard:
+			// С карты списывается ровно сумма платежа
+			payment.Tendered = payment.Amount
+			payment.ChangeDue = 0
+		default:
+			return ErrInvalidPayment
+		}
+		total += payment.Amount
+	}
+
+	if cashTenders > 1 {
+		return ErrMultipleCashTenders
+	}
+	if roundMoney(total) != roundMoney(due) {
+		return errs.NewValidation(ErrPaymentMismatch.Code, fmt.Sprintf("%s: платежи %.2f, к оплате %.2f", ErrPaymentMismatch.Message, total, due))
+	}
+	return nil
+}
+
+// ChargeCards проводит платежи картой через терминал. Если одна из карт
+// отклонена или терминал недоступен, уже одобренные операции отменяются.
+func (s *PaymentService) ChargeCards(register string, payments []models.Payment) error {
+	for i := range payments {
+		payment := &payments[i]
+		if payment.Method != models.PaymentCard {
+			continue
+		}
+
+		reference, err := newTerminalReference()
+		if err != nil {
+			return err
+		}
+		payment.TerminalReference = reference
+
+		result, err := s.charge(TerminalCharge{Reference: reference, Register: register, Amount: payment.Amount})
+		if err != nil {
+			s.CancelCards(payments[:i])
+			return fmt.Errorf("%w: %v", ErrTerminalUnavailable, err)
+		}
+		if !result.Approved {
+			s.CancelCards(payments[:i])
+			message := ErrPaymentDeclined.Message
+			if result.DeclineReason != "" {
+				message += ": " + result.DeclineReason
+			}
+			return errs.New(errs.PaymentRequired, ErrPaymentDeclined.Code, message)
+		}
+
+		payment.TransactionID = result.TransactionID
+		payment.AuthCode = result.AuthCode
+		payment.CardMask = result.CardMask
+	}
+	return nil
+}
+
+// CancelCards отменяет одобренные операции по картам. Ошибки отмены только
+// записываются в журнал: деньги вернет сверка с банком.
+func (s *PaymentService) CancelCards(payments []models.Payment) {
+	for _, payment := range payments {
+		if payment.Method != models.PaymentCard || payment.TransactionID == "" {
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), s.timeout())
+		err := s.Terminal.Cancel(ctx, payment.TransactionID)
+		cancel()
+		if err != nil {
+			log.Printf("Отмена операции %s по карте %s: %v", payment.TransactionID, payment.CardMask, err)
+		}
+	}
+}
+
+// RefundCards проводит возвраты на карту через терминал. Если один из
+// возвратов не прошел, уже проведенные отменяются.
+func (s *PaymentService) RefundCards(register string, refunds []models.Refund) error {
+	for i := range refunds {
+		refund := &refunds[i]
+		if refund.Method != models.PaymentCard {
+			continue
+		}
+
+		reference, err := newTerminalReference()
+		if err != nil {
+			return err
+		}
+		refund.TerminalReference = reference
+
+		ctx, cancel := context.WithTimeout(context.Background(), s.timeout())
+		result, err := s.Terminal.Refund(ctx, TerminalRefund{
+			Reference:     reference,
+			Register:      register,
+			TransactionID: refund.OriginalTransactionID,
+			Amount:        refund.Amount,
+		})
+		cancel()
+		if err != nil {
+			s.CancelRefunds(refunds[:i])
+			return fmt.Errorf("%w: %v", ErrTerminalUnavailable, err)
+		}
+		if !result.Approved {
+			s.CancelRefunds(refunds[:i])
+			message := ErrRefundDeclined.Message
+			if result.DeclineReason != "" {
+				message += ": " + result.DeclineReason
+			}
+			return errs.NewConflict(ErrRefundDeclined.Code, message)
+		}
+
+		refund.TransactionID = result.TransactionID
+	}
+	return nil
+}
+
+// CancelRefunds отменяет проведенные возвраты на карту, если возврат не
+// удалось сохранить. Ошибки отмены только записываются в журнал.
+func (s *PaymentService) CancelRefunds(refunds []models.Refund) {
+	for _, refund := range refunds {
+		if refund.Method != models.PaymentCard || refund.TransactionID == "" {
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), s.timeout())
+		err := s.Terminal.Cancel(ctx, refund.TransactionID)
+		cancel()
+		if err != nil {
+			log.Printf("Отмена возврата %s по операции %s: %v", refund.TransactionID, refund.OriginalTransactionID, err)
+		}
+	}
+}
+
+func (s *PaymentService) charge(charge TerminalCharge) (*TerminalResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout())
+	defer cancel()
+	return s.Terminal.Charge(ctx, charge)
+}
+
+func (s *PaymentService) timeout() time.Duration {
+	if s.Timeout <= 0 {
+		return DefaultTerminalTimeout
+	}
+	return s.Timeout
+}
+
+// newTerminalReference создает уникальный номер операции для терминала.
+func newTerminalReference() (string, error) {
+	buf := make([]byte, 12)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
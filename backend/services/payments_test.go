@@ -0,0 +1,126 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"grocery-store-api/models"
+	"grocery-store-api/services/errs"
+)
+
+func TestPreparePayments(t *testing.T) {
+	tests := []struct {
+		name     string
+		payments []models.Payment
+		due      float64
+		want     []models.Payment
+		// err — код ожидаемой ошибки
+		err string
+	}{
+		{
+			name: "без платежей оплачено наличными",
+			due:  12.5,
+			want: []models.Payment{{Method: models.PaymentCash, Amount: 12.5, Tendered: 12.5}},
+		},
+		{
+			name: "без платежей и без суммы к оплате",
+			due:  0,
+		},
+		{
+			name:     "наличные со сдачей",
+			payments: []models.Payment{{Method: models.PaymentCash, Amount: 7.3, Tendered: 10}},
+			due:      7.3,
+			want:     []models.Payment{{Method: models.PaymentCash, Amount: 7.3, Tendered: 10, ChangeDue: 2.7}},
+		},
+		{
+			name:     "наличные без полученной суммы — без сдачи",
+			payments: []models.Payment{{Method: models.PaymentCash, Amount: 5}},
+			due:      5,
+			want:     []models.Payment{{Method: models.PaymentCash, Amount: 5, Tendered: 5}},
+		},
+		{
+			name:     "суммы округляются до копейки",
+			payments: []models.Payment{{Method: models.PaymentCash, Amount: 3.334, Tendered: 5.001}},
+			due:      3.33,
+			want:     []models.Payment{{Method: models.PaymentCash, Amount: 3.33, Tendered: 5, ChangeDue: 1.67}},
+		},
+		{
+			name: "карта и наличные",
+			payments: []models.Payment{
+				{Method: models.PaymentCard, Amount: 6, Tendered: 100, ChangeDue: 94},
+				{Method: models.PaymentCash, Amount: 4, Tendered: 5},
+			},
+			due: 10,
+			want: []models.Payment{
+				{Method: models.PaymentCard, Amount: 6, Tendered: 6},
+				{Method: models.PaymentCash, Amount: 4, Tendered: 5, ChangeDue: 1},
+			},
+		},
+		{
+			name: "две карты",
+			payments: []models.Payment{
+				{Method: models.PaymentCard, Amount: 0.1},
+				{Method: models.PaymentCard, Amount: 0.2},
+			},
+			due: 0.3,
+			want: []models.Payment{
+				{Method: models.PaymentCard, Amount: 0.1, Tendered: 0.1},
+				{Method: models.PaymentCard, Amount: 0.2, Tendered: 0.2},
+			},
+		},
+		{
+			name:     "получено меньше суммы",
+			payments: []models.Payment{{Method: models.PaymentCash, Amount: 10, Tendered: 9.99}},
+			due:      10,
+			err:      ErrInsufficientTender.Code,
+		},
+		{
+			name: "несколько платежей наличными",
+			payments: []models.Payment{
+				{Method: models.PaymentCash, Amount: 5},
+				{Method: models.PaymentCash, Amount: 5},
+			},
+			due: 10,
+			err: ErrMultipleCashTenders.Code,
+		},
+		{
+			name:     "баллы не принимаются как платеж",
+			payments: []models.Payment{{Method: models.PaymentPoints, Amount: 10}},
+			due:      10,
+			err:      ErrInvalidPayment.Code,
+		},
+		{
+			name:     "платежей меньше суммы к оплате",
+			payments: []models.Payment{{Method: models.PaymentCard, Amount: 9.99}},
+			due:      10,
+			err:      ErrPaymentMismatch.Code,
+		},
+		{
+			name:     "платежей больше суммы к оплате",
+			payments: []models.Payment{{Method: models.PaymentCard, Amount: 10.01}},
+			due:      10,
+			err:      ErrPaymentMismatch.Code,
+		},
+	}
+
+	var service PaymentService
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sale := &models.Sale{Payments: tt.payments}
+			err := service.PreparePayments(sale, tt.due)
+
+			if tt.err != "" {
+				if e, ok := errs.As(err); !ok || e.Code != tt.err {
+					t.Fatalf("err = %v, want %s", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("err = %v", err)
+			}
+			if !reflect.DeepEqual(sale.Payments, tt.want) {
+				t.Errorf("payments = %+v, want %+v", sale.Payments, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package services
+
+import (
+	"errors"
+
+	"grocery-store-api/models"
+	"grocery-store-api/repositories"
+	"grocery-store-api/services/errs"
+)
+
+var (
+	ErrReturnExceedsSale      = errs.NewValidation("return_exceeds_sale", "нельзя вернуть больше, чем осталось невозвращенным в продаже")
+	ErrReturnShiftNotOpen     = errs.NewConflict("shift_not_open", "нет открытой смены: откройте смену перед возвратом")
+	ErrInsufficientRefundCash = errs.NewConflict("insufficient_cash", "в кассе недостаточно наличных для возврата")
+)
+
+// ReturnService оформляет возвраты товара по продажам. Деньги и баллы
+// возвращаются теми же способами, которыми была оплачена продажа,
+// пропорционально возвращенному количеству.
+type ReturnService struct {
+	Repo      repositories.SaleReturnRepository
+	SaleRepo  repositories.SaleRepository
+	ShiftRepo repositories.ShiftRepository
+	Shifts    ShiftService
+	Payments  PaymentService
+	Fiscal    FiscalService
+	Audit     AuditService
+}
+
+func (s *ReturnService) CreateReturn(actor Actor, saleID uint, quantity int, reason string) (*models.SaleReturn, error) {
+	sale, err := s.SaleRepo.FindByID(saleID)
+	if err != nil {
+		return nil, notFound(err, ErrSaleNotFound)
+	}
+	if quantity > sale.Quantity-sale.ReturnedQuantity {
+		return nil, ErrReturnExceedsSale
+	}
+
+	// Возврат проводится в открытой смене того, кто его оформляет
+	shift, err := s.ShiftRepo.FindOpenByCashier(actor.UserID)
+	if err != nil {
+		return nil, notFound(err, ErrReturnShiftNotOpen)
+	}
+
+	previous, err := s.Repo.FindBySale(sale.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := &models.SaleReturn{
+		SaleID:    sale.ID,
+		ShiftID:   shift.ID,
+		Quantity:  quantity,
+		Reason:    reason,
+		CreatedBy: actor.UserID,
+	}
+	ret.Refunds, ret.PointsReversed = returnRefunds(sale, previous, quantity)
+
+	cash := 0.0
+	for _, refund := range ret.Refunds {
+		ret.Amount += refund.Amount
+		if refund.Method == models.PaymentCash {
+			cash += refund.Amount
+		}
+	}
+	ret.Amount = roundMoney(ret.Amount)
+
+	if cash > 0 {
+		report, err := s.Shifts.buildReport(shift)
+		if err != nil {
+			return nil, err
+		}
+		if cash > report.ExpectedCash {
+			return nil, ErrInsufficientRefundCash
+		}
+	}
+
+	if err := s.Payments.RefundCards(shift.Register, ret.Refunds); err != nil {
+		return nil, err
+	}
+
+	if err := s.Repo.Create(ret, sale); err != nil {
+		s.Payments.CancelRefunds(ret.Refunds)
+		if errors.Is(err, repositories.ErrReturnExceedsSale) {
+			return nil, ErrReturnExceedsSale
+		}
+		return nil, err
+	}
+
+	s.Audit.Record(actor, models.AuditCreate, AuditEntitySaleReturn, ret.ID, nil, ret)
+
+	// Чек возврата прихода пробивается в фоне, как и чек продажи; для него
+	// нужны товар продажи и имя кассира
+	receipt := *ret
+	receipt.Sale = *sale
+	receipt.Creator = models.User{ID: actor.UserID, Username: actor.Username}
+	go s.Fiscal.FiscalizeReturn(&receipt)
+	return ret, nil
+}
+
+// GetReturns возвращает возвраты продажи.
+func (s *ReturnService) GetReturns(saleID uint) ([]models.SaleReturn, error) {
+	if _, err := s.SaleRepo.FindByID(saleID); err != nil {
+		return nil, notFound(err, ErrSaleNotFound)
+	}
+	return s.Repo.FindBySale(saleID)
+}
+
+// returnRefunds делит возврат quantity единиц продажи между способами
+// оплаты пропорционально оплаченному и считает баллы, начисленные за
+// возвращаемый товар. previous — ранее оформленные возвраты продажи:
+// возврат последних единиц забирает остатки, чтобы ошибки округления не
+// накапливались. Возврат на карту распределяется по платежам картой по
+// порядку.
+func returnRefunds(sale *models.Sale, previous []models.SaleReturn, quantity int) ([]models.Refund, int) {
+	paid := make(map[string]float64, len(models.PaymentMethods))
+	for _, payment := range sale.Payments {
+		paid[payment.Method] += payment.Amount
+	}
+
+	returnedQuantity, pointsReversed := 0, 0
+	refunded := make(map[string]float64, len(models.PaymentMethods))
+	for _, ret := range previous {
+		returnedQuantity += ret.Quantity
+		pointsReversed += ret.PointsReversed
+		for _, refund := range ret.Refunds {
+			refunded[refund.Method] += refund.Amount
+		}
+	}
+	last := returnedQuantity+quantity >= sale.Quantity
+
+	share := func(total, done float64) float64 {
+		if last {
+			return max(roundMoney(total-done), 0)
+		}
+		return roundMoney(total * float64(quantity) / float64(sale.Quantity))
+	}
+
+	var refunds []models.Refund
+	for _, method := range models.PaymentMethods {
+		amount := share(paid[method], refunded[method])
+		if method == models.PaymentPoints && !last {
+			amount = float64(int(paid[method]) * quantity / sale.Quantity)
+		}
+		if amount <= 0 {
+			continue
+		}
+
+		if method != models.PaymentCard {
+			refunds = append(refunds, models.Refund{Method: method, Amount: amount})
+			continue
+		}
+
+		// Прежние возвраты на карту занимали платежи картой по порядку
+		done := refunded[models.PaymentCard]
+		for _, payment := range sale.Payments {
+			if payment.Method != models.PaymentCard || amount <= 0 {
+				continue
+			}
+			used := min(payment.Amount, done)
+			done = roundMoney(done - used)
+			part := min(roundMoney(payment.Amount-used), amount)
+			if part <= 0 {
+				continue
+			}
+			refunds = append(refunds, models.Refund{Method: method, Amount: part, OriginalTransactionID: payment.TransactionID})
+			amount = roundMoney(amount - part)
+		}
+	}
+
+	reversed := sale.PointsEarned * quantity / sale.Quantity
+	if last {
+		reversed = max(sale.PointsEarned-pointsReversed, 0)
+	}
+	return refunds, reversed
+}
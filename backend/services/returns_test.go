@@ -0,0 +1,166 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"grocery-store-api/models"
+)
+
+func TestReturnRefunds(t *testing.T) {
+	type step struct {
+		quantity int
+		refunds  []models.Refund
+		points   int
+	}
+
+	tests := []struct {
+		name string
+		sale models.Sale
+		// steps — возвраты по продаже по порядку; каждый следующий видит
+		// предыдущие
+		steps []step
+	}{
+		{
+			name: "частичный и последний возврат в сумме дают оплаченное",
+			sale: models.Sale{
+				Quantity: 3,
+				Payments: []models.Payment{{Method: models.PaymentCash, Amount: 10}},
+			},
+			steps: []step{
+				{quantity: 1, refunds: []models.Refund{{Method: models.PaymentCash, Amount: 3.33}}},
+				{quantity: 1, refunds: []models.Refund{{Method: models.PaymentCash, Amount: 3.33}}},
+				{quantity: 1, refunds: []models.Refund{{Method: models.PaymentCash, Amount: 3.34}}},
+			},
+		},
+		{
+			name: "весь товар одним возвратом",
+			sale: models.Sale{
+				Quantity: 3,
+				Payments: []models.Payment{{Method: models.PaymentCash, Amount: 10}},
+			},
+			steps: []step{
+				{quantity: 3, refunds: []models.Refund{{Method: models.PaymentCash, Amount: 10}}},
+			},
+		},
+		{
+			name: "наличные, карта и баллы",
+			sale: models.Sale{
+				Quantity: 3,
+				Payments: []models.Payment{
+					{Method: models.PaymentPoints, Amount: 20},
+					{Method: models.PaymentCard, Amount: 50, TransactionID: "T1"},
+					{Method: models.PaymentCash, Amount: 30},
+				},
+			},
+			steps: []step{
+				{quantity: 1, refunds: []models.Refund{
+					{Method: models.PaymentCash, Amount: 10},
+					{Method: models.PaymentCard, Amount: 16.67, OriginalTransactionID: "T1"},
+					// Баллы возвращаются целыми, с округлением вниз
+					{Method: models.PaymentPoints, Amount: 6},
+				}},
+				{quantity: 2, refunds: []models.Refund{
+					{Method: models.PaymentCash, Amount: 20},
+					{Method: models.PaymentCard, Amount: 33.33, OriginalTransactionID: "T1"},
+					{Method: models.PaymentPoints, Amount: 14},
+				}},
+			},
+		},
+		{
+			name: "две карты: прежний возврат занял часть первой",
+			sale: models.Sale{
+				Quantity: 4,
+				Payments: []models.Payment{
+					{Method: models.PaymentCard, Amount: 30, TransactionID: "T1"},
+					{Method: models.PaymentCard, Amount: 10, TransactionID: "T2"},
+				},
+			},
+			steps: []step{
+				{quantity: 1, refunds: []models.Refund{{Method: models.PaymentCard, Amount: 10, OriginalTransactionID: "T1"}}},
+				{quantity: 3, refunds: []models.Refund{
+					{Method: models.PaymentCard, Amount: 20, OriginalTransactionID: "T1"},
+					{Method: models.PaymentCard, Amount: 10, OriginalTransactionID: "T2"},
+				}},
+			},
+		},
+		{
+			name: "две карты: возврат целиком по первой",
+			sale: models.Sale{
+				Quantity: 4,
+				Payments: []models.Payment{
+					{Method: models.PaymentCard, Amount: 30, TransactionID: "T1"},
+					{Method: models.PaymentCard, Amount: 10, TransactionID: "T2"},
+				},
+			},
+			steps: []step{
+				{quantity: 1, refunds: []models.Refund{{Method: models.PaymentCard, Amount: 10, OriginalTransactionID: "T1"}}},
+				{quantity: 2, refunds: []models.Refund{{Method: models.PaymentCard, Amount: 20, OriginalTransactionID: "T1"}}},
+				{quantity: 1, refunds: []models.Refund{{Method: models.PaymentCard, Amount: 10, OriginalTransactionID: "T2"}}},
+			},
+		},
+		{
+			name: "начисленные баллы не делятся на количество нацело",
+			sale: models.Sale{
+				Quantity:     3,
+				PointsEarned: 10,
+				Payments:     []models.Payment{{Method: models.PaymentCash, Amount: 30}},
+			},
+			steps: []step{
+				{quantity: 1, refunds: []models.Refund{{Method: models.PaymentCash, Amount: 10}}, points: 3},
+				{quantity: 1, refunds: []models.Refund{{Method: models.PaymentCash, Amount: 10}}, points: 3},
+				// Последний возврат забирает остаток баллов
+				{quantity: 1, refunds: []models.Refund{{Method: models.PaymentCash, Amount: 10}}, points: 4},
+			},
+		},
+		{
+			name: "начисленные баллы при возврате нескольких единиц",
+			sale: models.Sale{
+				Quantity:     3,
+				PointsEarned: 10,
+				Payments:     []models.Payment{{Method: models.PaymentCash, Amount: 30}},
+			},
+			steps: []step{
+				{quantity: 2, refunds: []models.Refund{{Method: models.PaymentCash, Amount: 20}}, points: 6},
+				{quantity: 1, refunds: []models.Refund{{Method: models.PaymentCash, Amount: 10}}, points: 4},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var previous []models.SaleReturn
+			refunded := make(map[string]float64)
+			points := 0
+			for i, step := range tt.steps {
+				refunds, reversed := returnRefunds(&tt.sale, previous, step.quantity)
+				if !reflect.DeepEqual(refunds, step.refunds) {
+					t.Errorf("return %d: refunds = %+v, want %+v", i+1, refunds, step.refunds)
+				}
+				if reversed != step.points {
+					t.Errorf("return %d: points reversed = %d, want %d", i+1, reversed, step.points)
+				}
+
+				previous = append(previous, models.SaleReturn{Quantity: step.quantity, PointsReversed: reversed, Refunds: refunds})
+				for _, refund := range refunds {
+					refunded[refund.Method] += refund.Amount
+				}
+				points += reversed
+			}
+
+			// После возврата всего товара возвращено ровно оплаченное
+			paid := make(map[string]float64)
+			for _, payment := range tt.sale.Payments {
+				paid[payment.Method] += payment.Amount
+			}
+			for method, amount := range paid {
+				if !almostEqual(refunded[method], amount) {
+					t.Errorf("%s: refunded %v, paid %v", method, refunded[method], amount)
+				}
+			}
+			if points != tt.sale.PointsEarned {
+				t.Errorf("points reversed %d, earned %d", points, tt.sale.PointsEarned)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"grocery-store-api/models"
+	"grocery-store-api/repositories"
+	"grocery-store-api/services/errs"
+)
+
+var ErrInvalidSalesGrouping = errs.NewValidation("invalid_group_by", "допустимые значения group_by: "+strings.Join(models.SalesGroupings, ", "))
+
+// DefaultTopProducts — сколько лучших и худших товаров попадает в
+// аналитику продаж, если не указано иное; maxTopProducts — предел.
+const (
+	DefaultTopProducts = 10
+	maxTopProducts     = 100
+)
+
+// SalesAnalytics — продажи за период [From, To): итоги, сравнение с
+// предыдущим периодом той же длины [PreviousFrom, From), разбивка по
+// разрезу GroupBy, лучшие и худшие товары по выручке.
+type SalesAnalytics struct {
+	From, To     time.Time
+	PreviousFrom time.Time
+	GroupBy      string
+
+	Summary        repositories.SalesSummary
+	Previous       repositories.SalesSummary
+	Payments       []repositories.PaymentTotal
+	Groups         []repositories.SalesGroup
+	TopProducts    []repositories.ProductSales
+	BottomProducts []repositories.ProductSales
+}
+
+// GetSalesAnalytics считает аналитику продаж за период [from, to) по
+// товарам отделов, доступных пользователю. Пустой groupBy — по дням;
+// в разрезах по времени пропущенные дни, недели, месяцы и часы
+// дополняются нулями. top — сколько лучших и худших товаров вернуть.
+func (s *SaleService) GetSalesAnalytics(actor Actor, from, to time.Time, groupBy string, top int) (*SalesAnalytics, error) {
+	if groupBy == "" {
+		groupBy = models.SalesByDay
+	}
+	if !models.IsValidSalesGrouping(groupBy) {
+		return nil, ErrInvalidSalesGrouping
+	}
+	if top <= 0 {
+		top = DefaultTopProducts
+	}
+	if top > maxTopProducts {
+		top = maxTopProducts
+	}
+
+	ids, all, err := s.Scope.Departments(actor)
+	if err != nil {
+		return nil, err
+	}
+	if all {
+		ids = nil
+	}
+
+	days := int(to.Sub(from).Round(24*time.Hour).Hours() / 24)
+	analytics := &SalesAnalytics{From: from, To: to, PreviousFrom: from.AddDate(0, 0, -days), GroupBy: groupBy}
+
+	if analytics.Summary, err = s.Repo.Summary(from, to, ids); err != nil {
+		return nil, err
+	}
+	if analytics.Previous, err = s.Repo.Summary(analytics.PreviousFrom, from, ids); err != nil {
+		return nil, err
+	}
+	if analytics.Payments, err = s.Repo.PaymentTotals(from, to, ids); err != nil {
+		return nil, err
+	}
+
+	groups, err := s.Repo.Groups(from, to, ids, groupBy)
+	if err != nil {
+		return nil, err
+	}
+	analytics.Groups = fillTimeline(groups, groupBy, from, to)
+
+	if analytics.TopProducts, err = s.Repo.ProductSales(from, to, ids, top, false); err != nil {
+		return nil, err
+	}
+	if analytics.BottomProducts, err = s.Repo.ProductSales(from, to, ids, top, true); err != nil {
+		return nil, err
+	}
+	return analytics, nil
+}
+
+// fillTimeline дополняет разрез по времени пустыми днями, неделями,
+// месяцами или часами, чтобы ряд шел без пропусков. Остальные разрезы
+// возвращаются как есть.
+func fillTimeline(groups []repositories.SalesGroup, groupBy string, from, to time.Time) []repositories.SalesGroup {
+	var keys []string
+	switch groupBy {
+	case models.SalesByDay:
+		for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+			keys = append(keys, day.Format("2006-01-02"))
+		}
+	case models.SalesByWeek:
+		monday := from.AddDate(0, 0, -(int(from.Weekday())+6)%7)
+		for week := monday; week.Before(to); week = week.AddDate(0, 0, 7) {
+			keys = append(keys, week.Format("2006-01-02"))
+		}
+	case models.SalesByMonth:
+		first := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, from.Location())
+		for month := first; month.Before(to); month = month.AddDate(0, 1, 0) {
+			keys = append(keys, month.Format("2006-01"))
+		}
+	case models.SalesByHour:
+		for hour := 0; hour < 24; hour++ {
+			keys = append(keys, fmt.Sprintf("%02d:00", hour))
+		}
+	default:
+		return groups
+	}
+
+	byKey := make(map[string]repositories.SalesGroup, len(groups))
+	for _, group := range groups {
+		byKey[group.Key] = group
+	}
+
+	filled := make([]repositories.SalesGroup, 0, len(keys))
+	for _, key := range keys {
+		group, ok := byKey[key]
+		if !ok {
+			group = repositories.SalesGroup{Key: key}
+		}
+		filled = append(filled, group)
+	}
+	return filled
+}
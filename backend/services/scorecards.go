@@ -0,0 +1,208 @@
+package services
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"grocery-store-api/models"
+)
+
+// DefaultNearExpiryDays — партия считается привезенной на исходе срока,
+// если до конца срока годности при приемке оставалось меньше стольких дней.
+const DefaultNearExpiryDays = 7
+
+// SupplierScorecard — показатели работы поставщика за период по истории
+// поставок. Своевременность и полнота поставок появятся вместе с заказами
+// поставщикам: без заказа не с чем сравнивать привезенное.
+type SupplierScorecard struct {
+	Supplier models.Supplier
+	From, To time.Time
+
+	Deliveries      int
+	FirstDelivery   *time.Time
+	LastDelivery    *time.Time
+	AvgIntervalDays *float64
+	Spend           float64
+	Months          []SupplierSpendMonth
+	Products        []SupplierPriceDrift
+
+	NearExpiryDays     int
+	LinesWithExpiry    int
+	QuantityWithExpiry int
+	NearExpiryLines    int
+	NearExpiryQuantity int
+}
+
+// SupplierSpendMonth — поставки за календарный месяц.
+type SupplierSpendMonth struct {
+	Month      time.Time
+	Deliveries int
+	Quantity   int
+	Spend      float64
+}
+
+// SupplierPriceDrift — изменение закупочной цены товара за период: первая
+// и последняя цена поставки, разброс, средняя цена, взвешенная по
+// количеству, и цена единицы по действующему прайс-листу (nil, если нет).
+type SupplierPriceDrift struct {
+	Product    models.Product
+	Deliveries int
+	Quantity   int
+	FirstPrice float64
+	LastPrice  float64
+	MinPrice   float64
+	MaxPrice   float64
+	AvgPrice   float64
+	ListPrice  *float64
+}
+
+// Change возвращает изменение цены от первой поставки к последней в
+// процентах.
+func (d *SupplierPriceDrift) Change() float64 {
+	if d.FirstPrice == 0 {
+		return 0
+	}
+	return (d.LastPrice - d.FirstPrice) / d.FirstPrice * 100
+}
+
+// GetSupplierScorecard считает показатели поставщика по поставкам за
+// период [from, to). nearExpiryDays <= 0 заменяется на
+// DefaultNearExpiryDays. Позиции без срока годности в долю партий на
+// исходе срока не входят.
+func (s *SupplyService) GetSupplierScorecard(supplierID uint, from, to time.Time, nearExpiryDays int) (*SupplierScorecard, error) {
+	supplier, err := s.SupplierRepo.FindArchivedByID(supplierID)
+	if err != nil {
+		return nil, notFound(err, ErrSupplierNotFound)
+	}
+
+	if nearExpiryDays <= 0 {
+		nearExpiryDays = DefaultNearExpiryDays
+	}
+
+	items, err := s.ItemRepo.FindBySupplier(supplierID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	card := &SupplierScorecard{Supplier: *supplier, From: from, To: to, NearExpiryDays: nearExpiryDays}
+
+	var months []*SupplierSpendMonth
+	byMonth := make(map[time.Time]*SupplierSpendMonth)
+	var drifts []*SupplierPriceDrift
+	byProduct := make(map[uint]*SupplierPriceDrift)
+	var deliveryDates []time.Time
+	lastSupplyID := uint(0)
+	lastDriftSupply := make(map[uint]uint)
+
+	for i := range items {
+		item := &items[i]
+		supply := &item.Supply
+		year, month, _ := supply.SupplyDate.In(time.Local).Date()
+		key := time.Date(year, month, 1, 0, 0, 0, 0, time.Local)
+
+		bucket, ok := byMonth[key]
+		if !ok {
+			bucket = &SupplierSpendMonth{Month: key}
+			byMonth[key] = bucket
+			months = append(months, bucket)
+		}
+		bucket.Quantity += item.Quantity
+
+		// Позиции идут по поставкам, поэтому новая поставка начинается со
+		// смены SupplyID
+		if item.SupplyID != lastSupplyID {
+			lastSupplyID = item.SupplyID
+			deliveryDates = append(deliveryDates, supply.SupplyDate)
+			bucket.Deliveries++
+			bucket.Spend += supply.TotalCost
+			card.Spend += supply.TotalCost
+		}
+
+		drift, ok := byProduct[item.ProductID]
+		if !ok {
+			drift = &SupplierPriceDrift{
+				Product:    item.Product,
+				FirstPrice: item.UnitPrice,
+				MinPrice:   item.UnitPrice,
+				MaxPrice:   item.UnitPrice,
+			}
+			byProduct[item.ProductID] = drift
+			drifts = append(drifts, drift)
+		}
+		if lastDriftSupply[item.ProductID] != item.SupplyID {
+			lastDriftSupply[item.ProductID] = item.SupplyID
+			drift.Deliveries++
+		}
+		drift.Quantity += item.Quantity
+		drift.LastPrice = item.UnitPrice
+		drift.MinPrice = math.Min(drift.MinPrice, item.UnitPrice)
+		drift.MaxPrice = math.Max(drift.MaxPrice, item.UnitPrice)
+		drift.AvgPrice += item.UnitPrice * float64(item.Quantity)
+
+		if item.ExpiryDate != nil {
+			card.LinesWithExpiry++
+			card.QuantityWithExpiry += item.Quantity
+			if item.ExpiryDate.Sub(supply.SupplyDate) < time.Duration(nearExpiryDays)*24*time.Hour {
+				card.NearExpiryLines++
+				card.NearExpiryQuantity += item.Quantity
+			}
+		}
+	}
+
+	card.Deliveries = len(deliveryDates)
+	if card.Deliveries > 0 {
+		first, last := deliveryDates[0], deliveryDates[len(deliveryDates)-1]
+		card.FirstDelivery, card.LastDelivery = &first, &last
+	}
+	if card.Deliveries > 1 {
+		interval := deliveryDates[len(deliveryDates)-1].Sub(deliveryDates[0]).Hours() / 24 / float64(card.Deliveries-1)
+		card.AvgIntervalDays = &interval
+	}
+	card.Spend = roundMoney(card.Spend)
+
+	for _, bucket := range months {
+		bucket.Spend = roundMoney(bucket.Spend)
+		card.Months = append(card.Months, *bucket)
+	}
+
+	if err := s.fillListPrices(supplierID, byProduct); err != nil {
+		return nil, err
+	}
+
+	for _, drift := range drifts {
+		if drift.Quantity > 0 {
+			drift.AvgPrice = roundMoney(drift.AvgPrice / float64(drift.Quantity))
+		}
+		card.Products = append(card.Products, *drift)
+	}
+	// Сначала товары, цена которых изменилась сильнее всего
+	sort.SliceStable(card.Products, func(i, j int) bool {
+		return math.Abs(card.Products[i].Change()) > math.Abs(card.Products[j].Change())
+	})
+
+	return card, nil
+}
+
+// fillListPrices проставляет цену единицы товара по прайс-листу
+// поставщика, действующему сейчас.
+func (s *SupplyService) fillListPrices(supplierID uint, byProduct map[uint]*SupplierPriceDrift) error {
+	if len(byProduct) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, 0, len(byProduct))
+	for id := range byProduct {
+		ids = append(ids, id)
+	}
+
+	prices, err := s.PriceRepo.Current(time.Now(), supplierID, ids)
+	if err != nil {
+		return err
+	}
+	for i := range prices {
+		unitCost := roundMoney(prices[i].UnitCost())
+		byProduct[prices[i].ProductID].ListPrice = &unitCost
+	}
+	return nil
+}
@@ -1,74 +1,874 @@
 package services
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
+	"fmt"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 	"grocery-store-api/models"
 	"grocery-store-api/repositories"
+	"grocery-store-api/services/errs"
+	"slices"
+	"sync"
 	"time"
+	"unicode"
+	"unicode/utf8"
 )
 
+var (
+	ErrInvalidRole    = errs.NewValidation("invalid_role", "недопустимая роль")
+	ErrUsernameTaken  = errs.NewConflict("username_taken", "имя пользователя уже занято")
+	ErrUserDisabled   = errs.NewUnauthorized("user_disabled", "учетная запись отключена")
+	ErrTokenRevoked   = errs.NewUnauthorized("token_revoked", "сеанс завершен, войдите заново")
+	ErrSelfLockout    = errs.NewForbidden("self_lockout", "нельзя отключить или понизить собственную учетную запись")
+	ErrBootstrapEmpty = errors.New("не задано имя первого администратора")
+
+	ErrInvalidCredentials = errs.NewUnauthorized("invalid_credentials", "неверное имя пользователя или пароль")
+	ErrTooManyAttempts    = errs.NewTooManyRequests("too_many_attempts", "слишком много неудачных попыток входа, попробуйте позже")
+	ErrWeakPassword       = errs.NewValidation("weak_password", "пароль не соответствует требованиям")
+
+	ErrInvalidPermission = errs.NewValidation("invalid_permission", "неизвестное разрешение")
+	ErrAdminLockout      = errs.NewForbidden("admin_lockout", "нельзя лишить роль администратора права управления ролями")
+
+	ErrOutOfScope = errs.NewForbidden("out_of_scope", "нет доступа к отделу")
+
+	ErrArchived    = errs.NewConflict("archived", "запись находится в архиве")
+	ErrNotArchived = errs.NewConflict("not_archived", "запись не находится в архиве")
+
+	ErrUserNotFound       = errs.NewNotFound("user_not_found", "пользователь не найден")
+	ErrProductNotFound    = errs.NewNotFound("product_not_found", "товар не найден")
+	ErrDepartmentNotFound = errs.NewNotFound("department_not_found", "отдел не найден")
+	ErrSupplierNotFound   = errs.NewNotFound("supplier_not_found", "поставщик не найден")
+	ErrSaleNotFound       = errs.NewNotFound("sale_not_found", "продажа не найдена")
+	ErrSupplyNotFound     = errs.NewNotFound("supply_not_found", "поставка не найдена")
+
+	// Ссылки на несуществующие или архивные записи в теле запроса — ошибка
+	// валидации, а не 404: сам запрошенный ресурс при этом может существовать
+	ErrUnknownDepartment = errs.NewValidation("unknown_department", "указанный отдел не существует или находится в архиве")
+	ErrUnknownSupplier   = errs.NewValidation("unknown_supplier", "указанный поставщик не существует или находится в архиве")
+	ErrUnknownProduct    = errs.NewValidation("unknown_product", "указанный товар не существует")
+
+	ErrDepartmentHasProducts = errs.NewConflict("department_has_products", "в отделе есть активные товары")
+	ErrSupplierHasProducts   = errs.NewConflict("supplier_has_products", "у поставщика есть активные товары")
+
+	ErrOutOfStock = errs.NewConflict("insufficient_stock", "остатка товара недостаточно для продажи")
+
+	// Просроченный срок годности нельзя задать новому товару или при его
+	// изменении, но уже просроченный товар остается доступным для правки
+	ErrExpiryInPast = errs.NewFieldValidation("validation_failed", "ошибка в данных запроса", []errs.FieldError{{
+		Field:   "expiry_date",
+		Rule:    "not_past",
+		Message: "дата не может быть в прошлом",
+	}})
+
+	ErrVersionMismatch = errs.New(errs.PreconditionFailed, "version_mismatch", "запись была изменена другим пользователем, обновите данные и повторите")
+)
+
+// notFound заменяет gorm.ErrRecordNotFound на ошибку предметной области,
+// остальные ошибки возвращает без изменений.
+func notFound(err error, domainErr error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return domainErr
+	}
+	return err
+}
+
+// checkVersion сравнивает версию, на которую опирается клиент, с текущей.
+// Нулевая версия означает, что клиент ее не передал.
+func checkVersion(expected, current uint) error {
+	if expected != 0 && expected != current {
+		return ErrVersionMismatch
+	}
+	return nil
+}
+
+// staleVersion заменяет ошибку устаревшей версии из репозитория на ошибку
+// предметной области.
+func staleVersion(err error) error {
+	if errors.Is(err, repositories.ErrStaleVersion) {
+		return ErrVersionMismatch
+	}
+	return err
+}
+
+// Actor — пользователь, от имени которого выполняется операция, и
+// параметры запроса, которые попадают в журнал изменений.
+type Actor struct {
+	UserID       uint
+	Username     string
+	Role         string
+	DepartmentID *uint
+	IP           string
+	RequestID    string
+}
+
+// systemActor используется для изменений, которые выполняет сам сервер.
+var systemActor = Actor{Username: "system"}
+
+// DepartmentScope определяет, с какими отделами может работать пользователь.
+// Администратор имеет доступ ко всем отделам, остальные — только к тем,
+// где они указаны руководителем (Department.ManagerID), и к отделу, в
+// котором работают (User.DepartmentID).
+type DepartmentScope struct {
+	DepartmentRepo repositories.DepartmentRepository
+}
+
+// Departments возвращает ID доступных отделов. Если all == true, доступ
+// не ограничен и список не используется.
+func (s *DepartmentScope) Departments(actor Actor) (ids []uint, all bool, err error) {
+	if actor.Role == models.RoleAdmin {
+		return nil, true, nil
+	}
+
+	departments, err := s.DepartmentRepo.FindByManager(actor.UserID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	ids = make([]uint, 0, len(departments)+1)
+	for _, department := range departments {
+		ids = append(ids, department.ID)
+	}
+	if actor.DepartmentID != nil && !slices.Contains(ids, *actor.DepartmentID) {
+		ids = append(ids, *actor.DepartmentID)
+	}
+
+	return ids, false, nil
+}
+
+func (s *DepartmentScope) Check(actor Actor, departmentID uint) error {
+	ids, all, err := s.Departments(actor)
+	if err != nil || all {
+		return err
+	}
+
+	for _, id := range ids {
+		if id == departmentID {
+			return nil
+		}
+	}
+
+	return ErrOutOfScope
+}
+
+// PasswordPolicy задает требования к паролям, которые проверяются при
+// регистрации и сбросе пароля.
+type PasswordPolicy struct {
+	MinLength      int
+	RequireLetter  bool
+	RequireDigit   bool
+	RequireUpper   bool
+	RequireSpecial bool
+}
+
+var DefaultPasswordPolicy = PasswordPolicy{
+	MinLength:     8,
+	RequireLetter: true,
+	RequireDigit:  true,
+}
+
+func (p PasswordPolicy) Validate(password string) error {
+	if utf8.RuneCountInString(password) < p.MinLength {
+		return fmt.Errorf("%w: минимальная длина %d символов", ErrWeakPassword, p.MinLength)
+	}
+
+	var hasLetter, hasDigit, hasUpper, hasSpecial bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLetter(r):
+			hasLetter = true
+			if unicode.IsUpper(r) {
+				hasUpper = true
+			}
+		case unicode.IsDigit(r):
+			hasDigit = true
+		default:
+			hasSpecial = true
+		}
+	}
+
+	switch {
+	case p.RequireLetter && !hasLetter:
+		return fmt.Errorf("%w: нужна хотя бы одна буква", ErrWeakPassword)
+	case p.RequireDigit && !hasDigit:
+		return fmt.Errorf("%w: нужна хотя бы одна цифра", ErrWeakPassword)
+	case p.RequireUpper && !hasUpper:
+		return fmt.Errorf("%w: нужна хотя бы одна заглавная буква", ErrWeakPassword)
+	case p.RequireSpecial && !hasSpecial:
+		return fmt.Errorf("%w: нужен хотя бы один специальный символ", ErrWeakPassword)
+	}
+
+	return nil
+}
+
+// LoginPolicy задает ограничения на неудачные попытки входа.
+type LoginPolicy struct {
+	// MaxUserFailures — число неудачных попыток подряд, после которого
+	// учетная запись блокируется на LockoutDuration.
+	MaxUserFailures int
+	// MaxIPFailures — число неудачных попыток с одного IP за IPWindow,
+	// после которого вход с этого адреса блокируется на LockoutDuration.
+	MaxIPFailures   int
+	IPWindow        time.Duration
+	LockoutDuration time.Duration
+}
+
+var DefaultLoginPolicy = LoginPolicy{
+	MaxUserFailures: 5,
+	MaxIPFailures:   20,
+	IPWindow:        15 * time.Minute,
+	LockoutDuration: 15 * time.Minute,
+}
+
+type ipAttempts struct {
+	failures    int
+	windowStart time.Time
+	lockedUntil time.Time
+}
+
+// LoginThrottle учитывает неудачные попытки входа по IP-адресам в памяти
+// процесса. Счетчики по имени пользователя хранятся в models.User.
+type LoginThrottle struct {
+	Policy LoginPolicy
+
+	mu       sync.Mutex
+	attempts map[string]*ipAttempts
+}
+
+func NewLoginThrottle(policy LoginPolicy) *LoginThrottle {
+	return &LoginThrottle{Policy: policy, attempts: make(map[string]*ipAttempts)}
+}
+
+func (t *LoginThrottle) Blocked(ip string, now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	a, ok := t.attempts[ip]
+	return ok && now.Before(a.lockedUntil)
+}
+
+func (t *LoginThrottle) RecordFailure(ip string, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// Удаляем устаревшие записи, чтобы карта не росла бесконечно
+	for key, a := range t.attempts {
+		if now.Sub(a.windowStart) > t.Policy.IPWindow && now.After(a.lockedUntil) {
+			delete(t.attempts, key)
+		}
+	}
+
+	a, ok := t.attempts[ip]
+	if !ok || now.Sub(a.windowStart) > t.Policy.IPWindow {
+		a = &ipAttempts{windowStart: now}
+		t.attempts[ip] = a
+	}
+
+	a.failures++
+	if a.failures >= t.Policy.MaxIPFailures {
+		a.lockedUntil = now.Add(t.Policy.LockoutDuration)
+		a.failures = 0
+		a.windowStart = now
+	}
+}
+
 type UserService struct {
-	Repo repositories.UserRepository
+	Repo           repositories.UserRepository
+	DepartmentRepo repositories.DepartmentRepository
+	PasswordPolicy PasswordPolicy
+	Throttle       *LoginThrottle
+	Audit          AuditService
 }
 
-func (s *UserService) Register(req models.RegisterRequest) (*models.User, error) {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+func (s *UserService) Register(actor Actor, username, password, role string) (*models.User, error) {
+	if err := s.PasswordPolicy.Validate(password); err != nil {
+		return nil, err
+	}
+
+	return s.createUser(actor, username, password, role)
+}
+
+func (s *UserService) createUser(actor Actor, username, password, role string) (*models.User, error) {
+	if !models.IsValidRole(role) {
+		return nil, ErrInvalidRole
+	}
+
+	if _, err := s.Repo.FindByUsername(username); err == nil {
+		return nil, ErrUsernameTaken
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
 		return nil, err
 	}
 
 	user := &models.User{
-		Username:  req.Username,
+		Username:  username,
 		Password:  string(hashedPassword),
-		Role:      req.Role,
+		Role:      role,
 		CreatedAt: time.Now(),
 	}
 
-	err = s.Repo.Create(user)
-	return user, err
+	// Проверка выше не защищает от параллельного создания: такой же
+	// логин, добавленный между ней и вставкой, отклонит уникальный индекс
+	if err := s.Repo.Create(user); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrUsernameTaken
+		}
+		return nil, err
+	}
+
+	s.Audit.Record(actor, models.AuditCreate, AuditEntityUser, user.ID, nil, user)
+	return user, nil
 }
 
-func (s *UserService) Login(req models.LoginRequest) (*models.User, error) {
-	user, err := s.Repo.FindByUsername(req.Username)
+// EnsureBootstrapAdmin создает первого администратора, если в базе еще нет
+// ни одного пользователя. Если пароль не задан, он генерируется и
+// возвращается вызывающему, чтобы его можно было вывести один раз в лог;
+// для заданного пароля возвращается пустая строка.
+func (s *UserService) EnsureBootstrapAdmin(username, password string) (*models.User, string, error) {
+	count, err := s.Repo.Count()
+	if err != nil || count > 0 {
+		return nil, "", err
+	}
+
+	if username == "" {
+		return nil, "", ErrBootstrapEmpty
+	}
+
+	if password != "" {
+		// Заданный оператором пароль ему известен и не возвращается
+		user, err := s.Register(systemActor, username, password, models.RoleAdmin)
+		return user, "", err
+	}
+
+	// Сгенерированный пароль случаен и не проверяется политикой
+	buf := make([]byte, 12)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, "", err
+	}
+	generated := hex.EncodeToString(buf)
+	user, err := s.createUser(systemActor, username, generated, models.RoleAdmin)
 	if err != nil {
+		return nil, "", err
+	}
+	return user, generated, nil
+}
+
+// dummyPasswordHash используется для сравнения, когда пользователь не найден,
+// чтобы время ответа не выдавало существование имени пользователя.
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-password"), bcrypt.DefaultCost)
+
+func (s *UserService) Login(username, password, ip string) (*models.User, error) {
+	now := time.Now()
+	if s.Throttle.Blocked(ip, now) {
+		return nil, ErrTooManyAttempts
+	}
+
+	user, err := s.Repo.FindByUsername(username)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+		s.Throttle.RecordFailure(ip, now)
+		return nil, ErrInvalidCredentials
+	} else if err != nil {
 		return nil, err
 	}
 
-	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password))
+	// Заблокированный пользователь получает тот же ответ, что и
+	// несуществующий, иначе по ответу можно перебирать имена
+	if user.LockedUntil != nil && now.Before(*user.LockedUntil) {
+		bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+		s.Throttle.RecordFailure(ip, now)
+		return nil, ErrInvalidCredentials
+	}
+
+	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
 	if err != nil {
-		return nil, errors.New("неверный пароль")
+		s.Throttle.RecordFailure(ip, now)
+
+		lockUntil := now.Add(s.Throttle.Policy.LockoutDuration)
+		if err := s.Repo.RecordLoginFailure(user.ID, s.Throttle.Policy.MaxUserFailures, lockUntil); err != nil {
+			return nil, err
+		}
+
+		return nil, ErrInvalidCredentials
+	}
+
+	if user.Disabled {
+		return nil, ErrUserDisabled
 	}
 
+	if err := s.Repo.RecordLoginSuccess(user.ID, now); err != nil {
+		return nil, err
+	}
+
+	user.FailedLoginAttempts = 0
+	user.LockedUntil = nil
+	user.LastLoginAt = &now
+	return user, nil
+}
+
+// Authenticate возвращает пользователя, которому выдан токен с версией
+// tokenVersion. Роль берется из базы, а не из токена, поэтому смена роли и
+// отключение действуют сразу; токены, выданные до сброса пароля или
+// отключения, не принимаются.
+func (s *UserService) Authenticate(id, tokenVersion uint) (*models.User, error) {
+	user, err := s.Repo.FindByID(id)
+	if err != nil {
+		return nil, notFound(err, ErrTokenRevoked)
+	}
+	if user.Disabled {
+		return nil, ErrUserDisabled
+	}
+	if user.TokenVersion != tokenVersion {
+		return nil, ErrTokenRevoked
+	}
 	return user, nil
 }
 
 func (s *UserService) GetUserByID(id uint) (*models.User, error) {
-	return s.Repo.FindByID(id)
+	user, err := s.Repo.FindByID(id)
+	return user, notFound(err, ErrUserNotFound)
+}
+
+func (s *UserService) GetAllUsers() ([]models.User, error) {
+	return s.Repo.FindAll()
+}
+
+func (s *UserService) ChangeRole(actor Actor, id uint, role string) (*models.User, error) {
+	if !models.IsValidRole(role) {
+		return nil, ErrInvalidRole
+	}
+
+	user, err := s.Repo.FindByID(id)
+	if err != nil {
+		return nil, notFound(err, ErrUserNotFound)
+	}
+
+	if user.ID == actor.UserID && role != models.RoleAdmin {
+		return nil, ErrSelfLockout
+	}
+
+	updated, err := s.updateUser(id, map[string]interface{}{"role": role})
+	if err != nil {
+		return nil, err
+	}
+
+	s.Audit.Record(actor, "change_role", AuditEntityUser, id, user, updated)
+	return updated, nil
+}
+
+// SetDepartment прикрепляет сотрудника к отделу; nil открепляет его.
+func (s *UserService) SetDepartment(actor Actor, id uint, departmentID *uint) (*models.User, error) {
+	user, err := s.Repo.FindByID(id)
+	if err != nil {
+		return nil, notFound(err, ErrUserNotFound)
+	}
+
+	if departmentID != nil {
+		if _, err := s.DepartmentRepo.FindByID(*departmentID); err != nil {
+			return nil, notFound(err, ErrDepartmentNotFound)
+		}
+	}
+
+	updated, err := s.updateUser(id, map[string]interface{}{"department_id": departmentID})
+	if err != nil {
+		return nil, err
+	}
+
+	s.Audit.Record(actor, "change_department", AuditEntityUser, id, user, updated)
+	return updated, nil
+}
+
+func (s *UserService) ResetPassword(actor Actor, id uint, password string) (*models.User, error) {
+	if err := s.PasswordPolicy.Validate(password); err != nil {
+		return nil, err
+	}
+
+	user, err := s.Repo.FindByID(id)
+	if err != nil {
+		return nil, notFound(err, ErrUserNotFound)
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	// Сброс пароля администратором также снимает блокировку входа и
+	// отзывает выданные токены
+	updated, err := s.updateUser(id, map[string]interface{}{
+		"password":              string(hashedPassword),
+		"failed_login_attempts": 0,
+		"locked_until":          nil,
+		"token_version":         gorm.Expr("token_version + 1"),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.Audit.Record(actor, "reset_password", AuditEntityUser, id, user, updated)
+	return updated, nil
+}
+
+func (s *UserService) SetDisabled(actor Actor, id uint, disabled bool) (*models.User, error) {
+	user, err := s.Repo.FindByID(id)
+	if err != nil {
+		return nil, notFound(err, ErrUserNotFound)
+	}
+
+	if user.ID == actor.UserID && disabled {
+		return nil, ErrSelfLockout
+	}
+
+	fields := map[string]interface{}{"disabled": disabled}
+	if disabled {
+		fields["token_version"] = gorm.Expr("token_version + 1")
+	}
+	updated, err := s.updateUser(id, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	action := "enable"
+	if disabled {
+		action = "disable"
+	}
+	s.Audit.Record(actor, action, AuditEntityUser, id, user, updated)
+	return updated, nil
+}
+
+// updateUser меняет столбцы fields пользователя и возвращает его
+// обновленную запись.
+func (s *UserService) updateUser(id uint, fields map[string]interface{}) (*models.User, error) {
+	if err := s.Repo.UpdateFields(id, fields); err != nil {
+		return nil, err
+	}
+	user, err := s.Repo.FindByID(id)
+	return user, notFound(err, ErrUserNotFound)
+}
+
+type PermissionService struct {
+	Repo  repositories.RolePermissionRepository
+	Audit AuditService
+}
+
+// SyncPermissions выдает ролям по умолчанию разрешения, которых база еще
+// не видела (первый запуск или новая версия). Уже известные разрешения не
+// трогаются, чтобы не перезаписывать настройки администратора.
+func (s *PermissionService) SyncPermissions() error {
+	known, err := s.Repo.FindKnownPermissions()
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool, len(known))
+	for _, permission := range known {
+		seen[permission.Name] = true
+	}
+
+	for _, permission := range models.Permissions {
+		if seen[permission] {
+			continue
+		}
+
+		var roles []string
+		for _, role := range models.Roles {
+			for _, p := range models.DefaultRolePermissions[role] {
+				if p == permission {
+					roles = append(roles, role)
+					break
+				}
+			}
+		}
+
+		if err := s.Repo.AddPermission(permission, roles); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// HasPermissions проверяет, что роли выданы все перечисленные разрешения.
+func (s *PermissionService) HasPermissions(role string, permissions ...string) (bool, error) {
+	if len(permissions) == 0 {
+		return true, nil
+	}
+
+	count, err := s.Repo.CountMatching(role, permissions)
+	if err != nil {
+		return false, err
+	}
+
+	return count == int64(len(permissions)), nil
+}
+
+func (s *PermissionService) GetRolePermissions() ([]models.RolePermissions, error) {
+	rows, err := s.Repo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	byRole := make(map[string][]string)
+	for _, row := range rows {
+		byRole[row.Role] = append(byRole[row.Role], row.Permission)
+	}
+
+	result := make([]models.RolePermissions, 0, len(models.Roles))
+	for _, role := range models.Roles {
+		permissions := byRole[role]
+		if permissions == nil {
+			permissions = []string{}
+		}
+		result = append(result, models.RolePermissions{Role: role, Permissions: permissions})
+	}
+
+	return result, nil
+}
+
+func (s *PermissionService) SetRolePermissions(actor Actor, role string, permissions []string) (*models.RolePermissions, error) {
+	if !models.IsValidRole(role) {
+		return nil, ErrInvalidRole
+	}
+
+	hasRoleManage := false
+	seen := make(map[string]bool)
+	unique := make([]string, 0, len(permissions))
+	for _, permission := range permissions {
+		if !models.IsValidPermission(permission) {
+			return nil, ErrInvalidPermission
+		}
+		if permission == models.PermRoleManage {
+			hasRoleManage = true
+		}
+		if !seen[permission] {
+			seen[permission] = true
+			unique = append(unique, permission)
+		}
+	}
+	permissions = unique
+
+	if role == models.RoleAdmin && !hasRoleManage {
+		return nil, ErrAdminLockout
+	}
+
+	rows, err := s.Repo.FindByRole(role)
+	if err != nil {
+		return nil, err
+	}
+
+	before := models.RolePermissions{Role: role, Permissions: make([]string, 0, len(rows))}
+	for _, row := range rows {
+		before.Permissions = append(before.Permissions, row.Permission)
+	}
+
+	if err := s.Repo.ReplaceForRole(role, permissions); err != nil {
+		return nil, err
+	}
+
+	after := &models.RolePermissions{Role: role, Permissions: permissions}
+	s.Audit.Record(actor, models.AuditUpdate, AuditEntityRolePermissions, 0, &before, after)
+	return after, nil
 }
 
 type ProductService struct {
-	Repo repositories.ProductRepository
+	Repo           repositories.ProductRepository
+	DepartmentRepo repositories.DepartmentRepository
+	SupplierRepo   repositories.SupplierRepository
+	Scope          DepartmentScope
+	Audit          AuditService
 }
 
-func (s *ProductService) CreateProduct(product *models.Product) error {
-	return s.Repo.Create(product)
+// checkReferences проверяет, что отдел и поставщик товара существуют и не
+// находятся в архиве. Нулевой ID не проверяется: при обновлении он означает,
+// что поле не меняется.
+func (s *ProductService) checkReferences(departmentID, supplierID uint) error {
+	if departmentID != 0 {
+		if _, err := s.DepartmentRepo.FindByID(departmentID); err != nil {
+			return notFound(err, ErrUnknownDepartment)
+		}
+	}
+
+	if supplierID != 0 {
+		if _, err := s.SupplierRepo.FindByID(supplierID); err != nil {
+			return notFound(err, ErrUnknownSupplier)
+		}
+	}
+
+	return nil
+}
+
+func (s *ProductService) CreateProduct(actor Actor, product *models.Product) error {
+	if product.DepartmentID == 0 {
+		return ErrUnknownDepartment
+	}
+	if product.SupplierID == 0 {
+		return ErrUnknownSupplier
+	}
+
+	if err := s.checkReferences(product.DepartmentID, product.SupplierID); err != nil {
+		return err
+	}
+
+	if expiryInPast(product.ExpiryDate) {
+		return ErrExpiryInPast
+	}
+
+	if err := s.Scope.Check(actor, product.DepartmentID); err != nil {
+		return err
+	}
+
+	product.Version = 1
+	if err := s.Repo.Create(product); err != nil {
+		return err
+	}
+
+	s.Audit.Record(actor, models.AuditCreate, AuditEntityProduct, product.ID, nil, product)
+	return nil
+}
+
+// expiryInPast сообщает, что срок годности раньше сегодняшнего дня.
+func expiryInPast(date time.Time) bool {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	return date.Before(today)
 }
 
 func (s *ProductService) GetProductByID(id uint) (*models.Product, error) {
-	return s.Repo.FindByID(id)
+	product, err := s.Repo.FindByID(id)
+	return product, notFound(err, ErrProductNotFound)
+}
+
+// GetProductsByIDs возвращает активные товары в порядке ids. Если
+// какого-то товара нет, возвращается ErrUnknownProduct.
+func (s *ProductService) GetProductsByIDs(ids []uint) ([]models.Product, error) {
+	products, err := s.Repo.FindByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uint]models.Product, len(products))
+	for _, product := range products {
+		byID[product.ID] = product
+	}
+
+	ordered := make([]models.Product, 0, len(ids))
+	for _, id := range ids {
+		product, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("%w: %d", ErrUnknownProduct, id)
+		}
+		ordered = append(ordered, product)
+	}
+	return ordered, nil
 }
 
 func (s *ProductService) GetAllProducts() ([]models.Product, error) {
 	return s.Repo.FindAll()
 }
 
-func (s *ProductService) UpdateProduct(product *models.Product) error {
-	return s.Repo.Update(product)
+// UpdateProduct заменяет все поля товара. version — версия, которую видел
+// клиент (0, если не передана); при расхождении возвращается ErrVersionMismatch.
+func (s *ProductService) UpdateProduct(actor Actor, product *models.Product, version uint) error {
+	existing, err := s.Repo.FindByID(product.ID)
+	if err != nil {
+		return notFound(err, ErrProductNotFound)
+	}
+
+	if err := checkVersion(version, existing.Version); err != nil {
+		return err
+	}
+
+	if err := s.Scope.Check(actor, existing.DepartmentID); err != nil {
+		return err
+	}
+
+	// Перенос товара в другой отдел тоже должен оставаться в пределах доступа
+	if product.DepartmentID != 0 && product.DepartmentID != existing.DepartmentID {
+		if err := s.Scope.Check(actor, product.DepartmentID); err != nil {
+			return err
+		}
+	}
+
+	if err := s.checkReferences(product.DepartmentID, product.SupplierID); err != nil {
+		return err
+	}
+
+	if !product.ExpiryDate.Equal(existing.ExpiryDate) && expiryInPast(product.ExpiryDate) {
+		return ErrExpiryInPast
+	}
+
+	if err := s.Repo.Update(product, existing.Version); err != nil {
+		return staleVersion(err)
+	}
+
+	updated, err := s.Repo.FindByID(product.ID)
+	if err != nil {
+		return err
+	}
+
+	s.Audit.Record(actor, models.AuditUpdate, AuditEntityProduct, product.ID, existing, updated)
+	*product = *updated
+	return nil
+}
+
+func (s *ProductService) DeleteProduct(actor Actor, id uint) error {
+	existing, err := s.Repo.FindByID(id)
+	if err != nil {
+		return notFound(err, ErrProductNotFound)
+	}
+
+	if err := s.Scope.Check(actor, existing.DepartmentID); err != nil {
+		return err
+	}
+
+	if err := s.Repo.Delete(id); err != nil {
+		return err
+	}
+
+	s.Audit.Record(actor, models.AuditDelete, AuditEntityProduct, id, existing, nil)
+	return nil
+}
+
+func (s *ProductService) GetArchivedProducts() ([]models.Product, error) {
+	return s.Repo.FindArchived()
 }
 
-func (s *ProductService) DeleteProduct(id uint) error {
-	return s.Repo.Delete(id)
+func (s *ProductService) RestoreProduct(actor Actor, id uint) (*models.Product, error) {
+	existing, err := s.Repo.FindArchivedByID(id)
+	if err != nil {
+		return nil, notFound(err, ErrProductNotFound)
+	}
+
+	if !existing.DeletedAt.Valid {
+		return nil, ErrNotArchived
+	}
+
+	if err := s.Scope.Check(actor, existing.DepartmentID); err != nil {
+		return nil, err
+	}
+
+	// Товар нельзя вернуть в архивный отдел или к архивному поставщику
+	if err := s.checkReferences(existing.DepartmentID, existing.SupplierID); err != nil {
+		return nil, err
+	}
+
+	if err := s.Repo.Restore(id); err != nil {
+		return nil, err
+	}
+
+	restored, err := s.Repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.Audit.Record(actor, "restore", AuditEntityProduct, id, existing, restored)
+	return restored, nil
 }
 
 func (s *ProductService) GetProductsByDepartment(departmentID uint) ([]models.Product, error) {
@@ -79,74 +879,352 @@ func (s *ProductService) GetProductsBySupplier(supplierID uint) ([]models.Produc
 	return s.Repo.FindBySupplier(supplierID)
 }
 
+func (s *ProductService) GetLowStockProducts(actor Actor) ([]models.Product, error) {
+	ids, all, err := s.Scope.Departments(actor)
+	if err != nil {
+		return nil, err
+	}
+
+	var products []models.Product
+	if all {
+		products, err = s.Repo.FindAll()
+	} else {
+		products, err = s.Repo.FindByDepartments(ids)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var lowStockProducts []models.Product
+	for _, product := range products {
+		if product.CurrentQty <= product.MinThreshold {
+			lowStockProducts = append(lowStockProducts, product)
+		}
+	}
+
+	return lowStockProducts, nil
+}
+
 func (s *ProductService) UpdateStock(id uint, quantity int) error {
 	return s.Repo.UpdateStock(id, quantity)
 }
 
 type DepartmentService struct {
-	Repo repositories.DepartmentRepository
+	Repo        repositories.DepartmentRepository
+	ProductRepo repositories.ProductRepository
+	Scope       DepartmentScope
+	Audit       AuditService
 }
 
-func (s *DepartmentService) CreateDepartment(department *models.Department) error {
-	return s.Repo.Create(department)
+func (s *DepartmentService) CreateDepartment(actor Actor, department *models.Department) error {
+	// Назначать руководителя другого пользователя может только администратор
+	if actor.Role != models.RoleAdmin && department.ManagerID != 0 && department.ManagerID != actor.UserID {
+		return ErrOutOfScope
+	}
+
+	department.Version = 1
+	if err := s.Repo.Create(department); err != nil {
+		return err
+	}
+
+	s.Audit.Record(actor, models.AuditCreate, AuditEntityDepartment, department.ID, nil, department)
+	return nil
 }
 
 func (s *DepartmentService) GetDepartmentByID(id uint) (*models.Department, error) {
-	return s.Repo.FindByID(id)
+	department, err := s.Repo.FindByID(id)
+	return department, notFound(err, ErrDepartmentNotFound)
 }
 
 func (s *DepartmentService) GetAllDepartments() ([]models.Department, error) {
 	return s.Repo.FindAll()
 }
 
-func (s *DepartmentService) UpdateDepartment(department *models.Department) error {
-	return s.Repo.Update(department)
+func (s *DepartmentService) UpdateDepartment(actor Actor, department *models.Department, version uint) error {
+	existing, err := s.Repo.FindByID(department.ID)
+	if err != nil {
+		return notFound(err, ErrDepartmentNotFound)
+	}
+
+	if err := checkVersion(version, existing.Version); err != nil {
+		return err
+	}
+
+	if err := s.Scope.Check(actor, existing.ID); err != nil {
+		return err
+	}
+
+	// Поля заменяются целиком, поэтому снять руководителя (ManagerID = 0)
+	// тоже может только администратор
+	if actor.Role != models.RoleAdmin && department.ManagerID != existing.ManagerID {
+		return ErrOutOfScope
+	}
+
+	if err := s.Repo.Update(department, existing.Version); err != nil {
+		return staleVersion(err)
+	}
+
+	updated, err := s.Repo.FindByID(department.ID)
+	if err != nil {
+		return err
+	}
+
+	s.Audit.Record(actor, models.AuditUpdate, AuditEntityDepartment, department.ID, existing, updated)
+	*department = *updated
+	return nil
+}
+
+func (s *DepartmentService) GetArchivedDepartments() ([]models.Department, error) {
+	return s.Repo.FindArchived()
 }
 
-func (s *DepartmentService) DeleteDepartment(id uint) error {
-	return s.Repo.Delete(id)
+func (s *DepartmentService) RestoreDepartment(actor Actor, id uint) (*models.Department, error) {
+	existing, err := s.Repo.FindArchivedByID(id)
+	if err != nil {
+		return nil, notFound(err, ErrDepartmentNotFound)
+	}
+
+	if !existing.DeletedAt.Valid {
+		return nil, ErrNotArchived
+	}
+
+	if err := s.Scope.Check(actor, id); err != nil {
+		return nil, err
+	}
+
+	if err := s.Repo.Restore(id); err != nil {
+		return nil, err
+	}
+
+	restored, err := s.Repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.Audit.Record(actor, "restore", AuditEntityDepartment, id, existing, restored)
+	return restored, nil
+}
+
+func (s *DepartmentService) DeleteDepartment(actor Actor, id uint) error {
+	existing, err := s.Repo.FindByID(id)
+	if err != nil {
+		return notFound(err, ErrDepartmentNotFound)
+	}
+
+	if err := s.Scope.Check(actor, id); err != nil {
+		return err
+	}
+
+	// Отдел с активными товарами архивировать нельзя: товары остались бы
+	// привязаны к архивной записи
+	count, err := s.ProductRepo.CountByDepartment(id)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrDepartmentHasProducts
+	}
+
+	if err := s.Repo.Delete(id); err != nil {
+		return err
+	}
+
+	s.Audit.Record(actor, models.AuditDelete, AuditEntityDepartment, id, existing, nil)
+	return nil
 }
 
 type SupplierService struct {
-	Repo repositories.SupplierRepository
+	Repo        repositories.SupplierRepository
+	ProductRepo repositories.ProductRepository
+	Audit       AuditService
 }
 
-func (s *SupplierService) CreateSupplier(supplier *models.Supplier) error {
-	return s.Repo.Create(supplier)
+func (s *SupplierService) CreateSupplier(actor Actor, supplier *models.Supplier) error {
+	supplier.Version = 1
+	if err := s.Repo.Create(supplier); err != nil {
+		return err
+	}
+
+	s.Audit.Record(actor, models.AuditCreate, AuditEntitySupplier, supplier.ID, nil, supplier)
+	return nil
 }
 
 func (s *SupplierService) GetSupplierByID(id uint) (*models.Supplier, error) {
-	return s.Repo.FindByID(id)
+	supplier, err := s.Repo.FindByID(id)
+	return supplier, notFound(err, ErrSupplierNotFound)
 }
 
 func (s *SupplierService) GetAllSuppliers() ([]models.Supplier, error) {
 	return s.Repo.FindAll()
 }
 
-func (s *SupplierService) UpdateSupplier(supplier *models.Supplier) error {
-	return s.Repo.Update(supplier)
+func (s *SupplierService) UpdateSupplier(actor Actor, supplier *models.Supplier, version uint) error {
+	existing, err := s.Repo.FindByID(supplier.ID)
+	if err != nil {
+		return notFound(err, ErrSupplierNotFound)
+	}
+
+	if err := checkVersion(version, existing.Version); err != nil {
+		return err
+	}
+
+	if err := s.Repo.Update(supplier, existing.Version); err != nil {
+		return staleVersion(err)
+	}
+
+	updated, err := s.Repo.FindByID(supplier.ID)
+	if err != nil {
+		return err
+	}
+
+	s.Audit.Record(actor, models.AuditUpdate, AuditEntitySupplier, supplier.ID, existing, updated)
+	*supplier = *updated
+	return nil
 }
 
-func (s *SupplierService) DeleteSupplier(id uint) error {
-	return s.Repo.Delete(id)
+func (s *SupplierService) GetArchivedSuppliers() ([]models.Supplier, error) {
+	return s.Repo.FindArchived()
+}
+
+func (s *SupplierService) RestoreSupplier(actor Actor, id uint) (*models.Supplier, error) {
+	existing, err := s.Repo.FindArchivedByID(id)
+	if err != nil {
+		return nil, notFound(err, ErrSupplierNotFound)
+	}
+
+	if !existing.DeletedAt.Valid {
+		return nil, ErrNotArchived
+	}
+
+	if err := s.Repo.Restore(id); err != nil {
+		return nil, err
+	}
+
+	restored, err := s.Repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.Audit.Record(actor, "restore", AuditEntitySupplier, id, existing, restored)
+	return restored, nil
+}
+
+func (s *SupplierService) DeleteSupplier(actor Actor, id uint) error {
+	existing, err := s.Repo.FindByID(id)
+	if err != nil {
+		return notFound(err, ErrSupplierNotFound)
+	}
+
+	count, err := s.ProductRepo.CountBySupplier(id)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrSupplierHasProducts
+	}
+
+	if err := s.Repo.Delete(id); err != nil {
+		return err
+	}
+
+	s.Audit.Record(actor, models.AuditDelete, AuditEntitySupplier, id, existing, nil)
+	return nil
 }
 
 type SaleService struct {
 	Repo        repositories.SaleRepository
 	ProductRepo repositories.ProductRepository
+	ShiftRepo   repositories.ShiftRepository
+	Scope       DepartmentScope
+	Audit       AuditService
+	Fiscal      FiscalService
+	Payments    PaymentService
+	Customers   CustomerService
 }
 
-func (s *SaleService) CreateSale(sale *models.Sale) error {
-	err := s.Repo.Create(sale)
+func (s *SaleService) CreateSale(actor Actor, sale *models.Sale) error {
+	product, err := s.ProductRepo.FindArchivedByID(sale.ProductID)
 	if err != nil {
+		return notFound(err, ErrUnknownProduct)
+	}
+
+	// Архивные товары остаются в истории, но продавать их нельзя
+	if product.DeletedAt.Valid {
+		return ErrArchived
+	}
+
+	// Стоимость считается по цене товара: от нее зависят сумма оплаты,
+	// начисление баллов и лимит оплаты баллами
+	sale.TotalPrice = roundMoney(product.Price * float64(sale.Quantity))
+
+	// Остаток проверяется до оплаты картой, окончательно — при сохранении
+	if product.CurrentQty < sale.Quantity {
+		return outOfStock(product)
+	}
+
+	if err := s.Customers.PrepareLoyalty(sale, &product.Department); err != nil {
 		return err
 	}
 
-	return s.ProductRepo.UpdateStock(sale.ProductID, -sale.Quantity)
+	due := roundMoney(sale.TotalPrice - float64(sale.PointsRedeemed))
+	if err := s.Payments.PreparePayments(sale, due); err != nil {
+		return err
+	}
+	if sale.PointsRedeemed > 0 {
+		amount := float64(sale.PointsRedeemed)
+		sale.Payments = append(sale.Payments, models.Payment{Method: models.PaymentPoints, Amount: amount, Tendered: amount})
+	}
+
+	// Продажа пробивается только в открытой смене кассира
+	shift, err := s.ShiftRepo.FindOpenByCashier(actor.UserID)
+	if err != nil {
+		return notFound(err, ErrShiftNotOpen)
+	}
+	sale.ShiftID = &shift.ID
+
+	if err := s.Payments.ChargeCards(shift.Register, sale.Payments); err != nil {
+		return err
+	}
+
+	err = s.Repo.Create(sale)
+	if err != nil {
+		s.Payments.CancelCards(sale.Payments)
+		var stockErr *repositories.InsufficientStockError
+		switch {
+		case errors.Is(err, repositories.ErrInsufficientPoints):
+			return ErrInsufficientPoints
+		case errors.As(err, &stockErr):
+			// Товар успели продать другие кассы
+			if current, findErr := s.ProductRepo.FindByID(product.ID); findErr == nil {
+				return outOfStock(current)
+			}
+			return ErrOutOfStock
+		}
+		return err
+	}
+
+	s.Audit.Record(actor, models.AuditCreate, AuditEntitySale, sale.ID, nil, sale)
+
+	// Для чека нужны название товара и имя кассира
+	sale.Product = *product
+	sale.Cashier = models.User{ID: actor.UserID, Username: actor.Username}
+
+	// Регистратор может отвечать долго, поэтому чек пробивается в фоне, а
+	// продажа возвращается в статусе pending. Fiscalize меняет переданную
+	// продажу, так что ему отдается копия
+	receipt := *sale
+	go s.Fiscal.Fiscalize(&receipt)
+	return nil
+}
+
+// outOfStock дополняет ErrOutOfStock названием товара и его остатком.
+func outOfStock(product *models.Product) error {
+	return errs.NewConflict(ErrOutOfStock.Code, fmt.Sprintf("%s: %q, в остатке %d", ErrOutOfStock.Message, product.Name, product.CurrentQty))
 }
 
 func (s *SaleService) GetSaleByID(id uint) (*models.Sale, error) {
-	return s.Repo.FindByID(id)
+	sale, err := s.Repo.FindByID(id)
+	return sale, notFound(err, ErrSaleNotFound)
 }
 
 func (s *SaleService) GetAllSales() ([]models.Sale, error) {
@@ -158,12 +1236,35 @@ func (s *SaleService) GetSalesByDateRange(start, end string) ([]models.Sale, err
 }
 
 type SupplyService struct {
-	Repo        repositories.SupplyRepository
-	ItemRepo    repositories.SupplyItemRepository
-	ProductRepo repositories.ProductRepository
+	Repo         repositories.SupplyRepository
+	ItemRepo     repositories.SupplyItemRepository
+	ProductRepo  repositories.ProductRepository
+	SupplierRepo repositories.SupplierRepository
+	PriceRepo    repositories.SupplierPriceRepository
+	Scope        DepartmentScope
+	Audit        AuditService
 }
 
-func (s *SupplyService) CreateSupply(supply *models.Supply, items []models.SupplyItem) error {
+func (s *SupplyService) CreateSupply(actor Actor, supply *models.Supply, items []models.SupplyItem) error {
+	if _, err := s.SupplierRepo.FindByID(supply.SupplierID); err != nil {
+		return notFound(err, ErrUnknownSupplier)
+	}
+
+	// Приход товара меняет остатки, поэтому все позиции должны относиться
+	// к отделам, доступным пользователю
+	for _, item := range items {
+		product, err := s.ProductRepo.FindArchivedByID(item.ProductID)
+		if err != nil {
+			return notFound(err, ErrUnknownProduct)
+		}
+		if product.DeletedAt.Valid {
+			return ErrArchived
+		}
+		if err := s.Scope.Check(actor, product.DepartmentID); err != nil {
+			return err
+		}
+	}
+
 	err := s.Repo.Create(supply)
 	if err != nil {
 		return err
@@ -182,13 +1283,15 @@ func (s *SupplyService) CreateSupply(supply *models.Supply, items []models.Suppl
 		}
 	}
 
+	supply.Items = items
+	s.Audit.Record(actor, models.AuditCreate, AuditEntitySupply, supply.ID, nil, supply)
 	return nil
 }
 
 func (s *SupplyService) GetSupplyByID(id uint) (*models.Supply, error) {
 	supply, err := s.Repo.FindByID(id)
 	if err != nil {
-		return nil, err
+		return nil, notFound(err, ErrSupplyNotFound)
 	}
 
 	items, err := s.ItemRepo.FindBySupplyID(id)
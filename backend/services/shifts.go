@@ -0,0 +1,273 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+
+	"grocery-store-api/models"
+	"grocery-store-api/repositories"
+	"grocery-store-api/services/errs"
+)
+
+var (
+	ErrShiftNotFound    = errs.NewNotFound("shift_not_found", "смена не найдена")
+	ErrNoOpenShift      = errs.NewNotFound("no_open_shift", "у пользователя нет открытой смены")
+	ErrShiftNotOpen     = errs.NewConflict("shift_not_open", "нет открытой смены: откройте смену перед продажей")
+	ErrShiftAlreadyOpen = errs.NewConflict("shift_already_open", "у кассира уже есть открытая смена")
+	ErrRegisterBusy     = errs.NewConflict("register_busy", "на кассе уже открыта смена")
+	ErrShiftClosed      = errs.NewConflict("shift_closed", "смена уже закрыта")
+	ErrInsufficientCash = errs.NewConflict("insufficient_cash", "в кассе недостаточно наличных для изъятия")
+	ErrNotShiftOwner    = errs.NewForbidden("not_shift_owner", "смена принадлежит другому кассиру")
+)
+
+// ShiftReport — отчет по смене: X-отчет для открытой смены и Z-отчет для
+// закрытой. Payments — суммы оплат по способам, Refunds — суммы выплат по
+// возвратам, оформленным в смене. Ожидаемая сумма наличных складывается из
+// размена на начало смены, оплат наличными (без сдачи) и внесений за
+// вычетом возвратов наличными и изъятий.
+type ShiftReport struct {
+	Shift        models.Shift
+	Final        bool
+	SalesCount   int64
+	SalesTotal   float64
+	Payments     map[string]float64
+	ReturnsCount int64
+	ReturnsTotal float64
+	Refunds      map[string]float64
+	CashIn       float64
+	CashOut      float64
+	ExpectedCash float64
+	CountedCash  *float64
+	Discrepancy  *float64
+	Movements    []models.CashMovement
+}
+
+// ShiftService управляет кассовыми сменами. Кассир работает только со
+// своей сменой; пользователи с разрешением shift.manage — с любой.
+type ShiftService struct {
+	Repo         repositories.ShiftRepository
+	MovementRepo repositories.CashMovementRepository
+	SaleRepo     repositories.SaleRepository
+	PaymentRepo  repositories.PaymentRepository
+	ReturnRepo   repositories.SaleReturnRepository
+	Permissions  PermissionService
+	Audit        AuditService
+}
+
+func (s *ShiftService) OpenShift(actor Actor, register string, openingFloat float64) (*models.Shift, error) {
+	_, err := s.Repo.FindOpenByCashier(actor.UserID)
+	if err == nil {
+		return nil, ErrShiftAlreadyOpen
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	count, err := s.Repo.CountOpenByRegister(register)
+	if err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, ErrRegisterBusy
+	}
+
+	shift := &models.Shift{
+		CashierID:    actor.UserID,
+		Register:     register,
+		OpeningFloat: openingFloat,
+		OpenedAt:     time.Now(),
+	}
+	if err := s.Repo.Create(shift); err != nil {
+		return nil, err
+	}
+
+	s.Audit.Record(actor, models.AuditCreate, AuditEntityShift, shift.ID, nil, shift)
+	shift.Cashier = models.User{ID: actor.UserID, Username: actor.Username}
+	return shift, nil
+}
+
+// GetCurrentShift возвращает открытую смену пользователя.
+func (s *ShiftService) GetCurrentShift(actor Actor) (*models.Shift, error) {
+	shift, err := s.Repo.FindOpenByCashier(actor.UserID)
+	return shift, notFound(err, ErrNoOpenShift)
+}
+
+func (s *ShiftService) GetShift(actor Actor, id uint) (*models.Shift, error) {
+	shift, err := s.Repo.FindByID(id)
+	if err != nil {
+		return nil, notFound(err, ErrShiftNotFound)
+	}
+	if err := s.checkAccess(actor, shift); err != nil {
+		return nil, err
+	}
+	return shift, nil
+}
+
+func (s *ShiftService) GetShifts(filter models.ShiftFilter) ([]models.Shift, error) {
+	if filter.Limit <= 0 || filter.Limit > 1000 {
+		filter.Limit = 100
+	}
+	return s.Repo.Find(filter)
+}
+
+// AddCashMovement вносит наличные в кассу или изымает их. Изъять можно не
+// больше, чем должно быть в кассе.
+func (s *ShiftService) AddCashMovement(actor Actor, shiftID uint, movementType string, amount float64, reason string) (*models.CashMovement, error) {
+	shift, err := s.GetShift(actor, shiftID)
+	if err != nil {
+		return nil, err
+	}
+	if shift.ClosedAt != nil {
+		return nil, ErrShiftClosed
+	}
+
+	if movementType == models.CashOut {
+		report, err := s.buildReport(shift)
+		if err != nil {
+			return nil, err
+		}
+		if amount > report.ExpectedCash {
+			return nil, ErrInsufficientCash
+		}
+	}
+
+	movement := &models.CashMovement{
+		ShiftID:   shift.ID,
+		Type:      movementType,
+		Amount:    amount,
+		Reason:    reason,
+		CreatedBy: actor.UserID,
+	}
+	if err := s.MovementRepo.Create(movement); err != nil {
+		return nil, err
+	}
+
+	s.Audit.Record(actor, models.AuditCreate, AuditEntityCashMovement, movement.ID, nil, movement)
+	return movement, nil
+}
+
+// GetReport возвращает X-отчет открытой смены или Z-отчет закрытой.
+func (s *ShiftService) GetReport(actor Actor, shiftID uint) (*ShiftReport, error) {
+	shift, err := s.GetShift(actor, shiftID)
+	if err != nil {
+		return nil, err
+	}
+	return s.buildReport(shift)
+}
+
+// CloseShift закрывает смену с пересчитанной суммой наличных и
+// возвращает Z-отчет с расхождением.
+func (s *ShiftService) CloseShift(actor Actor, shiftID uint, countedCash float64) (*ShiftReport, error) {
+	shift, err := s.GetShift(actor, shiftID)
+	if err != nil {
+		return nil, err
+	}
+	if shift.ClosedAt != nil {
+		return nil, ErrShiftClosed
+	}
+	before := *shift
+
+	report, err := s.buildReport(shift)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	shift.ClosedAt = &now
+	shift.ExpectedCash = &report.ExpectedCash
+	shift.CountedCash = &countedCash
+	closed, err := s.Repo.Close(shift)
+	if err != nil {
+		return nil, err
+	}
+	if !closed {
+		return nil, ErrShiftClosed
+	}
+
+	s.Audit.Record(actor, models.AuditUpdate, AuditEntityShift, shift.ID, &before, shift)
+	return s.buildReport(shift)
+}
+
+func (s *ShiftService) checkAccess(actor Actor, shift *models.Shift) error {
+	if shift.CashierID == actor.UserID {
+		return nil
+	}
+
+	allowed, err := s.Permissions.HasPermissions(actor.Role, models.PermShiftManage)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return ErrNotShiftOwner
+	}
+	return nil
+}
+
+func (s *ShiftService) buildReport(shift *models.Shift) (*ShiftReport, error) {
+	sales, err := s.SaleRepo.TotalByShift(shift.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	payments, err := s.PaymentRepo.TotalsByShift(shift.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	returns, err := s.ReturnRepo.TotalByShift(shift.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	refunds, err := s.ReturnRepo.RefundTotalsByShift(shift.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	movements, err := s.MovementRepo.FindByShift(shift.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ShiftReport{
+		Shift:        *shift,
+		Final:        shift.ClosedAt != nil,
+		SalesCount:   sales.Count,
+		SalesTotal:   roundMoney(sales.Total),
+		Payments:     make(map[string]float64, len(models.PaymentMethods)),
+		ReturnsCount: returns.Count,
+		ReturnsTotal: roundMoney(returns.Total),
+		Refunds:      make(map[string]float64, len(models.PaymentMethods)),
+		Movements:    movements,
+	}
+	for _, method := range models.PaymentMethods {
+		report.Payments[method] = roundMoney(payments[method])
+		report.Refunds[method] = roundMoney(refunds[method])
+	}
+	for _, movement := range movements {
+		switch movement.Type {
+		case models.CashIn:
+			report.CashIn += movement.Amount
+		case models.CashOut:
+			report.CashOut += movement.Amount
+		}
+	}
+	report.CashIn = roundMoney(report.CashIn)
+	report.CashOut = roundMoney(report.CashOut)
+	report.ExpectedCash = roundMoney(shift.OpeningFloat + report.Payments[models.PaymentCash] - report.Refunds[models.PaymentCash] + report.CashIn - report.CashOut)
+
+	if shift.CountedCash != nil {
+		discrepancy := roundMoney(*shift.CountedCash - report.ExpectedCash)
+		report.CountedCash = shift.CountedCash
+		report.Discrepancy = &discrepancy
+	}
+	return report, nil
+}
+
+// roundMoney округляет сумму до копеек.
+func roundMoney(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,345 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"grocery-store-api/models"
+	"grocery-store-api/repositories"
+	"grocery-store-api/services/errs"
+)
+
+var (
+	ErrWriteOffNotFound       = errs.NewNotFound("write_off_not_found", "акт списания не найден")
+	ErrInvalidWriteOffReason  = errs.NewValidation("invalid_write_off_reason", "недопустимая причина списания")
+	ErrInvalidWriteOffStatus  = errs.NewValidation("invalid_write_off_status", "недопустимый статус акта списания")
+	ErrWriteOffNoteRequired   = errs.NewValidation("write_off_note_required", "для прочих списаний укажите причину в комментарии")
+	ErrDuplicateWriteOffItem  = errs.NewValidation("duplicate_write_off_item", "товар указан в акте несколько раз")
+	ErrWriteOffItemDepartment = errs.NewValidation("write_off_item_department", "товар не относится к отделу акта")
+	ErrWriteOffReviewed       = errs.NewConflict("write_off_reviewed", "акт списания уже утвержден или отклонен")
+	ErrWriteOffSelfApproval   = errs.NewForbidden("self_approval", "нельзя утвердить собственный акт списания")
+	ErrInsufficientStock      = errs.NewConflict("insufficient_stock", "остатка товара недостаточно для списания")
+)
+
+// WriteOffService ведет акты списания товаров: бой, порчу, кражи,
+// дегустации. Акт составляет сотрудник отдела, остатки уменьшаются только
+// после утверждения.
+type WriteOffService struct {
+	Repo           repositories.WriteOffRepository
+	ProductRepo    repositories.ProductRepository
+	DepartmentRepo repositories.DepartmentRepository
+	SupplyItemRepo repositories.SupplyItemRepository
+	Scope          DepartmentScope
+	Audit          AuditService
+}
+
+// CreateWriteOff составляет акт списания. Все товары должны относиться к
+// отделу акта, и списать можно не больше текущего остатка.
+func (s *WriteOffService) CreateWriteOff(actor Actor, writeOff *models.WriteOff) error {
+	if !models.IsValidWriteOffReason(writeOff.Reason) {
+		return ErrInvalidWriteOffReason
+	}
+	writeOff.Note = strings.TrimSpace(writeOff.Note)
+	if writeOff.Reason == models.WriteOffOther && writeOff.Note == "" {
+		return ErrWriteOffNoteRequired
+	}
+
+	if _, err := s.DepartmentRepo.FindByID(writeOff.DepartmentID); err != nil {
+		return notFound(err, ErrUnknownDepartment)
+	}
+	if err := s.Scope.Check(actor, writeOff.DepartmentID); err != nil {
+		return err
+	}
+
+	seen := make(map[uint]bool, len(writeOff.Items))
+	for _, item := range writeOff.Items {
+		if seen[item.ProductID] {
+			return fmt.Errorf("%w: %d", ErrDuplicateWriteOffItem, item.ProductID)
+		}
+		seen[item.ProductID] = true
+
+		product, err := s.ProductRepo.FindArchivedByID(item.ProductID)
+		if err != nil {
+			return notFound(err, fmt.Errorf("%w: %d", ErrUnknownProduct, item.ProductID))
+		}
+		if product.DeletedAt.Valid {
+			return ErrArchived
+		}
+		if product.DepartmentID != writeOff.DepartmentID {
+			return fmt.Errorf("%w: %d", ErrWriteOffItemDepartment, item.ProductID)
+		}
+		if item.Quantity > product.CurrentQty {
+			return insufficientStock(product)
+		}
+	}
+
+	writeOff.Status = models.WriteOffPending
+	writeOff.TotalCost = 0
+	writeOff.CreatedBy = actor.UserID
+	writeOff.ReviewedBy, writeOff.ReviewedAt = nil, nil
+	if err := s.Repo.Create(writeOff); err != nil {
+		return err
+	}
+
+	created, err := s.Repo.FindByID(writeOff.ID)
+	if err != nil {
+		return err
+	}
+
+	s.Audit.Record(actor, models.AuditCreate, AuditEntityWriteOff, writeOff.ID, nil, created)
+	*writeOff = *created
+	return nil
+}
+
+// GetWriteOff возвращает акт списания отдела, доступного пользователю.
+func (s *WriteOffService) GetWriteOff(actor Actor, id uint) (*models.WriteOff, error) {
+	writeOff, err := s.Repo.FindByID(id)
+	if err != nil {
+		return nil, notFound(err, ErrWriteOffNotFound)
+	}
+	if err := s.Scope.Check(actor, writeOff.DepartmentID); err != nil {
+		return nil, err
+	}
+	return writeOff, nil
+}
+
+// GetWriteOffs возвращает акты списания отделов, доступных пользователю.
+func (s *WriteOffService) GetWriteOffs(actor Actor, filter models.WriteOffFilter) ([]models.WriteOff, error) {
+	if filter.Status != "" && !models.IsValidWriteOffStatus(filter.Status) {
+		return nil, ErrInvalidWriteOffStatus
+	}
+	if filter.Reason != "" && !models.IsValidWriteOffReason(filter.Reason) {
+		return nil, ErrInvalidWriteOffReason
+	}
+	if filter.Limit <= 0 || filter.Limit > 1000 {
+		filter.Limit = 100
+	}
+
+	ids, all, err := s.Scope.Departments(actor)
+	if err != nil {
+		return nil, err
+	}
+	if !all {
+		filter.DepartmentIDs = ids
+	}
+	return s.Repo.Find(filter)
+}
+
+// ApproveWriteOff утверждает акт и уменьшает остатки. Себестоимость строк
+// считается по текущему остатку товара, см. stockCosts.
+func (s *WriteOffService) ApproveWriteOff(actor Actor, id uint) (*models.WriteOff, error) {
+	writeOff, err := s.GetWriteOff(actor, id)
+	if err != nil {
+		return nil, err
+	}
+	if writeOff.Status != models.WriteOffPending {
+		return nil, ErrWriteOffReviewed
+	}
+	// Списание утверждает не тот, кто его оформил
+	if writeOff.CreatedBy == actor.UserID {
+		return nil, ErrWriteOffSelfApproval
+	}
+
+	products := make([]models.Product, 0, len(writeOff.Items))
+	stock := make(map[uint]int64, len(writeOff.Items))
+	for _, item := range writeOff.Items {
+		products = append(products, item.Product)
+		stock[item.ProductID] = int64(item.Product.CurrentQty)
+	}
+	now := time.Now()
+	costs, err := stockCosts(s.SupplyItemRepo, products, stock, now)
+	if err != nil {
+		return nil, err
+	}
+
+	before := *writeOff
+	before.Items = append([]models.WriteOffItem(nil), writeOff.Items...)
+	writeOff.TotalCost = 0
+	for i := range writeOff.Items {
+		item := &writeOff.Items[i]
+		item.UnitCost, item.Cost = 0, 0
+		if quantity := stock[item.ProductID]; quantity > 0 {
+			item.UnitCost = roundMoney(costs[item.ProductID].value / float64(quantity))
+			item.Cost = roundMoney(item.UnitCost * float64(item.Quantity))
+		}
+		writeOff.TotalCost += item.Cost
+	}
+	writeOff.TotalCost = roundMoney(writeOff.TotalCost)
+	writeOff.Status = models.WriteOffApproved
+	writeOff.ReviewedBy, writeOff.ReviewedAt = &actor.UserID, &now
+
+	if err := s.Repo.Approve(writeOff); err != nil {
+		var stockErr *repositories.InsufficientStockError
+		switch {
+		case errors.Is(err, repositories.ErrWriteOffReviewed):
+			return nil, ErrWriteOffReviewed
+		case errors.As(err, &stockErr):
+			// Остаток мог уменьшиться после составления акта
+			product, findErr := s.ProductRepo.FindByID(stockErr.ProductID)
+			if findErr != nil {
+				return nil, ErrInsufficientStock
+			}
+			return nil, insufficientStock(product)
+		}
+		return nil, err
+	}
+
+	approved, err := s.Repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	s.Audit.Record(actor, models.AuditUpdate, AuditEntityWriteOff, id, before, approved)
+	return approved, nil
+}
+
+// RejectWriteOff отклоняет акт, остатки не меняются.
+func (s *WriteOffService) RejectWriteOff(actor Actor, id uint, reason string) (*models.WriteOff, error) {
+	writeOff, err := s.GetWriteOff(actor, id)
+	if err != nil {
+		return nil, err
+	}
+	if writeOff.Status != models.WriteOffPending {
+		return nil, ErrWriteOffReviewed
+	}
+
+	before := *writeOff
+	now := time.Now()
+	writeOff.Status = models.WriteOffRejected
+	writeOff.ReviewedBy, writeOff.ReviewedAt = &actor.UserID, &now
+	writeOff.RejectReason = strings.TrimSpace(reason)
+
+	if err := s.Repo.Reject(writeOff); err != nil {
+		if errors.Is(err, repositories.ErrWriteOffReviewed) {
+			return nil, ErrWriteOffReviewed
+		}
+		return nil, err
+	}
+
+	rejected, err := s.Repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	s.Audit.Record(actor, models.AuditUpdate, AuditEntityWriteOff, id, before, rejected)
+	return rejected, nil
+}
+
+// insufficientStock дополняет ErrInsufficientStock названием товара и его
+// остатком.
+func insufficientStock(product *models.Product) error {
+	return errs.NewConflict(ErrInsufficientStock.Code, fmt.Sprintf("%s: %q, в остатке %d", ErrInsufficientStock.Message, product.Name, product.CurrentQty))
+}
+
+// ShrinkageTotals — списания в единицах товара и по себестоимости.
+type ShrinkageTotals struct {
+	WriteOffs int
+	Quantity  int64
+	Cost      float64
+}
+
+func (t *ShrinkageTotals) add(row *repositories.ShrinkageRow) {
+	t.WriteOffs += row.WriteOffs
+	t.Quantity += row.Quantity
+	t.Cost += row.Cost
+}
+
+// ReasonShrinkage — списания по одной причине.
+type ReasonShrinkage struct {
+	Reason string
+	ShrinkageTotals
+}
+
+// DepartmentShrinkage — списания отдела с разбивкой по причинам.
+type DepartmentShrinkage struct {
+	Department models.Department
+	Reasons    []ReasonShrinkage
+	ShrinkageTotals
+}
+
+// ShrinkageReport — утвержденные списания за период [From, To). Отделы
+// идут по убыванию себестоимости списаний, причины — в порядке
+// models.WriteOffReasons.
+type ShrinkageReport struct {
+	From, To    time.Time
+	Departments []DepartmentShrinkage
+	Reasons     []ReasonShrinkage
+	Total       ShrinkageTotals
+}
+
+// GetShrinkage собирает отчет о списаниях отделов, доступных
+// пользователю, утвержденных за период [from, to).
+func (s *WriteOffService) GetShrinkage(actor Actor, from, to time.Time) (*ShrinkageReport, error) {
+	ids, all, err := s.Scope.Departments(actor)
+	if err != nil {
+		return nil, err
+	}
+	if all {
+		ids = nil
+	}
+
+	rows, err := s.Repo.Shrinkage(from, to, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ShrinkageReport{From: from, To: to}
+	byReason := make(map[string]*ShrinkageTotals)
+	byDepartment := make(map[uint]*DepartmentShrinkage)
+	var order []uint
+	for i := range rows {
+		row := &rows[i]
+		department, ok := byDepartment[row.DepartmentID]
+		if !ok {
+			department = &DepartmentShrinkage{Department: models.Department{ID: row.DepartmentID, Name: row.DepartmentName}}
+			byDepartment[row.DepartmentID] = department
+			order = append(order, row.DepartmentID)
+		}
+		// Акт относится к одному отделу и одной причине, поэтому число актов
+		// можно складывать
+		department.add(row)
+		department.Reasons = append(department.Reasons, ReasonShrinkage{
+			Reason:          row.Reason,
+			ShrinkageTotals: ShrinkageTotals{WriteOffs: row.WriteOffs, Quantity: row.Quantity, Cost: roundMoney(row.Cost)},
+		})
+
+		if byReason[row.Reason] == nil {
+			byReason[row.Reason] = &ShrinkageTotals{}
+		}
+		byReason[row.Reason].add(row)
+		report.Total.add(row)
+	}
+
+	for _, id := range order {
+		department := byDepartment[id]
+		department.Cost = roundMoney(department.Cost)
+		sort.SliceStable(department.Reasons, func(i, j int) bool {
+			return reasonIndex(department.Reasons[i].Reason) < reasonIndex(department.Reasons[j].Reason)
+		})
+		report.Departments = append(report.Departments, *department)
+	}
+	sort.SliceStable(report.Departments, func(i, j int) bool {
+		return report.Departments[i].Cost > report.Departments[j].Cost
+	})
+
+	for _, reason := range models.WriteOffReasons {
+		totals := ShrinkageTotals{}
+		if byReason[reason] != nil {
+			totals = *byReason[reason]
+		}
+		totals.Cost = roundMoney(totals.Cost)
+		report.Reasons = append(report.Reasons, ReasonShrinkage{Reason: reason, ShrinkageTotals: totals})
+	}
+	report.Total.Cost = roundMoney(report.Total.Cost)
+	return report, nil
+}
+
+func reasonIndex(reason string) int {
+	for i, known := range models.WriteOffReasons {
+		if known == reason {
+			return i
+		}
+	}
+	return len(models.WriteOffReasons)
+}
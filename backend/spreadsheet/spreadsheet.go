@@ -0,0 +1,99 @@
+// Package spreadsheet читает и пишет табличные файлы CSV и XLSX, которые
+// используются для массового импорта и выгрузки данных.
+package spreadsheet
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+type Format string
+
+const (
+	CSV  Format = "csv"
+	XLSX Format = "xlsx"
+)
+
+var ErrUnsupportedFormat = errors.New("поддерживаются только файлы CSV и XLSX")
+
+// ParseFormat возвращает формат по явно заданному имени ("csv", "xlsx")
+// или, если оно пустое, по расширению файла.
+func ParseFormat(name, filename string) (Format, error) {
+	if name == "" {
+		name = strings.TrimPrefix(filepath.Ext(filename), ".")
+	}
+
+	switch Format(strings.ToLower(name)) {
+	case CSV:
+		return CSV, nil
+	case XLSX:
+		return XLSX, nil
+	default:
+		return "", ErrUnsupportedFormat
+	}
+}
+
+// ReadRows читает все строки таблицы: весь файл для CSV и первый лист для
+// XLSX. Значения ячеек XLSX возвращаются без форматирования, поэтому даты
+// приходят серийными номерами Excel (см. excelize.ExcelDateToTime).
+func ReadRows(r io.Reader, format Format) ([][]string, error) {
+	switch format {
+	case CSV:
+		return readCSV(r)
+	case XLSX:
+		return readXLSX(r)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// readCSV понимает разделители «,» и «;»: русский Excel сохраняет CSV через
+// точку с запятой. Разделитель определяется по первой строке.
+func readCSV(r io.Reader) ([][]string, error) {
+	buffered := bufio.NewReader(r)
+	if prefix, err := buffered.Peek(len(utf8BOM)); err == nil && bytes.Equal(prefix, utf8BOM) {
+		buffered.Discard(len(utf8BOM))
+	}
+
+	firstLine, err := buffered.Peek(buffered.Size())
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, bufio.ErrBufferFull) {
+		return nil, err
+	}
+	if i := bytes.IndexByte(firstLine, '\n'); i >= 0 {
+		firstLine = firstLine[:i]
+	}
+
+	reader := csv.NewReader(buffered)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+	if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		reader.Comma = ';'
+	}
+
+	return reader.ReadAll()
+}
+
+func readXLSX(r io.Reader) ([][]string, error) {
+	file, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	sheets := file.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, nil
+	}
+
+	return file.GetRows(sheets[0], excelize.Options{RawCellValue: true})
+}
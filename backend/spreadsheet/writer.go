@@ -0,0 +1,223 @@
+package spreadsheet
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Writer построчно записывает таблицу. Close дописывает файл и должен
+// вызываться после последней строки; Abort освобождает ресурсы, если
+// запись прервана, и не дописывает то, что еще не отдано в w.
+type Writer interface {
+	WriteRow(values ...interface{}) error
+	Close() error
+	Abort()
+}
+
+// NewWriter создает Writer и сразу записывает строку заголовка. Значения
+// строк могут быть строками, целыми и дробными числами и time.Time; время
+// с нулевым временем суток записывается как дата.
+func NewWriter(w io.Writer, format Format, header []string) (Writer, error) {
+	switch format {
+	case CSV:
+		return newCSVWriter(w, header)
+	case XLSX:
+		return newXLSXWriter(w, header)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+// ContentType возвращает MIME-тип файла формата.
+func ContentType(format Format) string {
+	if format == XLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// csvWriter пишет CSV так, как его ожидает русский Excel: с BOM,
+// разделителем «;», десятичной запятой и датами ДД.ММ.ГГГГ. Такой файл
+// читается обратно функцией ReadRows.
+type csvWriter struct {
+	csv *csv.Writer
+}
+
+func newCSVWriter(w io.Writer, header []string) (*csvWriter, error) {
+	if _, err := w.Write(utf8BOM); err != nil {
+		return nil, err
+	}
+
+	writer := &csvWriter{csv: csv.NewWriter(w)}
+	writer.csv.Comma = ';'
+	if err := writer.csv.Write(header); err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
+
+func (w *csvWriter) WriteRow(values ...interface{}) error {
+	record := make([]string, len(values))
+	for i, value := range values {
+		record[i] = formatCSVValue(value)
+	}
+	return w.csv.Write(record)
+}
+
+func (w *csvWriter) Close() error {
+	w.csv.Flush()
+	return w.csv.Error()
+}
+
+func (w *csvWriter) Abort() {}
+
+func formatCSVValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case uint:
+		return strconv.FormatUint(uint64(v), 10)
+	case float64:
+		return strings.Replace(strconv.FormatFloat(v, 'f', 2, 64), ".", ",", 1)
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		if isDate(v) {
+			return v.Format("02.01.2006")
+		}
+		return v.Format("02.01.2006 15:04:05")
+	default:
+		return ""
+	}
+}
+
+// xlsxWriter пишет первый лист книги потоково: excelize держит в памяти
+// только небольшой буфер и переносит строки во временный файл, а в w книга
+// записывается целиком при Close.
+type xlsxWriter struct {
+	w      io.Writer
+	file   *excelize.File
+	stream *excelize.StreamWriter
+	row    int
+
+	dateStyle     int
+	dateTimeStyle int
+	moneyStyle    int
+}
+
+const xlsxSheet = "Sheet1"
+
+func newXLSXWriter(w io.Writer, header []string) (writer *xlsxWriter, err error) {
+	file := excelize.NewFile()
+	defer func() {
+		if err != nil {
+			file.Close()
+		}
+	}()
+
+	writer = &xlsxWriter{w: w, file: file}
+	styles := []struct {
+		target *int
+		style  excelize.Style
+	}{
+		// Встроенные форматы Excel: 14 — дата, 22 — дата и время, 4 — #,##0.00
+		{&writer.dateStyle, excelize.Style{NumFmt: 14}},
+		{&writer.dateTimeStyle, excelize.Style{NumFmt: 22}},
+		{&writer.moneyStyle, excelize.Style{NumFmt: 4}},
+	}
+	for _, s := range styles {
+		if *s.target, err = file.NewStyle(&s.style); err != nil {
+			return nil, err
+		}
+	}
+	headerStyle, err := file.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return nil, err
+	}
+
+	if writer.stream, err = file.NewStreamWriter(xlsxSheet); err != nil {
+		return nil, err
+	}
+
+	// Ширина колонок задается до первой строки, поэтому считается по заголовку
+	for i, title := range header {
+		width := float64(utf8.RuneCountInString(title)) + 4
+		if width < 12 {
+			width = 12
+		}
+		if err := writer.stream.SetColWidth(i+1, i+1, width); err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.stream.SetPanes(&excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"}); err != nil {
+		return nil, err
+	}
+
+	cells := make([]interface{}, len(header))
+	for i, title := range header {
+		cells[i] = excelize.Cell{StyleID: headerStyle, Value: title}
+	}
+	if err := writer.writeCells(cells); err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
+
+func (w *xlsxWriter) WriteRow(values ...interface{}) error {
+	cells := make([]interface{}, len(values))
+	for i, value := range values {
+		switch v := value.(type) {
+		case float64:
+			cells[i] = excelize.Cell{StyleID: w.moneyStyle, Value: v}
+		case time.Time:
+			switch {
+			case v.IsZero():
+				cells[i] = nil
+			case isDate(v):
+				cells[i] = excelize.Cell{StyleID: w.dateStyle, Value: v}
+			default:
+				cells[i] = excelize.Cell{StyleID: w.dateTimeStyle, Value: v}
+			}
+		default:
+			cells[i] = v
+		}
+	}
+	return w.writeCells(cells)
+}
+
+func (w *xlsxWriter) writeCells(cells []interface{}) error {
+	w.row++
+	cell, err := excelize.CoordinatesToCellName(1, w.row)
+	if err != nil {
+		return err
+	}
+	return w.stream.SetRow(cell, cells)
+}
+
+func (w *xlsxWriter) Close() error {
+	err := w.stream.Flush()
+	if err == nil {
+		_, err = w.file.WriteTo(w.w)
+	}
+	return errors.Join(err, w.file.Close())
+}
+
+func (w *xlsxWriter) Abort() {
+	w.file.Close()
+}
+
+func isDate(t time.Time) bool {
+	return t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 && t.Nanosecond() == 0
+}
@@ -0,0 +1,136 @@
+// Package terminal содержит драйверы банковских платежных терминалов,
+// реализующие services.PaymentTerminal.
+package terminal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sync"
+
+	"grocery-store-api/services"
+)
+
+var ErrUnknownTransaction = errors.New("операция не найдена")
+
+// Simulator — имитация терминала для разработки и тестов. Операции
+// хранятся в памяти. Оплата одобряется, если сумма не превышает
+// DeclineAbove (0 — без ограничения), иначе отклоняется с причиной
+// «недостаточно средств». Возврат одобряется, пока сумма возвратов не
+// превышает сумму операции. Повторный запрос с тем же Reference возвращает
+// результат первой операции.
+type Simulator struct {
+	DeclineAbove float64
+
+	mu           sync.Mutex
+	seq          int
+	byReference  map[string]services.TerminalResult
+	transactions map[string]*transaction
+}
+
+type transaction struct {
+	amount    float64
+	refunded  float64
+	cancelled bool
+	// refundOf — операция, по которой сделан возврат
+	refundOf string
+}
+
+var _ services.PaymentTerminal = (*Simulator)(nil)
+
+func NewSimulator(declineAbove float64) *Simulator {
+	return &Simulator{
+		DeclineAbove: declineAbove,
+		byReference:  make(map[string]services.TerminalResult),
+		transactions: make(map[string]*transaction),
+	}
+}
+
+func (s *Simulator) Charge(ctx context.Context, charge services.TerminalCharge) (*services.TerminalResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if result, ok := s.byReference[charge.Reference]; ok {
+		return &result, nil
+	}
+
+	var result services.TerminalResult
+	if s.DeclineAbove > 0 && charge.Amount > s.DeclineAbove {
+		result = services.TerminalResult{DeclineReason: "недостаточно средств"}
+	} else {
+		result = s.approve(charge.Reference)
+		s.transactions[result.TransactionID] = &transaction{amount: charge.Amount}
+	}
+
+	s.byReference[charge.Reference] = result
+	return &result, nil
+}
+
+func (s *Simulator) Refund(ctx context.Context, refund services.TerminalRefund) (*services.TerminalResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if result, ok := s.byReference[refund.Reference]; ok {
+		return &result, nil
+	}
+
+	original, ok := s.transactions[refund.TransactionID]
+	if !ok || original.refundOf != "" {
+		return nil, ErrUnknownTransaction
+	}
+
+	var result services.TerminalResult
+	switch {
+	case original.cancelled:
+		result = services.TerminalResult{DeclineReason: "операция отменена"}
+	case original.refunded+refund.Amount > original.amount+0.005:
+		result = services.TerminalResult{DeclineReason: "сумма возврата больше суммы операции"}
+	default:
+		result = s.approve(refund.Reference)
+		original.refunded += refund.Amount
+		s.transactions[result.TransactionID] = &transaction{amount: refund.Amount, refundOf: refund.TransactionID}
+	}
+
+	s.byReference[refund.Reference] = result
+	return &result, nil
+}
+
+func (s *Simulator) Cancel(ctx context.Context, transactionID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	operation, ok := s.transactions[transactionID]
+	if !ok {
+		return ErrUnknownTransaction
+	}
+	if !operation.cancelled && operation.refundOf != "" {
+		s.transactions[operation.refundOf].refunded -= operation.amount
+	}
+	operation.cancelled = true
+	return nil
+}
+
+// approve выдает реквизиты одобренной операции.
+func (s *Simulator) approve(reference string) services.TerminalResult {
+	s.seq++
+	hash := fnv.New32a()
+	fmt.Fprint(hash, reference)
+	sum := hash.Sum32()
+
+	return services.TerminalResult{
+		Approved:      true,
+		TransactionID: fmt.Sprintf("SIM%08d", s.seq),
+		AuthCode:      fmt.Sprintf("%06d", sum%1000000),
+		CardMask:      fmt.Sprintf("**** %04d", sum%10000),
+	}
+}